@@ -66,22 +66,43 @@ func NewSound(relAudioPath string, volume float64) (Sound, error) {
 	if relAudioPath == "" {
 		return Sound{}, errors.New("no relative audio path given")
 	}
-	srcPath := config.ResolveAudioPath(relAudioPath)
+	return loadSound(config.ResolveAudioPath(relAudioPath), volume, 1)
+}
+
+// WithPitch loads a copy of the sound that plays at a different pitch (2 is an octave up, 0.5 an octave down).
+// Like old tape players, the pitch is changed by speeding up (or slowing down) the sound, so higher pitches are also shorter.
+func (s Sound) WithPitch(pitch float64) (Sound, error) {
+	if pitch <= 0 {
+		return Sound{}, errors.New("pitch must be positive")
+	}
+	return loadSound(s.srcPath, s.baseVolume, pitch)
+}
 
+func loadSound(srcPath string, volume float64, pitch float64) (Sound, error) {
 	data, err := os.ReadFile(srcPath)
 	if err != nil {
 		return Sound{}, err
 	}
 
 	var stream io.ReadSeeker
+	var length int64
+	var srcSampleRate int
 
 	ext := filepath.Ext(srcPath)
 
 	switch ext {
 	case ".mp3":
-		stream, err = mp3.DecodeF32(bytes.NewReader(data))
+		var s *mp3.Stream
+		s, err = mp3.DecodeF32(bytes.NewReader(data))
+		if s != nil {
+			stream, length, srcSampleRate = s, s.Length(), s.SampleRate()
+		}
 	case ".wav":
-		stream, err = wav.DecodeF32(bytes.NewReader(data))
+		var s *wav.Stream
+		s, err = wav.DecodeF32(bytes.NewReader(data))
+		if s != nil {
+			stream, length, srcSampleRate = s, s.Length(), s.SampleRate()
+		}
 	default:
 		logz.Panicln("NewSound", "unsupported audio format:", ext)
 	}
@@ -90,7 +111,13 @@ func NewSound(relAudioPath string, volume float64) (Sound, error) {
 		return Sound{}, err
 	}
 
-	player, err := audioContext.NewPlayerF32(stream)
+	var src io.Reader = stream
+	if pitch != 1 {
+		// pretending the sound was recorded at a higher sample rate makes it play faster, which raises the pitch
+		src = audio.ResampleReaderF32(stream, length, int(float64(srcSampleRate)*pitch), sampleRate)
+	}
+
+	player, err := audioContext.NewPlayerF32(src)
 	if err != nil {
 		return Sound{}, err
 	}
@@ -8,12 +8,20 @@ import (
 type AudioManager struct {
 	SFXLibrary map[defs.SoundID]*Sound
 	BGMLibrary map[defs.SoundID]*Sound
+
+	pitchedSFX map[pitchedSFXKey]*Sound // pitched copies of sound effects, made the first time they are played
+}
+
+type pitchedSFXKey struct {
+	id    defs.SoundID
+	pitch float64
 }
 
 func NewAudioManager() *AudioManager {
 	return &AudioManager{
 		SFXLibrary: make(map[defs.SoundID]*Sound),
 		BGMLibrary: make(map[defs.SoundID]*Sound),
+		pitchedSFX: make(map[pitchedSFXKey]*Sound),
 	}
 }
 
@@ -53,3 +61,26 @@ func (am AudioManager) PlaySFX(id defs.SoundID, vol float64) {
 
 	sound.PlayVolumeAdjusted(vol)
 }
+
+// PlaySFXPitched plays a sound effect at a different pitch (2 is an octave up, 0.5 an octave down). A pitch of 0 or 1 plays it normally.
+func (am *AudioManager) PlaySFXPitched(id defs.SoundID, vol float64, pitch float64) {
+	if pitch == 0 || pitch == 1 {
+		am.PlaySFX(id, vol)
+		return
+	}
+	key := pitchedSFXKey{id, pitch}
+	sound, exists := am.pitchedSFX[key]
+	if !exists {
+		src, found := am.SFXLibrary[id]
+		if !found {
+			logz.Panicln("AudioManager", "tried to play sound that doesn't exist:", id)
+		}
+		pitched, err := src.WithPitch(pitch)
+		if err != nil {
+			logz.Panicln("AudioManager", "failed to load pitched sound:", id, err)
+		}
+		sound = &pitched
+		am.pitchedSFX[key] = sound
+	}
+	sound.PlayVolumeAdjusted(vol)
+}
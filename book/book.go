@@ -11,6 +11,7 @@ import (
 	"github.com/webbben/2d-game-engine/display"
 	characterstate "github.com/webbben/2d-game-engine/entity/characterState"
 	"github.com/webbben/2d-game-engine/imgutil/rendering"
+	"github.com/webbben/2d-game-engine/locale"
 	"github.com/webbben/2d-game-engine/logz"
 	"github.com/webbben/2d-game-engine/pubsub"
 	"github.com/webbben/2d-game-engine/ui/box"
@@ -112,7 +113,7 @@ func NewBookSession(
 	}
 
 	if sesh.bookDef.Text != "" {
-		sesh.bookDef.Text = dialogv2.InsertDialogVariables(sesh.bookDef.Text, playerInfo, dataman)
+		sesh.bookDef.Text = dialogv2.InsertDialogVariables(sesh.bookDef.Text, dialogv2.DialogTextContext{PlayerInfo: playerInfo, Dataman: dataman})
 	}
 
 	// decide which font to use
@@ -163,7 +164,7 @@ func NewBookSession(
 		})
 	}
 
-	sesh.closeBtn = button.NewButton("Close", config.DefaultFont, 0, 0, audioman)
+	sesh.closeBtn = button.NewButton(locale.Text("ui.book.close", "Close"), config.DefaultFont, 0, 0, audioman)
 
 	// if the book def has knowledge topics, apply it to the player's character state
 	if len(sesh.bookDef.KnowledgeTopics) > 0 {
@@ -388,3 +388,32 @@ func (c *Clock) Update() (hourChanged bool) {
 
 	return
 }
+
+// GetDayOfWeek gets the current day of the week.
+func (c Clock) GetDayOfWeek() DayOfWeek {
+	return DaysOfWeek[c.dayOfWeek]
+}
+
+// TimeOfDay is a rough description of the time of day, e.g. for use in dialog ("Good morning").
+type TimeOfDay string
+
+const (
+	Morning   TimeOfDay = "morning"
+	Afternoon TimeOfDay = "afternoon"
+	Evening   TimeOfDay = "evening"
+	Night     TimeOfDay = "night"
+)
+
+// TimeOfDay gets the rough time of day that this game time falls in.
+func (gt GameTime) TimeOfDay() TimeOfDay {
+	switch {
+	case gt.Hour >= 5 && gt.Hour < 12:
+		return Morning
+	case gt.Hour >= 12 && gt.Hour < 17:
+		return Afternoon
+	case gt.Hour >= 17 && gt.Hour < 21:
+		return Evening
+	default:
+		return Night
+	}
+}
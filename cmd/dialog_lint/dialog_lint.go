@@ -0,0 +1,22 @@
+package main
+
+import (
+	"os"
+
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/dialogv2/dialoglint"
+
+	// registers the world effects that dialog scripts can use
+	_ "github.com/webbben/2d-game-engine/world"
+)
+
+// Checks dialog scripts for problems, or walks through a dialog in the terminal.
+//
+// Games that define dialog in Go can run the same commands on the profiles and topics they've loaded into their own DataManager,
+// by calling dialoglint.Main from a command of their own (dialog scripts can still be added on top with -dir).
+//
+//	dialog_lint lint -dir data/dialog
+//	dialog_lint walk -dir data/dialog -profile blacksmith -gold 50 -know lost_sword
+func main() {
+	os.Exit(dialoglint.Main(datamanager.NewDataManager(), os.Args[1:]))
+}
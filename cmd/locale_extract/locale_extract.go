@@ -0,0 +1,23 @@
+package main
+
+import (
+	"os"
+
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/locale/localeextract"
+
+	// registers the world effects that dialog scripts can use
+	_ "github.com/webbben/2d-game-engine/world"
+)
+
+// Lists the strings that a locale hasn't translated yet, as a locale table (JSON) that can be filled in and dropped into the locales directory.
+// Each string is given with its text in the default locale, so translators know what to translate.
+//
+// Strings are taken from the default locale's tables, and from dialog scripts if a dialog directory is given.
+// Games that define items, books, quests, etc in Go can include those too, by calling localeextract.Main from a command of their own
+// with the defs they've loaded.
+//
+//	locale_extract -locales data/locales -locale fr -dialog data/dialog -out fr.todo.json
+func main() {
+	os.Exit(localeextract.Main(datamanager.NewDataManager(), nil, os.Args[1:]))
+}
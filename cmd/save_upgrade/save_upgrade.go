@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/webbben/2d-game-engine/data/savegame"
+)
+
+// Upgrades all the save files in a directory (and its subdirectories) to the current save file version, in place.
+// Saves keep whichever format they're in (plain JSON or compressed).
+// Before a save is overwritten, the original is copied into a "backups" directory next to it.
+//
+// Saves are also migrated in memory whenever they're loaded, so this isn't required; it's for upgrading saves
+// in bulk, or checking which saves need upgrading (with -dry).
+//
+//	save_upgrade -dir ~/2d/saves
+func main() {
+	dir := flag.String("dir", "", "directory of save files to upgrade (e.g. the game data saves directory)")
+	dry := flag.Bool("dry", false, "only report which saves would be upgraded, without changing anything")
+	noBackup := flag.Bool("no-backup", false, "don't keep backups of the original save files")
+	flag.Parse()
+
+	if *dir == "" {
+		fmt.Println("Error: dir argument is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	fmt.Println("current save version:", savegame.SaveFileVersion)
+
+	upgraded, current, failed := 0, 0, 0
+	err := filepath.WalkDir(*dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "backups" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// only files named like saves; other JSON files (settings, locale tables, etc) are left alone if dir is the game data root
+		if _, _, ok := savegame.ParseSaveFileName(d.Name()); !ok {
+			return nil
+		}
+
+		if *dry {
+			version, err := getVersion(path)
+			if err != nil {
+				fmt.Printf("FAIL %s: %s\n", path, err)
+				failed++
+				return nil
+			}
+			if version == savegame.SaveFileVersion {
+				current++
+				return nil
+			}
+			fmt.Printf("would upgrade %s (v%v)\n", path, version)
+			for _, note := range savegame.GetMigrationNotes(version) {
+				fmt.Println("  ", note)
+			}
+			upgraded++
+			return nil
+		}
+
+		ok, fromVersion, err := savegame.UpgradeSaveFile(path, !*noBackup)
+		if err != nil {
+			fmt.Printf("FAIL %s: %s\n", path, err)
+			failed++
+			return nil
+		}
+		if !ok {
+			current++
+			return nil
+		}
+		fmt.Printf("upgraded %s (v%v -> v%v)\n", path, fromVersion, savegame.SaveFileVersion)
+		upgraded++
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error: failed to walk save directory:", err)
+		os.Exit(1)
+	}
+
+	verb := "upgraded"
+	if *dry {
+		verb = "to upgrade"
+	}
+	fmt.Printf("%v %s, %v already current, %v failed\n", upgraded, verb, current, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func getVersion(path string) (int, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	// decoding also verifies the checksum of compressed saves, so corrupted saves are reported too
+	saveJSON, _, err := savegame.DecodeSaveFile(raw)
+	if err != nil {
+		return 0, err
+	}
+	// migrating in memory also catches saves that are too new, or missing a migration
+	_, version, err := savegame.MigrateSaveData(saveJSON)
+	return version, err
+}
@@ -4,6 +4,7 @@ package config
 import (
 	"time"
 
+	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/webbben/2d-game-engine/data/defs"
 	"golang.org/x/image/font"
 )
@@ -43,6 +44,7 @@ var (
 	ShowNPCPaths        = false // highlight the paths that NPCs are following
 	TrackMemoryUsage    = false // show a report in the console of memory usage every few seconds
 	ShowGameDebugInfo   = false // show a report of various debugging info (like F12 in minecraft)
+	PlainJSONSaves      = false // write saves as plain JSON instead of the compressed format, so they're easy to read and edit
 
 	// misc
 
@@ -85,6 +87,39 @@ var (
 	// TODO: move other screens here too? I guess trade is just a screen shown during dialog, but maybe player menu can go here?
 
 	DefaultBookSessionParams BookSessionParams
+
+	// dialog
+
+	DialogTranscriptMaxEntries int           = 200             // max number of lines kept in each dialog profile's transcript. if 0, transcripts aren't recorded.
+	DialogTranscriptKey        ebiten.Key    = ebiten.KeyL     // key to open/close the transcript of past conversations, while in a dialog
+	DialogShowPortraits        bool          = true            // if set, the speaker's portrait shows above the dialog box
+	DialogEmoteIconDuration    time.Duration = time.Second * 2 // how long emote icons (like "!") float above a speaker's head
+
+	// ambient NPC barks
+
+	BarkCheckInterval time.Duration = time.Second * 20 // roughly how often we try to start a bark between NPCs in the current map. if 0, barks are disabled.
+	BarkMaxDistance   float64       = 4                // max distance (in tiles) between the NPCs taking part in a bark
+
+	// saves
+
+	// max number of saves kept for each slot type, per character; when a new save is made, the oldest ones past this are deleted. 0 means no limit.
+	SaveRetention = map[defs.SaveSlotType]int{
+		defs.SaveSlotManual: 0,
+		defs.SaveSlotAuto:   3,
+		defs.SaveSlotQuick:  1,
+	}
+	// events that trigger an autosave. if nil, the engine defaults are used (see game.DefaultAutosaveEvents). set to an empty slice to turn autosaves off.
+	AutosaveEvents      []defs.EventType
+	AutosaveMinInterval time.Duration = time.Minute // autosave triggers that come sooner than this after the last autosave are ignored
+	QuickSaveEnabled    bool          = true        // if set, pressing QuickSaveKey while in the game world makes a quicksave
+	QuickSaveKey        ebiten.Key    = ebiten.KeyF5
+	QuickLoadEnabled    bool          = true // if set, pressing QuickLoadKey while in the game world loads the character's latest quicksave
+	QuickLoadKey        ebiten.Key    = ebiten.KeyF9
+	SaveThumbnailWidth  int           = 240 // width (in pixels) of the screenshot saved with each save file, for previews. if 0, no screenshots are saved.
+
+	// localization
+
+	DefaultLocale string = "en" // locale that text falls back to when the active locale doesn't have it. source text in defs should be in this locale.
 )
 
 const (
@@ -21,6 +21,8 @@ Here is the file structure for game data:
 │   └── fonts
 ├── generated      // all files that are generated during runtime
 │   └── tiles      // individual tile images generated from tilesets
+├── locales        // localized string tables, e.g. fr.json (see the locale package)
+├── settings.json  // player settings that are kept between sessions
 └── tiled
     ├── maps       // maps where the player or other entities can load into
     └── tilesets   // tilesets used by maps, entities, UI components, etc
@@ -82,6 +84,11 @@ func getPlayerSaveDir(uniquePlayerID defs.UniquePlayerID) string {
 	return filepath.Join(saveFilesPath(), string(uniquePlayerID))
 }
 
+// ResolvePlayerSaveDir gets the directory where a character's save files are kept.
+func ResolvePlayerSaveDir(uniquePlayerID defs.UniquePlayerID) string {
+	return getPlayerSaveDir(uniquePlayerID)
+}
+
 func GetAllSaveDirs() []string {
 	return files.GetListOfDirs(saveFilesPath(), true)
 }
@@ -162,3 +169,10 @@ func gameFontsPath() string {
 func ResolveFontPath(fontRelPath string) string {
 	return filepath.Join(gameFontsPath(), fontRelPath)
 }
+
+// LOCALES
+
+// GameLocalesPath gets the directory where locale string tables are kept.
+func GameLocalesPath() string {
+	return filepath.Join(GameDataRootPath(), "locales")
+}
@@ -25,6 +25,7 @@ type LineWriterParams struct {
 	WriteImmediately     bool // if true, text will write immediately on first update
 	TextBlipSfx          defs.SoundID
 	TextBlipTickInterval int
+	TextBlipPitch        float64 // pitch of the text blip sound. 1 (or 0) plays it normally.
 
 	FgColor   color.Color // color used for text foreground. defaults to black.
 	BgColor   color.Color // color used for shadow behind text, or for de-emphasized/aside text (text inside underscores). defaults to light gray.
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/webbben/2d-game-engine/utils/files"
+)
+
+// Settings are the player's settings, which are kept between sessions (not per save).
+type Settings struct {
+	Locale string // the active locale, e.g. "en" or "fr". if empty, DefaultLocale is used.
+}
+
+func settingsFilePath() string {
+	return filepath.Join(GameDataRootPath(), "settings.json")
+}
+
+// LoadSettings loads the player's settings. If there's no settings file yet, the default settings are returned.
+func LoadSettings() (Settings, error) {
+	settings := Settings{}
+	data, err := os.ReadFile(settingsFilePath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("failed to read settings file: %w", err)
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveSettings writes the player's settings to the settings file.
+func SaveSettings(settings Settings) error {
+	return files.WriteToJSON(settings, settingsFilePath())
+}
@@ -2,15 +2,23 @@
 package datamanager
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"path/filepath"
 
 	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/dialogscript"
 	"github.com/webbben/2d-game-engine/data/id"
 	"github.com/webbben/2d-game-engine/data/state"
+	"github.com/webbben/2d-game-engine/locale"
 	"github.com/webbben/2d-game-engine/logz"
 	"github.com/webbben/2d-game-engine/utils"
 )
 
+// DialogScriptExt is the file extension of dialog scripts (see the dialogscript package)
+const DialogScriptExt = ".dlg"
+
 type DataManager struct {
 	CombatSystemCalc defs.CombatSystemCalc
 	LevelSysParams   *defs.LevelSystemParameters
@@ -31,6 +39,7 @@ type DataManager struct {
 	DialogProfiles      map[defs.DialogProfileID]*defs.DialogProfileDef
 	DialogProfileStates map[defs.DialogProfileID]*state.DialogProfileState
 	DialogTopics        map[defs.TopicID]*defs.DialogTopic
+	BarkDefs            map[defs.BarkID]defs.BarkDef
 
 	NPCSchedules map[defs.ScheduleID]defs.ScheduleDef
 
@@ -68,6 +77,7 @@ func NewDataManager() *DataManager {
 		DialogProfiles:      make(map[defs.DialogProfileID]*defs.DialogProfileDef),
 		DialogTopics:        make(map[defs.TopicID]*defs.DialogTopic),
 		DialogProfileStates: make(map[defs.DialogProfileID]*state.DialogProfileState),
+		BarkDefs:            make(map[defs.BarkID]defs.BarkDef),
 		CharacterDefs:       make(map[defs.CharacterDefID]defs.CharacterDef),
 		CharacterStates:     make(map[id.CharacterStateID]*state.CharacterState),
 		CharacterGenerators: make(map[string]defs.CharacterGenerator),
@@ -78,6 +88,21 @@ func NewDataManager() *DataManager {
 	return &dataman
 }
 
+// ResetStates removes all loaded states, along with the player's defs (which come from the save file), so that a different save can be loaded.
+// All other defs stay loaded.
+func (dataman *DataManager) ResetStates() {
+	if playerDef, exists := dataman.CharacterDefs[defs.PlayerID]; exists {
+		if playerDef.IsCustomClassDef {
+			delete(dataman.ClassDefs, playerDef.ClassDefID)
+		}
+		delete(dataman.CharacterDefs, defs.PlayerID)
+	}
+	dataman.MapStates = make(map[defs.MapID]*state.MapState)
+	dataman.ShopkeeperStates = make(map[defs.ShopID]*state.ShopkeeperState)
+	dataman.DialogProfileStates = make(map[defs.DialogProfileID]*state.DialogProfileState)
+	dataman.CharacterStates = make(map[id.CharacterStateID]*state.CharacterState)
+}
+
 func (dataman *DataManager) LoadLevelSys(lvlSys *defs.LevelSystemParameters) {
 	dataman.LevelSysParams = lvlSys
 }
@@ -119,10 +144,17 @@ func (dataman *DataManager) LoadBookDef(def defs.BookDef) {
 	if _, exists := dataman.BookDefs[def.ID]; exists {
 		logz.Panicln("DataManager", "book def already exists:", def.ID)
 	}
+	// books are shown outside of dialogs, so they can only use dialog variables that don't need the world or a dialog
+	if _, isRef := locale.RefKey(def.Text); !isRef {
+		if err := defs.ValidateBookText(def.Text); err != nil {
+			logz.Panicln("DataManager", "book text is invalid:", def.ID, err)
+		}
+	}
 
 	dataman.BookDefs[def.ID] = def
 }
 
+// GetBookDef gets a book def, with its text in the active locale.
 func (dataman *DataManager) GetBookDef(id defs.BookID) defs.BookDef {
 	if id == "" {
 		logz.Panic("id was empty")
@@ -133,6 +165,8 @@ func (dataman *DataManager) GetBookDef(id defs.BookID) defs.BookDef {
 		logz.Panicln("DataManager", "book def doesn't exist:", id)
 	}
 
+	def.Title = locale.Text(bookKey(id, "title"), def.Title)
+	def.Text = locale.Text(bookKey(id, "text"), def.Text)
 	return def
 }
 
@@ -424,9 +458,63 @@ func (dataman *DataManager) LoadDialogProfile(profile *defs.DialogProfileDef) {
 	if _, exists := dataman.DialogProfiles[profile.ProfileID]; exists {
 		logz.Panicln("DataManager", "tried to load dialog profile, but a profile with the same ID already exists:", profile.ProfileID)
 	}
+	profile.Validate()
+	if profile.Portrait != nil {
+		profile.Portrait.Validate()
+	}
+	if profile.Voice != nil {
+		profile.Voice.Validate()
+	}
 	dataman.DialogProfiles[profile.ProfileID] = profile
 }
 
+// LoadDialogScripts compiles and loads every dialog script (.dlg file) in the given directory (and its subdirectories).
+// All scripts are compiled before anything is loaded, so the returned error has the problems from every file, with line numbers.
+func (dataman *DataManager) LoadDialogScripts(dir string) error {
+	paths := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == DialogScriptExt {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find dialog scripts: %w", err)
+	}
+
+	scripts := []dialogscript.Script{}
+	var allErrs dialogscript.ErrorList
+	for _, path := range paths {
+		script, err := dialogscript.CompileFile(path)
+		if err != nil {
+			var errList dialogscript.ErrorList
+			if errors.As(err, &errList) {
+				allErrs = append(allErrs, errList...)
+				continue
+			}
+			return err
+		}
+		scripts = append(scripts, script)
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	for _, script := range scripts {
+		for _, topic := range script.Topics {
+			dataman.LoadDialogTopic(topic)
+		}
+		for _, profile := range script.Profiles {
+			dataman.LoadDialogProfile(profile)
+		}
+	}
+	logz.Println("DataManager", "loaded dialog scripts:", len(scripts))
+	return nil
+}
+
 func (dataman DataManager) GetDialogProfile(id defs.DialogProfileID) *defs.DialogProfileDef {
 	profile, exists := dataman.DialogProfiles[id]
 	if !exists {
@@ -435,6 +523,22 @@ func (dataman DataManager) GetDialogProfile(id defs.DialogProfileID) *defs.Dialo
 	return profile
 }
 
+func (dataman *DataManager) LoadBarkDef(def defs.BarkDef) {
+	def.Validate()
+	if _, exists := dataman.BarkDefs[def.ID]; exists {
+		logz.Panicln("DataManager", "tried to load bark def, but id already exists:", def.ID)
+	}
+	dataman.BarkDefs[def.ID] = def
+}
+
+func (dataman DataManager) GetBarkDef(id defs.BarkID) defs.BarkDef {
+	def, exists := dataman.BarkDefs[id]
+	if !exists {
+		logz.Panicln("DataManager", "tried to get bark def, but id doesn't exist:", id)
+	}
+	return def
+}
+
 func (dataman *DataManager) LoadDialogProfileState(profileState *state.DialogProfileState) {
 	if profileState.ProfileID == "" {
 		logz.Panicln("DataManager", "tried to load dialog profile state, but profile ID was empty")
@@ -547,11 +651,14 @@ func (dataman *DataManager) LoadItemDefs(itemDefs []defs.ItemDef) {
 	}
 }
 
+// GetItemDef gets an item def, with its name and description in the active locale.
 func (dataman *DataManager) GetItemDef(defID defs.ItemID) defs.ItemDef {
 	itemDef, exists := dataman.ItemDefs[defID]
 	if !exists {
 		logz.Panicln("DataManager", "item def not found:", defID)
 	}
+	itemDef.Name = locale.Text(itemKey(defID, "name"), itemDef.Name)
+	itemDef.Description = locale.Text(itemKey(defID, "description"), itemDef.Description)
 	return itemDef
 }
 
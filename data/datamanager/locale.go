@@ -0,0 +1,98 @@
+package datamanager
+
+import (
+	"fmt"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/locale"
+)
+
+func itemKey(id defs.ItemID, field string) locale.Key {
+	return locale.Key(fmt.Sprintf("item.%s.%s", id, field))
+}
+
+func bookKey(id defs.BookID, field string) locale.Key {
+	return locale.Key(fmt.Sprintf("book.%s.%s", id, field))
+}
+
+// TopicPromptKey gets the localization key of a dialog topic's prompt.
+func TopicPromptKey(id defs.TopicID) locale.Key {
+	return locale.Key(fmt.Sprintf("topic.%s.prompt", id))
+}
+
+// LocalizationKeys gets the localization keys for all the loaded item, book and dialog defs, along with their source (untranslated) text.
+// Keys that dialog text refers to directly ("@some.key") are included too, but with no source text, since the text lives in the locale tables.
+func (dataman *DataManager) LocalizationKeys() map[locale.Key]string {
+	keys := make(map[locale.Key]string)
+	add := func(key locale.Key, source string) {
+		if source == "" {
+			return
+		}
+		if ref, isRef := locale.RefKey(source); isRef {
+			addRef(keys, ref)
+			return
+		}
+		keys[key] = source
+	}
+
+	for id, def := range dataman.ItemDefs {
+		add(itemKey(id, "name"), def.Name)
+		add(itemKey(id, "description"), def.Description)
+	}
+	for id, def := range dataman.BookDefs {
+		add(bookKey(id, "title"), def.Title)
+		add(bookKey(id, "text"), def.Text)
+	}
+
+	// responses and replies have no keys of their own, so only references count for them
+	var addResponse func(resp defs.DialogResponse)
+	addResponse = func(resp defs.DialogResponse) {
+		if ref, isRef := locale.RefKey(resp.Text); isRef {
+			addRef(keys, ref)
+		}
+		for _, reply := range resp.Replies {
+			if ref, isRef := locale.RefKey(reply.Text); isRef {
+				addRef(keys, ref)
+			}
+			if reply.SkillCheck != nil {
+				if reply.SkillCheck.Success != nil {
+					addResponse(*reply.SkillCheck.Success)
+				}
+				if reply.SkillCheck.Failure != nil {
+					addResponse(*reply.SkillCheck.Failure)
+				}
+			}
+			if reply.NextResponse != nil {
+				addResponse(*reply.NextResponse)
+			}
+			for _, next := range reply.NextResponseOptions {
+				addResponse(next)
+			}
+		}
+		if resp.NextResponse != nil {
+			addResponse(*resp.NextResponse)
+		}
+		for _, next := range resp.NextResponseOptions {
+			addResponse(next)
+		}
+	}
+	for id, topic := range dataman.DialogTopics {
+		add(TopicPromptKey(id), topic.Prompt)
+		for _, resp := range topic.Responses {
+			addResponse(resp)
+		}
+	}
+	for _, profile := range dataman.DialogProfiles {
+		for _, resp := range profile.Greeting {
+			addResponse(resp)
+		}
+	}
+
+	return keys
+}
+
+func addRef(keys map[locale.Key]string, ref locale.Key) {
+	if _, exists := keys[ref]; !exists {
+		keys[ref] = ""
+	}
+}
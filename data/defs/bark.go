@@ -0,0 +1,96 @@
+package defs
+
+import (
+	"time"
+
+	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/data/id"
+)
+
+type BarkID string
+
+const (
+	DefaultBarkCooldown     = time.Minute * 5
+	DefaultBarkRecentWithin = time.Minute * 2
+	DefaultBarkLineDuration = time.Second * 4
+)
+
+// BarkDef defines a short exchange between NPCs that the player can overhear, like two guards complaining about the weather.
+// Barks are shown in speech bubbles, and only involve NPCs that aren't busy with anything important.
+type BarkDef struct {
+	ID           BarkID
+	Participants []BarkParticipant // the NPCs taking part in the exchange. needs at least 2.
+	Lines        []BarkLine        // the lines of the exchange, said in order
+
+	// Conditions for when the bark can play. All are optional.
+
+	TimesOfDay   []clock.TimeOfDay // only plays during these times of day
+	MapTypes     []MapType         // only plays in these types of maps (e.g. taverns)
+	RecentEvents []EventType       // only plays if one of these events happened recently (e.g. people gossiping after a murder)
+	RecentWithin time.Duration     // how recent those events must have been. defaults to DefaultBarkRecentWithin.
+	Conditions   []BarkCondition   // any custom conditions. AND logic.
+
+	Cooldown time.Duration // how long (real time) before this bark can play again. defaults to DefaultBarkCooldown.
+}
+
+// BarkParticipant decides which NPCs can take a part in a bark.
+type BarkParticipant struct {
+	Roles        []RoleID              // OPT: the NPC must have at least one of these roles
+	CharacterIDs []id.CharacterStateID // OPT: the NPC must be one of these characters
+}
+
+type BarkLine struct {
+	Speaker  int // index of the participant who says this line
+	Text     string
+	Duration time.Duration // OPT: how long the speech bubble shows for. defaults to DefaultBarkLineDuration.
+}
+
+// BarkCondition is a custom condition for whether a bark can play.
+// participants are the NPCs that were chosen for the bark, in the same order as the bark's Participants.
+type BarkCondition interface {
+	IsMet(ctx SpeechBubbleContext, participants []id.CharacterStateID) bool
+}
+
+func (bd BarkDef) Validate() {
+	if bd.ID == "" {
+		panic("bark id was empty")
+	}
+	if len(bd.Participants) < 2 {
+		panic("barks need at least 2 participants")
+	}
+	if len(bd.Lines) == 0 {
+		panic("bark has no lines")
+	}
+	for _, line := range bd.Lines {
+		if line.Text == "" {
+			panic("bark line text was empty")
+		}
+		if line.Speaker < 0 || line.Speaker >= len(bd.Participants) {
+			panic("bark line speaker index doesn't match any participant")
+		}
+	}
+	if bd.RecentWithin < 0 || bd.Cooldown < 0 {
+		panic("bark durations can't be negative")
+	}
+}
+
+func (bd BarkDef) GetCooldown() time.Duration {
+	if bd.Cooldown == 0 {
+		return DefaultBarkCooldown
+	}
+	return bd.Cooldown
+}
+
+func (bd BarkDef) GetRecentWithin() time.Duration {
+	if bd.RecentWithin == 0 {
+		return DefaultBarkRecentWithin
+	}
+	return bd.RecentWithin
+}
+
+func (bl BarkLine) GetDuration() time.Duration {
+	if bl.Duration == 0 {
+		return DefaultBarkLineDuration
+	}
+	return bl.Duration
+}
@@ -65,6 +65,11 @@ type CharacterDef struct {
 	FootstepSFXDefID FootstepSFXDefID
 	ScheduleID       ScheduleID
 
+	// OPT: portrait and voice used while this character speaks in dialog. if unset, the ones in the character's dialog profile are used,
+	// and if those aren't set either, no portrait is shown and the default text blip sound is used.
+	Portrait *PortraitDef
+	Voice    *VoiceDef
+
 	BaseAttributes map[AttributeID]int // Base attribute levels (not including modifiers from traits, etc)
 	BaseSkills     map[SkillID]int     // Base skill levels (not including modifiers from traits, etc)
 	InitialTraits  []TraitID
@@ -83,6 +88,12 @@ func (cd CharacterDef) Validate() {
 	if cd.CultureID == "" {
 		logz.Panicln(string(cd.ID), "culture ID was empty")
 	}
+	if cd.Portrait != nil {
+		cd.Portrait.Validate()
+	}
+	if cd.Voice != nil {
+		cd.Voice.Validate()
+	}
 }
 
 // A CharacterGenerator is a definition of a "type" of character to generate in the game world.
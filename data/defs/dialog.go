@@ -2,8 +2,11 @@
 package defs
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/webbben/2d-game-engine/data/id"
 	"github.com/webbben/2d-game-engine/logz"
@@ -28,6 +31,45 @@ type DialogProfileDef struct {
 
 	PlayerNoticeReactions []SpeechBubbleReaction
 	SpeechBubbles         []SpeechBubbleDef
+
+	// OPT: portrait and voice for characters using this profile, if their character def doesn't set its own.
+	// Handy for giving a shared profile (like "town guard") a consistent voice.
+	Portrait *PortraitDef
+	Voice    *VoiceDef
+}
+
+func (pd DialogProfileDef) Validate() {
+	if err := pd.Check(); err != nil {
+		logz.Panicln("DialogProfileDef", pd.ProfileID, err)
+	}
+}
+
+// Check checks the parts of a profile that make up its dialog. It returns the problem instead of panicking,
+// so tools like the dialog script compiler and linter can report it.
+func (pd DialogProfileDef) Check() error {
+	if pd.ProfileID == "" {
+		return errors.New("profile ID was empty")
+	}
+	// a conversation always starts with a greeting, so without one, starting a dialog with this profile would panic
+	if len(pd.Greeting) == 0 {
+		return errors.New("profile has no greeting")
+	}
+	for _, resp := range pd.Greeting {
+		if err := resp.Check(); err != nil {
+			return fmt.Errorf("greeting: %w", err)
+		}
+	}
+	seen := make(map[TopicID]bool)
+	for _, topicID := range append(slices.Clone(pd.TopicsIDs), pd.KnowledgeTopics...) {
+		if topicID == "" {
+			return errors.New("profile has an empty topic ID")
+		}
+		if seen[topicID] {
+			return fmt.Errorf("topic %s is listed more than once", topicID)
+		}
+		seen[topicID] = true
+	}
+	return nil
 }
 
 // SpeechBubbleReaction is an interface meant for producing speech bubble text to show (if any)
@@ -58,18 +100,28 @@ type DialogTopic struct {
 }
 
 func (dt DialogTopic) Validate() {
+	if err := dt.Check(); err != nil {
+		logz.Panicln("DialogTopic", dt.ID, err)
+	}
+}
+
+// Check is the same as Validate, but returns the problem instead of panicking. (e.g. for tools like the dialog script compiler)
+func (dt DialogTopic) Check() error {
 	if dt.ID == "" {
-		panic("id was empty")
+		return errors.New("id was empty")
 	}
 	if dt.Prompt == "" {
-		panic("prompt was empty")
+		return errors.New("prompt was empty")
 	}
 	if len(dt.Responses) == 0 {
-		panic("responses was empty")
+		return errors.New("responses was empty")
 	}
 	for _, resp := range dt.Responses {
-		resp.Validate()
+		if err := resp.Check(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // DialogResponse defines a specific response to a topic prompt, according to an NPC's role or identity, a quest state, etc.
@@ -88,6 +140,14 @@ type DialogResponse struct {
 	Goodbye      bool              // if set, this response will only have a 'Goodbye' reply available, which ends the conversation.
 	Exit         bool              // if set,  the dialog will exit immediately, without any 'Goodbye' reply prompt or anything
 
+	// OPT: for group conversations; if set, this response is said by this character instead of the NPC the player is talking to.
+	// The speaker should be nearby (in the same map). Conditions (and effects) of this response are evaluated against the speaker,
+	// e.g. opinion conditions check the speaker's opinion of the player. For unique characters, the state ID is just the CharacterDefID.
+	Speaker id.CharacterStateID
+
+	Expression PortraitExpression // OPT: the speaker's portrait expression while saying this (e.g. "happy"). defaults to neutral.
+	Emote      *DialogEmote       // OPT: something the speaker acts out in the world as they say this (a nod, a turn, an animation, etc).
+
 	Replies []DialogReply // if the response should pose possibly replies by the player, set them here.
 
 	Action *DialogAction // if set, will fire first before any text or effects; can cause UI flows to happen, such as getting user text input.
@@ -101,8 +161,15 @@ type DialogResponse struct {
 }
 
 func (dr DialogResponse) Validate() {
+	if err := dr.Check(); err != nil {
+		logz.Panicln("DialogResponse", err, "response text:", dr.Text)
+	}
+}
+
+// Check is the same as Validate, but returns the problem instead of panicking.
+func (dr DialogResponse) Check() error {
 	if dr.Once && dr.ID == "" {
-		panic("responses marked as Once must have an ID")
+		return errors.New("responses marked as Once must have an ID")
 	}
 	if dr.Text == "" {
 		// if text is empty, then this must be a grouper
@@ -110,31 +177,40 @@ func (dr DialogResponse) Validate() {
 			// the reason we don't allow a grouper with no conditions is, the logic flow would always go into it, even if the options below it were invalid.
 			// in such a case, it's possible for the logic flow to miss valid options that were outside of the first grouper is encountered, railroading the dialog into
 			// a bad direction.
-			panic("is this supposed to be a grouper? no text is set, but no conditions are set either.")
+			return errors.New("is this supposed to be a grouper? no text is set, but no conditions are set either")
 		}
 		if dr.NextResponse == nil && len(dr.NextResponseOptions) == 0 {
-			panic("is this supposed to be a grouper? no text is set, but no next responses are set either.")
+			return errors.New("is this supposed to be a grouper? no text is set, but no next responses are set either")
+		}
+	}
+	if dr.Emote != nil {
+		if err := dr.Emote.Check(); err != nil {
+			return fmt.Errorf("emote: %w", err)
 		}
 	}
 	if dr.Goodbye {
 		if dr.NextResponse != nil {
-			panic("goodbye response has next response linked")
+			return errors.New("goodbye response has next response linked")
 		}
 		if len(dr.NextResponseOptions) > 0 {
-			panic("goodbye response has next response options set")
+			return errors.New("goodbye response has next response options set")
 		}
 		if len(dr.Replies) > 0 {
-			panic("goodbye response has replies defined. goodbye responses will automatically define a single 'goodbye' reply, so don't create one yourself.")
+			return errors.New("goodbye response has replies defined. goodbye responses will automatically define a single 'goodbye' reply, so don't create one yourself")
 		}
 	}
 	if dr.NextResponse != nil {
-		dr.NextResponse.Validate()
 		if len(dr.NextResponseOptions) > 0 {
-			panic("has next response, but also has next response options")
+			return errors.New("has next response, but also has next response options")
+		}
+		if err := dr.NextResponse.Check(); err != nil {
+			return err
 		}
 	} else {
 		for _, nr := range dr.NextResponseOptions {
-			nr.Validate()
+			if err := nr.Check(); err != nil {
+				return err
+			}
 		}
 	}
 	// next topics: next topics can be listed without corresponding '[...]' in the text, but all '[...]' in the text must have
@@ -142,12 +218,19 @@ func (dr DialogResponse) Validate() {
 	re := regexp.MustCompile(`\[([^\]]+)\]`)
 	count := len(re.FindAllStringSubmatch(dr.Text, -1))
 	if count > len(dr.NextTopics) {
-		logz.Panicln("DialogResponse", "number of square bracket pairs exceeds the number of NextTopics; for any square bracket pairs put into the dialog, there must be a NextTopic there to match with it. response text:", dr.Text)
+		return errors.New("number of square bracket pairs exceeds the number of NextTopics; for any square bracket pairs put into the dialog, there must be a NextTopic there to match with it")
+	}
+
+	if err := ValidateDialogText(dr.Text); err != nil {
+		return err
 	}
 
 	for _, reply := range dr.Replies {
-		reply.Validate()
+		if err := reply.Check(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // ReplyDecoration defines a decoration type for dialog replies
@@ -182,6 +265,10 @@ type DialogReply struct {
 	// if set, these are the possible responses the NPC can give to this reply (for when there are multiple different possible answers)
 	// this cannot be set at the same time as NextResponse
 	NextResponseOptions []DialogResponse
+
+	// if set, this reply is a chance-based check (e.g. persuade, intimidate, bribe), and the NPC's response depends on whether it passes.
+	// Can't be set at the same time as NextResponse or NextResponseOptions. If InfoText isn't set, the check's odds are shown as info text.
+	SkillCheck *DialogSkillCheck
 }
 
 func (dr DialogReply) info() string {
@@ -189,27 +276,154 @@ func (dr DialogReply) info() string {
 }
 
 func (dr DialogReply) Validate() {
+	if err := dr.Check(); err != nil {
+		logz.Panicln("DialogReply", err, dr.info())
+	}
+}
+
+// Check is the same as Validate, but returns the problem instead of panicking.
+func (dr DialogReply) Check() error {
 	if dr.Text == "" {
-		panic("dialog replies must have text set")
+		return errors.New("dialog replies must have text set")
 	}
 	if dr.Goodbye {
 		if dr.NextResponse != nil {
-			panic("goodbye reply has next response linked. " + dr.info())
+			return errors.New("goodbye reply has next response linked")
 		}
 		if len(dr.NextResponseOptions) > 0 {
-			panic("goodbye response has next response options set. " + dr.info())
+			return errors.New("goodbye response has next response options set")
 		}
 	}
 	if dr.NextResponse != nil {
-		dr.NextResponse.Validate()
 		if len(dr.NextResponseOptions) > 0 {
-			panic("has next response, but also has next response options. " + dr.info())
+			return errors.New("has next response, but also has next response options")
+		}
+		if err := dr.NextResponse.Check(); err != nil {
+			return err
 		}
 	} else {
 		for _, nr := range dr.NextResponseOptions {
-			nr.Validate()
+			if err := nr.Check(); err != nil {
+				return err
+			}
+		}
+	}
+	if dr.SkillCheck != nil {
+		if dr.Goodbye || dr.NextResponse != nil || len(dr.NextResponseOptions) > 0 {
+			return errors.New("skill check replies choose their own next response, so goodbye, next response and next response options can't be set")
 		}
+		if err := dr.SkillCheck.Check(); err != nil {
+			return fmt.Errorf("skill check: %w", err)
+		}
+	}
+	return nil
+}
+
+const (
+	SkillCheckPassedKey string = "SKILL_CHECK_PASSED"
+	SkillCheckFailedKey string = "SKILL_CHECK_FAILED"
+
+	DefaultSkillCheckXP int = 10
+)
+
+// SkillCheckMemoryKey gets the dialog memory key that records the outcome of a skill check.
+func SkillCheckMemoryKey(checkID string, passed bool) string {
+	if passed {
+		return SkillCheckPassedKey + ":" + checkID
+	}
+	return SkillCheckFailedKey + ":" + checkID
+}
+
+// DialogSkillCheck is a chance-based check for a dialog reply, like persuading, intimidating or bribing an NPC.
+// The outcome is remembered (by ID) in the dialog profile's memory, so the player only gets one roll; choosing the reply again just
+// gives the same outcome.
+//
+// The success chance (in percent) is:
+//
+//	50 + 2 * (skill - Difficulty) + (attribute - Difficulty) / 2 + opinion / 5
+//
+// where the attribute and opinion parts are only included if AttributeID and UseOpinion are set. It's always kept between 5% and 95%.
+type DialogSkillCheck struct {
+	ID          string      // REQ: used to remember the outcome. Must be unique within the dialog profile(s) this is used in.
+	SkillID     SkillID     // REQ: the skill being tested. It also gets some XP when the check is attempted.
+	AttributeID AttributeID // OPT: an attribute that also helps with the check (e.g. a personality attribute for persuasion)
+	Difficulty  int         // a player with a skill level equal to this has a 50/50 chance (before attribute and opinion)
+	UseOpinion  bool        // if set, the NPC's opinion of the player helps (or hurts) the chance
+	XP          int         // skill XP given when the check is attempted. defaults to DefaultSkillCheckXP.
+
+	Success *DialogResponse // REQ: the NPC's response if the check passes
+	Failure *DialogResponse // REQ: the NPC's response if the check fails
+}
+
+func (sc DialogSkillCheck) Validate() {
+	if err := sc.Check(); err != nil {
+		logz.Panicln("DialogSkillCheck", sc.ID, err)
+	}
+}
+
+// Check is the same as Validate, but returns the problem instead of panicking.
+func (sc DialogSkillCheck) Check() error {
+	if sc.ID == "" {
+		return errors.New("skill check ID was empty")
+	}
+	if sc.SkillID == "" {
+		return errors.New("skill ID was empty")
+	}
+	if sc.XP < 0 {
+		return errors.New("xp was negative")
+	}
+	if sc.Success == nil || sc.Failure == nil {
+		return errors.New("skill checks must have both a success and failure response")
+	}
+	if err := sc.Success.Check(); err != nil {
+		return fmt.Errorf("success: %w", err)
+	}
+	if err := sc.Failure.Check(); err != nil {
+		return fmt.Errorf("failure: %w", err)
 	}
+	return nil
+}
+
+func (sc DialogSkillCheck) GetXP() int {
+	if sc.XP == 0 {
+		return DefaultSkillCheckXP
+	}
+	return sc.XP
+}
+
+// SuccessChance gets the chance (as a percent) that the player passes this check right now.
+func (sc DialogSkillCheck) SuccessChance(ctx ConditionContext) int {
+	chance := 50 + 2*(ctx.GetPlayerSkillLevel(sc.SkillID)-sc.Difficulty)
+	if sc.AttributeID != "" {
+		chance += (ctx.GetPlayerAttributeLevel(sc.AttributeID) - sc.Difficulty) / 2
+	}
+	if sc.UseOpinion {
+		chance += ctx.GetOpinionOfPlayer() / 5
+	}
+	return min(max(chance, 5), 95)
+}
+
+// Result gets the outcome of this check, if it has already been attempted.
+func (sc DialogSkillCheck) Result(ctx ConditionContext) (passed bool, attempted bool) {
+	if ctx.HasMemory(SkillCheckMemoryKey(sc.ID, true)) {
+		return true, true
+	}
+	if ctx.HasMemory(SkillCheckMemoryKey(sc.ID, false)) {
+		return false, true
+	}
+	return false, false
+}
+
+// GetInfoText shows the odds of the check, or the outcome if it was already attempted.
+func (sc DialogSkillCheck) GetInfoText(ctx ConditionContext) string {
+	skillName := ctx.GetSkillDef(sc.SkillID).DisplayName
+	if passed, attempted := sc.Result(ctx); attempted {
+		if passed {
+			return fmt.Sprintf("%s check: passed", skillName)
+		}
+		return fmt.Sprintf("%s check: failed", skillName)
+	}
+	return fmt.Sprintf("%s check: %v%% chance", skillName, sc.SuccessChance(ctx))
 }
 
 type DialogCondition interface {
@@ -223,6 +437,7 @@ type ConditionContext interface {
 	GetActiveMapDef() MapDef
 	HasSeenTopic(id TopicID) bool
 	HasMemory(key string) bool
+	GetMemoryValue(key string) (string, bool)
 	GetCharacterDef(id CharacterDefID) CharacterDef
 	GetCharacterSocialRank(id id.CharacterStateID) SocialRank
 	CharacterHasRole(id id.CharacterStateID, roleID RoleID) bool
@@ -234,6 +449,7 @@ type ConditionContext interface {
 	GetPlayerSkillLevel(skillID SkillID) int
 	GetPlayerAttributeLevel(attrID AttributeID) int
 	GetOpinionOfPlayer() int
+	GetSkillDef(skillID SkillID) SkillDef
 }
 
 type MemoryCondition struct {
@@ -265,3 +481,165 @@ type DialogAction struct {
 	Scope  DialogActionResultScope // what the action's result should be applied to.
 	Params any                     // Params that are passed to the action's UI, modal, etc. You should be using an actual params struct defined for this Action type's UI.
 }
+
+// DialogTextVarSpec describes a dialog text variable, so that dialog text can be checked at load time.
+// The actual values are filled in by the dialog system (see dialogv2.RegisterDialogVariable).
+type DialogTextVarSpec struct {
+	IsFlag      bool // if set, this is a true/false flag for conditional fragments ("{?name|if true|if false}") instead of text to insert ("{name}")
+	TakesArg    bool // if set, the variable must be given an arg ("{name:arg}"); otherwise, it must not be given one.
+	NeedsWorld  bool // if set, the variable needs world info, so it can't be used in text shown outside of the world (e.g. books)
+	NeedsDialog bool // if set, the variable can only be used in dialogs
+}
+
+var dialogTextVarSpecs = make(map[string]DialogTextVarSpec)
+
+// RegisterDialogTextVarSpec registers a dialog text variable so that ValidateDialogText will recognize it.
+func RegisterDialogTextVarSpec(name string, spec DialogTextVarSpec) {
+	if name == "" {
+		panic("dialog variable name was empty")
+	}
+	if strings.ContainsAny(name, "{}|:?") {
+		logz.Panicln("RegisterDialogTextVarSpec", "dialog variable name has reserved characters:", name)
+	}
+	if _, exists := dialogTextVarSpecs[name]; exists {
+		logz.Panicln("RegisterDialogTextVarSpec", "dialog variable was already registered:", name)
+	}
+	dialogTextVarSpecs[name] = spec
+}
+
+// DialogTextToken is a single variable ("{name}" or "{name:arg}") or conditional fragment ("{?name:arg|if true|if false}") found in dialog text.
+type DialogTextToken struct {
+	Raw     string // the whole token as it was written, including braces
+	Pos     int    // where the token starts in the text it was parsed from (in bytes)
+	Name    string
+	Arg     string
+	IsFlag  bool     // if set, this is a conditional fragment
+	Options []string // for conditional fragments: the text to use if the flag is true, and (optionally) the text to use if it's false
+}
+
+// ParseDialogText finds all the (top level) variables and conditional fragments in some dialog text.
+// Conditional fragments can have variables, or even other conditional fragments, inside their options.
+func ParseDialogText(text string) ([]DialogTextToken, error) {
+	tokens := []DialogTextToken{}
+	depth := 0
+	start := 0
+	for i, r := range text {
+		switch r {
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				return nil, fmt.Errorf("unmatched '}' at position %v", i)
+			}
+			depth--
+			if depth == 0 {
+				tok, err := parseDialogTextToken(text[start : i+1])
+				if err != nil {
+					return nil, err
+				}
+				tok.Pos = start
+				tokens = append(tokens, tok)
+			}
+		}
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("unclosed '{' at position %v", start)
+	}
+	return tokens, nil
+}
+
+func parseDialogTextToken(raw string) (DialogTextToken, error) {
+	tok := DialogTextToken{Raw: raw}
+	head := raw[1 : len(raw)-1]
+	if strings.HasPrefix(head, "?") {
+		tok.IsFlag = true
+		parts := splitTopLevel(head[1:], '|')
+		if len(parts) < 2 || len(parts) > 3 {
+			return tok, fmt.Errorf("conditional fragment should look like {?flag|if true|if false}: %s", raw)
+		}
+		head = parts[0]
+		tok.Options = parts[1:]
+	}
+	tok.Name, tok.Arg, _ = strings.Cut(head, ":")
+	if tok.Name == "" {
+		return tok, fmt.Errorf("variable name was empty: %s", raw)
+	}
+	return tok, nil
+}
+
+// splitTopLevel splits a string by the separator, ignoring any separators that are inside of braces.
+func splitTopLevel(s string, sep rune) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i, r := range s {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// ValidateDialogText checks that all the variables and conditional fragments in some dialog text are well formed and registered.
+func ValidateDialogText(text string) error {
+	return validateDialogText(text, nil)
+}
+
+// ValidateBookText is like ValidateDialogText, but for text that's shown outside of dialogs and the world (e.g. books),
+// so it also checks that only variables that don't need world info or a dialog are used.
+func ValidateBookText(text string) error {
+	return validateDialogText(text, func(tok DialogTextToken, spec DialogTextVarSpec) error {
+		if spec.NeedsWorld || spec.NeedsDialog {
+			return fmt.Errorf("%s can only be used in dialogs or the game world, not in books: %s", tok.Name, tok.Raw)
+		}
+		return nil
+	})
+}
+
+// validateDialogText checks dialog text, along with any extra check for where the text is used (if given).
+func validateDialogText(text string, extraCheck func(tok DialogTextToken, spec DialogTextVarSpec) error) error {
+	tokens, err := ParseDialogText(text)
+	if err != nil {
+		return err
+	}
+	for _, tok := range tokens {
+		spec, exists := dialogTextVarSpecs[tok.Name]
+		if !exists {
+			return fmt.Errorf("unknown dialog variable: %s", tok.Raw)
+		}
+		if spec.IsFlag && !tok.IsFlag {
+			return fmt.Errorf("%s is a flag, so it can only be used in a conditional fragment ({?%s|...|...}): %s", tok.Name, tok.Name, tok.Raw)
+		}
+		if !spec.IsFlag && tok.IsFlag {
+			return fmt.Errorf("%s is not a flag, so it can't be used in a conditional fragment: %s", tok.Name, tok.Raw)
+		}
+		if spec.TakesArg && tok.Arg == "" {
+			return fmt.Errorf("%s requires an arg ({%s:arg}): %s", tok.Name, tok.Name, tok.Raw)
+		}
+		if !spec.TakesArg && tok.Arg != "" {
+			return fmt.Errorf("%s doesn't take an arg: %s", tok.Name, tok.Raw)
+		}
+		if extraCheck != nil {
+			if err := extraCheck(tok, spec); err != nil {
+				return err
+			}
+		}
+		for _, opt := range tok.Options {
+			if err := validateDialogText(opt, extraCheck); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
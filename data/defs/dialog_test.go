@@ -0,0 +1,53 @@
+package defs
+
+import "testing"
+
+func init() {
+	RegisterDialogTextVarSpec("test_anywhere", DialogTextVarSpec{})
+	RegisterDialogTextVarSpec("test_world", DialogTextVarSpec{NeedsWorld: true})
+	RegisterDialogTextVarSpec("test_dialog_flag", DialogTextVarSpec{IsFlag: true, NeedsDialog: true})
+}
+
+func TestParseDialogTextPositions(t *testing.T) {
+	text := "a {test_anywhere} b {?test_dialog_flag|{test_world}|c}"
+	tokens, err := ParseDialogText(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("expected 2 top level tokens, got %v", len(tokens))
+	}
+	for _, tok := range tokens {
+		if text[tok.Pos:tok.Pos+len(tok.Raw)] != tok.Raw {
+			t.Errorf("token position doesn't match its raw text: %+v", tok)
+		}
+	}
+}
+
+func TestValidateBookText(t *testing.T) {
+	tests := []struct {
+		text  string
+		valid bool
+	}{
+		{"plain text", true},
+		{"hello {test_anywhere}", true},
+		{"hello {test_world}", false},
+		{"{?test_dialog_flag|yes|no}", false},
+		{"hello {unknown_var}", false},
+	}
+	for _, tt := range tests {
+		err := ValidateBookText(tt.text)
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid=%v, got error: %v", tt.text, tt.valid, err)
+		}
+		// everything that's fine in a book is fine in dialog too
+		if tt.valid {
+			if err := ValidateDialogText(tt.text); err != nil {
+				t.Errorf("%q: valid book text should be valid dialog text: %v", tt.text, err)
+			}
+		}
+	}
+	if err := ValidateDialogText("hello {test_world}"); err != nil {
+		t.Error("world variables should be allowed in dialog text:", err)
+	}
+}
@@ -0,0 +1,67 @@
+package defs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// DialogFacing is which way a character turns when they emote.
+type DialogFacing string
+
+const (
+	FacePlayer DialogFacing = "player" // turn towards the player
+	FaceAway   DialogFacing = "away"   // turn away from the player
+	FaceLeft   DialogFacing = "L"
+	FaceRight  DialogFacing = "R"
+	FaceUp     DialogFacing = "U"
+	FaceDown   DialogFacing = "D"
+)
+
+// Gestures that are built into the engine (see entity.RegisterGesture for adding more).
+const (
+	GestureNod        = "nod"
+	GestureShakeHead  = "shake_head"
+	GestureBow        = "bow"
+	GestureLookAround = "look_around"
+)
+
+// DialogEmote makes the speaker of a dialog response act something out in the world, so the conversation doesn't feel so static.
+// Any combination of the fields can be set; they all play at once.
+type DialogEmote struct {
+	Face      DialogFacing // OPT: turn to face a direction, or towards (or away from) the player.
+	Gesture   string       // OPT: a short body movement, like "nod" or "shake_head".
+	Animation string       // OPT: a body animation to play once, like "slash". must be one of the animations in entity/body.
+	// OPT: instead of going back to idle, hold the animation's last frame (e.g. "shield" for a defensive stance) until the conversation ends,
+	// or another emote plays an animation.
+	HoldAnimation bool
+	Icon          string // OPT: a symbol that floats up above the speaker's head, like "!", "?" or "...".
+}
+
+func (e DialogEmote) Validate() {
+	if err := e.Check(); err != nil {
+		logz.Panicln("DialogEmote", err)
+	}
+}
+
+// Check is the same as Validate, but returns the problem instead of panicking.
+func (e DialogEmote) Check() error {
+	switch e.Face {
+	case "", FacePlayer, FaceAway, FaceLeft, FaceRight, FaceUp, FaceDown:
+	default:
+		return fmt.Errorf("unknown facing: %s", e.Face)
+	}
+	if e.HoldAnimation && e.Animation == "" {
+		return errors.New("hold animation is set, but there's no animation")
+	}
+	if e.IsEmpty() {
+		return errors.New("emote doesn't do anything")
+	}
+	return nil
+}
+
+// IsEmpty checks if the emote has nothing set.
+func (e DialogEmote) IsEmpty() bool {
+	return e.Face == "" && e.Gesture == "" && e.Animation == "" && e.Icon == ""
+}
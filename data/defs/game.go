@@ -45,12 +45,16 @@ type GameContext interface {
 
 type DataContext interface {
 	GetItemDef(itemID ItemID) ItemDef
+	GetSkillDef(skillID SkillID) SkillDef
 }
 
 type SaveFileContext interface {
 	SaveGame() (saveFileName string)
+	QuickSave() (saveFileName string)
+	RequestAutosave(reason string) // autosaves as soon as it's safe to (e.g. not during a dialog or loading screen)
 	GetAllExistingCharacters() []ExistingCharacterInfo
-	LoadGame(saveFilePath string)
+	LoadGame(saveFilePath string) // if the game world is already running, it's replaced
+	QuickLoad() bool              // loads the latest quicksave (of the current character, if in the game world)
 }
 
 type GameDialogContext interface {
@@ -60,10 +64,23 @@ type GameDialogContext interface {
 
 type GameQuestContext interface {
 	WorldEffectContext
+	QuestConditionContext
+	DataContext // for showing item and skill names in quest reward previews
+}
+
+// QuestConditionContext gives quest conditions read access to the parts of the game world they check against.
+type QuestConditionContext interface {
+	WorldInfoContext
+
+	GetPlayerItemCount(itemID ItemID) int // total quantity of the given item in the player's inventory
+	GetPlayerGold() int
+	PlayerHasRole(roleID RoleID) bool
+	GetNPCOpinionOfPlayer(npcID id.CharacterStateID) int
 }
 
 type WorldInfoContext interface {
 	GetCurrentGameTime() clock.GameTime
+	GetDayOfWeek() clock.DayOfWeek
 	GetMapID() MapID
 	GetActiveMapDef() MapDef
 	GetPlayerInfo() PlayerInfo
@@ -97,12 +114,15 @@ type WorldEffectContext interface {
 	AddItem(itemID ItemID, quantity int)
 	AddRole(roleID RoleID)
 	RemoveRole(roleID RoleID)
+	AddSkillXP(skillID SkillID, xp int)
+	AddKnowledge(topicID TopicID)
 
 	// NPCs
 
 	AssignTaskToNPC(id CharacterDefID, taskDef TaskDef, requireListener bool)
 	AddOpinionModifier(holder, subject id.CharacterStateID, mod OpinionModifier)
-	GetDialogNPC() id.CharacterStateID // if not in a dialog, returns empty string
+	GetDialogNPC() id.CharacterStateID                       // if not in a dialog, returns empty string
+	PlayEmote(charID id.CharacterStateID, emote DialogEmote) // has an NPC in the current map act out an emote (nod, turn, animation, etc)
 }
 
 type EventContext interface {
@@ -159,6 +179,15 @@ type PlayerInfo struct {
 	PlayerCulture CultureID
 }
 
+// SaveSlotType is the kind of save a save file is. Each kind has its own retention policy (see config.SaveRetention).
+type SaveSlotType string
+
+const (
+	SaveSlotManual SaveSlotType = "manual" // saves the player (or game) chose to make
+	SaveSlotAuto   SaveSlotType = "auto"   // saves made automatically on certain events, like entering a map. kept as a rotating set.
+	SaveSlotQuick  SaveSlotType = "quick"  // the quicksave slot
+)
+
 // SaveInfo is just an overview of a single save file - NOT the actual save data.
 // This is used for showing a preview of a save file, without actually loading all the data.
 type SaveInfo struct {
@@ -168,6 +197,15 @@ type SaveInfo struct {
 	CurrentMapID    MapID
 	CurrentGameTime clock.GameTime
 	SaveFilePath    string
+	SlotType        SaveSlotType
+	Label           string // for autosaves, what triggered it
+	PlayerLevel     int    // 0 if the game doesn't have a level system
+	Gold            int
+	MapDisplayName  string
+	RegionID        RegionID
+	PlayTime        time.Duration
+	ActiveQuestName string        // the quest the player was tracking, if any
+	Thumbnail       *ebiten.Image // a screenshot from when the game was saved. nil if the save doesn't have one.
 }
 
 // ExistingCharacterInfo gives info about an existing character that has save files.
@@ -177,4 +215,6 @@ type ExistingCharacterInfo struct {
 	DisplayName    string
 	RecentSave     SaveInfo
 	SaveFilePaths  []string
+	// saves that couldn't be read (e.g. truncated by a crash while saving). RecentSave is the newest save that isn't one of these.
+	CorruptSaveFilePaths []string
 }
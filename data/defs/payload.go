@@ -0,0 +1,239 @@
+package defs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/data/id"
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// Payload types are the concrete types that can be stored in "any" values which get saved to JSON, like event data
+// (Event.Data), task params (TaskDef.Params) and world effects carried by scheduled events.
+//
+// Without this, a struct in an any value comes back from JSON as a map[string]interface{}, and the type assertions that use it panic.
+// So, when these values are saved they're written along with a type tag, and when loaded the tag is used to decode them back into the
+// right Go type. Any type that might be put into one of these values and saved needs to be registered (in an init function).
+//
+// Generic JSON values (map[string]any and []any) don't need to be registered; they're saved without a tag, and load back the same way.
+// This is also what untagged data from older saves loads as, so it can be saved again.
+
+var (
+	payloadTypes = make(map[string]reflect.Type)
+	payloadTags  = make(map[reflect.Type]string)
+)
+
+func init() {
+	// builtin types
+	RegisterPayloadType("string", "")
+	RegisterPayloadType("bool", false)
+	RegisterPayloadType("int", 0)
+	RegisterPayloadType("int64", int64(0))
+	RegisterPayloadType("float64", float64(0))
+	RegisterPayloadType("[]string", []string{})
+
+	// common IDs and structs from defs
+	RegisterPayloadType("clock.GameTime", clock.GameTime{})
+	RegisterPayloadType("id.CharacterStateID", id.CharacterStateID(""))
+	RegisterPayloadType("defs.CharacterDefID", CharacterDefID(""))
+	RegisterPayloadType("defs.ItemID", ItemID(""))
+	RegisterPayloadType("defs.MapID", MapID(""))
+	RegisterPayloadType("defs.RoleID", RoleID(""))
+	RegisterPayloadType("defs.SkillID", SkillID(""))
+	RegisterPayloadType("defs.AttributeID", AttributeID(""))
+	RegisterPayloadType("defs.TopicID", TopicID(""))
+	RegisterPayloadType("defs.DialogProfileID", DialogProfileID(""))
+	RegisterPayloadType("defs.QuestID", QuestID(""))
+	RegisterPayloadType("defs.QuestStageID", QuestStageID(""))
+	RegisterPayloadType("defs.QuestTerminalStatus", QuestTerminalStatus(0))
+	RegisterPayloadType("defs.ScreenID", ScreenID(""))
+	RegisterPayloadType("defs.BookID", BookID(""))
+	RegisterPayloadType("defs.Event", Event{})
+	RegisterPayloadType("defs.TaskDef", TaskDef{})
+}
+
+// RegisterPayloadType registers a type that can be saved in payload values (see above). The tag is what's written in the JSON
+// to identify the type, so it shouldn't change once saves exist that use it. example is any value of the type.
+func RegisterPayloadType(tag string, example any) {
+	if tag == "" {
+		panic("payload type tag was empty")
+	}
+	t := reflect.TypeOf(example)
+	if t == nil {
+		logz.Panicln("RegisterPayloadType", "example value was nil:", tag)
+	}
+	if _, exists := payloadTypes[tag]; exists {
+		logz.Panicln("RegisterPayloadType", "payload type tag already registered:", tag)
+	}
+	if existingTag, exists := payloadTags[t]; exists {
+		logz.Panicln("RegisterPayloadType", "type", t, "is already registered with tag:", existingTag)
+	}
+	payloadTypes[tag] = t
+	payloadTags[t] = tag
+}
+
+// PayloadTypeRegistered checks if the type of the given value can be saved in payload values.
+func PayloadTypeRegistered(v any) bool {
+	if v == nil || isGenericJSON(v) {
+		return true
+	}
+	_, exists := payloadTags[reflect.TypeOf(v)]
+	return exists
+}
+
+// isGenericJSON checks if a value is what encoding/json decodes untyped objects and arrays into
+func isGenericJSON(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+// Payload is how a payload value is written in JSON: the value, along with the tag of its type.
+// An empty type means the value has no type info (e.g. nil, or saved before payloads were tagged), and is decoded as generic JSON.
+type Payload struct {
+	Type  string `json:",omitempty"`
+	Value json.RawMessage
+}
+
+// EncodePayload wraps a value with its type tag, so it can be decoded back into the same type (see DecodePayload).
+func EncodePayload(v any) (Payload, error) {
+	if v == nil {
+		return Payload{Value: json.RawMessage("null")}, nil
+	}
+	if isGenericJSON(v) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return Payload{}, fmt.Errorf("failed to encode untyped payload: %w", err)
+		}
+		return Payload{Value: data}, nil
+	}
+	tag, exists := payloadTags[reflect.TypeOf(v)]
+	if !exists {
+		return Payload{}, fmt.Errorf("payload type %T is not registered; register it with defs.RegisterPayloadType", v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Payload{}, fmt.Errorf("failed to encode payload of type %s: %w", tag, err)
+	}
+	return Payload{Type: tag, Value: data}, nil
+}
+
+// DecodePayload decodes a payload back into a value of its original type.
+func DecodePayload(p Payload) (any, error) {
+	if p.Type == "" {
+		if len(p.Value) == 0 {
+			return nil, nil
+		}
+		var v any
+		if err := json.Unmarshal(p.Value, &v); err != nil {
+			return nil, fmt.Errorf("failed to decode untyped payload: %w", err)
+		}
+		return v, nil
+	}
+	t, exists := payloadTypes[p.Type]
+	if !exists {
+		return nil, fmt.Errorf("unknown payload type %q; is it registered?", p.Type)
+	}
+	ptr := reflect.New(t)
+	if err := json.Unmarshal(p.Value, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to decode payload of type %s: %w", p.Type, err)
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// CheckPayloads checks that all of the event's data can be saved, including anything nested inside it (like a scheduled world effect's
+// task params). Call this when an event is going to be kept around and saved, so an unregistered type is caught where it's used
+// instead of when the game is next saved.
+func (e Event) CheckPayloads() error {
+	for k, v := range e.Data {
+		if !PayloadTypeRegistered(v) {
+			return fmt.Errorf("event %s, data %q: payload type %T is not registered; register it with defs.RegisterPayloadType", e.Type, k, v)
+		}
+	}
+	// nested values (events inside events, task defs inside effects, etc) are only checked by actually encoding them
+	if _, err := json.Marshal(e); err != nil {
+		return err
+	}
+	return nil
+}
+
+type eventJSON struct {
+	Type              EventType
+	Data              map[string]Payload
+	RequireSubscriber bool
+}
+
+// MarshalJSON writes the event with each data value tagged with its type, so that it comes back the same when loaded.
+func (e Event) MarshalJSON() ([]byte, error) {
+	out := eventJSON{
+		Type:              e.Type,
+		RequireSubscriber: e.RequireSubscriber,
+	}
+	if e.Data != nil {
+		out.Data = make(map[string]Payload)
+		for k, v := range e.Data {
+			p, err := EncodePayload(v)
+			if err != nil {
+				return nil, fmt.Errorf("event %s, data %q: %w", e.Type, k, err)
+			}
+			out.Data[k] = p
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var in eventJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	e.Type = in.Type
+	e.RequireSubscriber = in.RequireSubscriber
+	e.Data = nil
+	if in.Data != nil {
+		e.Data = make(map[string]any)
+		for k, p := range in.Data {
+			v, err := DecodePayload(p)
+			if err != nil {
+				return fmt.Errorf("event %s, data %q: %w", in.Type, k, err)
+			}
+			e.Data[k] = v
+		}
+	}
+	return nil
+}
+
+// MarshalJSON writes the task def with its params tagged with their type (see Payload).
+func (td TaskDef) MarshalJSON() ([]byte, error) {
+	type plainTaskDef TaskDef // avoids recursing into this function
+	params, err := EncodePayload(td.Params)
+	if err != nil {
+		return nil, fmt.Errorf("task %s params: %w", td.TaskID, err)
+	}
+	return json.Marshal(struct {
+		plainTaskDef
+		Params Payload
+	}{plainTaskDef(td), params})
+}
+
+func (td *TaskDef) UnmarshalJSON(data []byte) error {
+	type plainTaskDef TaskDef
+	var in struct {
+		plainTaskDef
+		Params Payload
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	params, err := DecodePayload(in.Params)
+	if err != nil {
+		return fmt.Errorf("task %s params: %w", in.TaskID, err)
+	}
+	*td = TaskDef(in.plainTaskDef)
+	td.Params = params
+	return nil
+}
@@ -0,0 +1,109 @@
+package defs
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/webbben/2d-game-engine/clock"
+)
+
+type testTaskParams struct {
+	TileX, TileY int
+	Target       CharacterDefID
+}
+
+type unregisteredParams struct {
+	Name string
+}
+
+func init() {
+	RegisterPayloadType("defs.testTaskParams", testTaskParams{})
+}
+
+func roundTrip[T any](t *testing.T, v T) T {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal("failed to marshal:", err)
+	}
+	var out T
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal("failed to unmarshal:", err)
+	}
+	return out
+}
+
+func TestEventRoundTrip(t *testing.T) {
+	event := Event{
+		Type: "test_event",
+		Data: map[string]any{
+			"name":   "wolf",
+			"count":  3,
+			"tags":   []string{"forest", "night"},
+			"itemID": ItemID("wolf_pelt"),
+			"time":   clock.GameTime{Hour: 8, Year: 1, Season: 2, DayOfSeason: 10},
+			"nested": Event{Type: "inner", Data: map[string]any{"mapID": MapID("town")}},
+			"task":   TaskDef{TaskID: "goto", Params: testTaskParams{TileX: 1, TileY: 2}},
+			"empty":  nil,
+		},
+		RequireSubscriber: true,
+	}
+
+	loaded := roundTrip(t, event)
+	if !reflect.DeepEqual(loaded, event) {
+		t.Errorf("event changed after round trip:\nwant %#v\ngot  %#v", event, loaded)
+	}
+}
+
+func TestEventUntypedDataRoundTrip(t *testing.T) {
+	// data from saves before payloads were tagged has no type, and loads as generic JSON
+	raw := `{"Type":"old_event","Data":{"pos":{"Value":{"X":1,"Y":2}},"list":{"Value":[1,"a"]},"num":{"Value":5}},"RequireSubscriber":false}`
+	var event Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := event.Data["pos"].(map[string]any); !ok {
+		t.Fatalf("untyped object should load as a map, got %T", event.Data["pos"])
+	}
+
+	// it has to be possible to save it again
+	if err := event.CheckPayloads(); err != nil {
+		t.Fatal("loaded untyped data should be saveable:", err)
+	}
+	loaded := roundTrip(t, event)
+	if !reflect.DeepEqual(loaded, event) {
+		t.Errorf("event changed after round trip:\nwant %#v\ngot  %#v", event, loaded)
+	}
+}
+
+func TestTaskDefRoundTrip(t *testing.T) {
+	x := 4
+	td := TaskDef{
+		TaskID:        "goto",
+		Priority:      2,
+		Params:        testTaskParams{TileX: 3, TileY: 7, Target: "guard"},
+		StartLocation: &TaskStartLocation{MapID: "town", TileX: &x},
+		NextTask:      &TaskDef{TaskID: "idle"},
+	}
+
+	loaded := roundTrip(t, td)
+	if !reflect.DeepEqual(loaded, td) {
+		t.Errorf("task def changed after round trip:\nwant %#v\ngot  %#v", td, loaded)
+	}
+}
+
+func TestCheckPayloadsUnregistered(t *testing.T) {
+	event := Event{Type: "test_event", Data: map[string]any{"params": unregisteredParams{Name: "x"}}}
+	if err := event.CheckPayloads(); err == nil {
+		t.Error("expected an error for an unregistered payload type")
+	}
+
+	// nested values are checked too
+	nested := Event{Type: "test_event", Data: map[string]any{
+		"task": TaskDef{TaskID: "goto", Params: unregisteredParams{Name: "x"}},
+	}}
+	if err := nested.CheckPayloads(); err == nil {
+		t.Error("expected an error for an unregistered payload type nested in a task def")
+	}
+}
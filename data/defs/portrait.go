@@ -0,0 +1,88 @@
+package defs
+
+import "github.com/webbben/2d-game-engine/logz"
+
+// PortraitExpression is a variant of a character's portrait, like "happy" or "angry".
+// Any name can be used, as long as the portrait defines it; these are just the common ones.
+type PortraitExpression string
+
+const (
+	ExpressionNeutral PortraitExpression = "neutral"
+	ExpressionHappy   PortraitExpression = "happy"
+	ExpressionAngry   PortraitExpression = "angry"
+	ExpressionSad     PortraitExpression = "sad"
+)
+
+// PortraitDef defines the portrait that shows for a character while they speak in dialog.
+//
+// If TilesetSrc is set, the portrait images are tiles in that tileset, one for each expression.
+// Otherwise, the portrait is rendered from the character's body (the same as their avatar in menus), which only has the one expression.
+type PortraitDef struct {
+	TilesetSrc  string                     // OPT: tileset that the portrait images are in. if unset, the character's avatar is used.
+	Expressions map[PortraitExpression]int // REQ if TilesetSrc is set: tile index of each expression. must have neutral, which is used when an expression isn't found.
+}
+
+func (def PortraitDef) Validate() {
+	if def.TilesetSrc == "" {
+		if len(def.Expressions) > 0 {
+			logz.Panicln("PortraitDef", "expressions are set, but there's no tileset to get them from")
+		}
+		return
+	}
+	if _, exists := def.Expressions[ExpressionNeutral]; !exists {
+		logz.Panicln("PortraitDef", "tileset portraits must have a neutral expression:", def.TilesetSrc)
+	}
+	for expr, index := range def.Expressions {
+		if index < 0 {
+			logz.Panicln("PortraitDef", "expression has a negative tile index:", expr, index)
+		}
+	}
+}
+
+// UsesAvatar checks if the portrait is rendered from the character's body, instead of from a tileset.
+func (def PortraitDef) UsesAvatar() bool {
+	return def.TilesetSrc == ""
+}
+
+// TileIndex gets the tile index of an expression, falling back to neutral if the portrait doesn't have it.
+func (def PortraitDef) TileIndex(expr PortraitExpression) int {
+	if index, exists := def.Expressions[expr]; exists {
+		return index
+	}
+	return def.Expressions[ExpressionNeutral]
+}
+
+// VoiceDef defines how a character "sounds" when their dialog text is being written out.
+type VoiceDef struct {
+	BlipSFX      SoundID // OPT: sound played as text is written. if unset, the default text blip sound is used.
+	Pitch        float64 // OPT: pitch of the blip sound. 1 is normal, 2 is an octave up, 0.5 an octave down. defaults to 1.
+	TickInterval int     // OPT: number of written characters between blips. lower sounds chattier. defaults to DefaultVoiceTickInterval.
+}
+
+// DefaultVoiceTickInterval is the tick interval used for text blips when a voice doesn't set its own.
+const DefaultVoiceTickInterval = 5
+
+func (def VoiceDef) Validate() {
+	if def.Pitch < 0 {
+		logz.Panicln("VoiceDef", "pitch can't be negative:", def.Pitch)
+	}
+	if def.TickInterval < 0 {
+		logz.Panicln("VoiceDef", "tick interval can't be negative:", def.TickInterval)
+	}
+}
+
+// GetPitch gets the voice's pitch, defaulting to 1.
+func (def VoiceDef) GetPitch() float64 {
+	if def.Pitch == 0 {
+		return 1
+	}
+	return def.Pitch
+}
+
+// GetTickInterval gets the voice's tick interval, defaulting to DefaultVoiceTickInterval.
+func (def VoiceDef) GetTickInterval() int {
+	if def.TickInterval == 0 {
+		return DefaultVoiceTickInterval
+	}
+	return def.TickInterval
+}
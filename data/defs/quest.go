@@ -1,12 +1,18 @@
 package defs
 
 import (
+	"strconv"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/data/id"
 	"github.com/webbben/2d-game-engine/logz"
 )
 
 type (
-	QuestID      string
-	QuestStageID string
+	QuestID          string
+	QuestStageID     string
+	QuestObjectiveID string
 
 	QuestActionType     string
 	QuestConditionType  string
@@ -14,6 +20,9 @@ type (
 	QuestStatus         string
 )
 
+// QuestInstanceSeparator separates a quest template ID from the instance number, in the IDs of quest instances (e.g. "wolf_bounty#3")
+const QuestInstanceSeparator = "#"
+
 type QuestDef struct {
 	ID          QuestID
 	Name        string // actual name that the player sees
@@ -22,6 +31,122 @@ type QuestDef struct {
 	StartStage  QuestStageID
 
 	StartTrigger QuestStartTrigger // REQ: this is what causes the quest to begin.
+
+	Rewards QuestRewardsDef // OPT: given to the player when the quest is completed (not when it fails).
+
+	Template *QuestTemplateDef // OPT: makes this quest a template, which can be started many times (e.g. bounty board or "radiant" quests).
+}
+
+// QuestTemplateDef turns a quest def into a template for repeatable quests.
+// Each time it starts, a new instance of the quest is created with its own ID (e.g. "wolf_bounty#3") and its own quest state,
+// so multiple instances can be tracked at the same time.
+//
+// Placeholders are written like "{target_npc}" in the quest's text (name, description, stage and objective text), condition params, and reward item/NPC IDs.
+// They are filled in when an instance starts, either by a generator or by whoever starts the instance directly.
+// NOTE: placeholders can't be used in OnEnter or reaction effects, since those are interfaces and we can't look inside them. They also can't be used in int params.
+type QuestTemplateDef struct {
+	Placeholders []string // names of the placeholders, without the braces. e.g. "target_npc"
+	// ID of a registered quest generator that fills in the placeholders when an instance is started by the start trigger.
+	// Required if there are placeholders, since the start trigger has no other way of filling them in.
+	Generator     string
+	CooldownHours int // OPT: hours after an instance ends before a new one can start
+	MaxActive     int // OPT: how many instances can be active at the same time. defaults to 1.
+	KeepFinished  int // OPT: how many finished instances are kept (e.g. for the journal) before the oldest ones are removed. defaults to 5.
+}
+
+func (t QuestTemplateDef) Validate() {
+	seen := make(map[string]bool)
+	for _, p := range t.Placeholders {
+		if p == "" {
+			panic("quest template placeholder name was empty")
+		}
+		if strings.ContainsAny(p, "{}") {
+			logz.Panicln("QuestTemplateDef", "placeholder names shouldn't include braces:", p)
+		}
+		if seen[p] {
+			logz.Panicln("QuestTemplateDef", "duplicate placeholder:", p)
+		}
+		seen[p] = true
+	}
+	if len(t.Placeholders) > 0 && t.Generator == "" {
+		logz.Panicln("QuestTemplateDef", "template has placeholders, but no generator to fill them in when its start trigger fires:", t.Placeholders)
+	}
+	if t.CooldownHours < 0 {
+		logz.Panicln("QuestTemplateDef", "cooldown hours is negative:", t.CooldownHours)
+	}
+	if t.MaxActive < 0 {
+		logz.Panicln("QuestTemplateDef", "max active is negative:", t.MaxActive)
+	}
+}
+
+// GetMaxActive gets how many instances of the template can be active at once.
+func (t QuestTemplateDef) GetMaxActive() int {
+	if t.MaxActive <= 0 {
+		return 1
+	}
+	return t.MaxActive
+}
+
+// GetKeepFinished gets how many finished (completed or failed) instances of the template are kept.
+func (t QuestTemplateDef) GetKeepFinished() int {
+	if t.KeepFinished <= 0 {
+		return 5
+	}
+	return t.KeepFinished
+}
+
+// QuestRewardsDef declares what the player gets for completing a quest.
+// Since it's declarative (unlike OnEnter effects), the UI can also show what a quest will give ahead of time.
+type QuestRewardsDef struct {
+	Gold      int
+	Items     []QuestItemReward
+	SkillXP   map[SkillID]int
+	Opinion   []QuestOpinionReward
+	Roles     []RoleID
+	Knowledge []TopicID
+}
+
+type QuestItemReward struct {
+	ItemID   ItemID
+	Quantity int // defaults to 1
+}
+
+// QuestOpinionReward changes how an NPC feels about the player.
+type QuestOpinionReward struct {
+	NPC    id.CharacterStateID
+	Mod    int
+	Reason string // OPT: shown in the opinion breakdown. defaults to the quest name.
+}
+
+func (r QuestRewardsDef) IsEmpty() bool {
+	return r.Gold == 0 && len(r.Items) == 0 && len(r.SkillXP) == 0 && len(r.Opinion) == 0 && len(r.Roles) == 0 && len(r.Knowledge) == 0
+}
+
+func (r QuestRewardsDef) Validate() {
+	if r.Gold < 0 {
+		logz.Panicln("QuestRewardsDef", "reward gold is negative:", r.Gold)
+	}
+	for _, it := range r.Items {
+		if it.ItemID == "" {
+			panic("reward item ID was empty")
+		}
+		if it.Quantity < 0 {
+			logz.Panicln("QuestRewardsDef", "reward item quantity is negative:", it.ItemID, it.Quantity)
+		}
+	}
+	for skillID, xp := range r.SkillXP {
+		if xp <= 0 {
+			logz.Panicln("QuestRewardsDef", "reward skill XP must be positive:", skillID, xp)
+		}
+	}
+	for _, op := range r.Opinion {
+		if op.NPC == "" {
+			panic("opinion reward NPC was empty")
+		}
+		if op.Mod == 0 {
+			logz.Panicln("QuestRewardsDef", "opinion reward mod is 0:", op.NPC)
+		}
+	}
 }
 
 // QuestStartTrigger defines conditions that will cause the quest to start.
@@ -49,6 +174,16 @@ func (qd QuestDef) Validate() {
 	if qd.StartStage == "" {
 		logz.Panicln(string(qd.ID), "quest def had no start stage defined")
 	}
+	for _, cond := range qd.StartTrigger.Conditions {
+		cond.Validate()
+	}
+	qd.Rewards.Validate()
+	if qd.Template != nil {
+		if strings.Contains(string(qd.ID), QuestInstanceSeparator) {
+			logz.Panicln(string(qd.ID), "quest template ID can't contain the instance separator:", QuestInstanceSeparator)
+		}
+		qd.Template.Validate()
+	}
 	startStageFound := false
 	for _, stage := range qd.Stages {
 		if stage.ID == qd.StartStage {
@@ -89,19 +224,114 @@ type QuestStageDef struct {
 	OnEnter        []WorldEffect // a list of (non-conditional) effects that execute right when this stage is reached.
 	Reactions      []QuestReactionDef
 	TerminalStatus QuestTerminalStatus // Determines if this reaction is a "quest end". If this is set, there should be no reactions
+
+	// OPT: goals the player works on in parallel during this stage, each with its own progress counter (e.g. "collect 5 wolf pelts" and "speak to 2 elders").
+	// Once they are complete, the quest moves to ObjectivesNextStage. Can be used alongside Reactions; whichever finishes the stage first wins.
+	Objectives          []QuestObjectiveDef
+	AnyObjective        bool         // if set, completing any one objective finishes the stage, instead of needing all of them.
+	ObjectivesNextStage QuestStageID // REQ if Objectives are set: the stage to move to once the objectives are complete.
+
+	Deadline *QuestDeadlineDef // OPT: if the stage isn't finished by the deadline, the quest moves to the deadline's fallback stage.
+
+	Target *QuestTargetDef // OPT: where the stage's goal is in the world. used for showing markers and the compass when this quest is tracked.
+}
+
+// QuestTargetDef is the place in the world a quest stage points the player to. Set one of:
+//
+// - NPC: wherever that NPC currently is
+//
+// - MapID + ObjectID: an object (by its Tiled ID) in a map
+//
+// - MapID + TileX/TileY: a specific tile in a map
+//
+// - MapID only: just the map itself
+type QuestTargetDef struct {
+	NPC          CharacterDefID
+	MapID        MapID
+	ObjectID     int
+	TileX, TileY *int
+}
+
+func (t QuestTargetDef) Validate() {
+	if t.NPC != "" {
+		if t.MapID != "" || t.ObjectID != 0 || t.TileX != nil || t.TileY != nil {
+			logz.Panicln("QuestTargetDef", "NPC target shouldn't have a map, object or tile set:", t.NPC)
+		}
+		return
+	}
+	if t.MapID == "" {
+		panic("quest target has neither an NPC nor a map ID set")
+	}
+	if (t.TileX == nil) != (t.TileY == nil) {
+		logz.Panicln("QuestTargetDef", "quest target tile needs both X and Y set:", t.MapID)
+	}
+	if t.ObjectID != 0 && t.TileX != nil {
+		logz.Panicln("QuestTargetDef", "quest target has both an object ID and a tile set; only one should be set:", t.MapID)
+	}
+}
+
+// QuestMarker is a quest target resolved to a position in the current map, for drawing markers or a compass in the HUD.
+type QuestMarker struct {
+	QuestID     QuestID
+	MapID       MapID   // the map the marker is in (always the current map)
+	X, Y        float64 // position (in pixels) in the current map
+	IsDoor      bool    // if set, the target is in another map, and this points to the door to take towards it
+	TargetMapID MapID   // the map the target is actually in
+}
+
+// QuestDeadlineDef is a time limit on a quest stage, e.g. "within 3 days" or "before the festival".
+// Set either Hours or At, but not both.
+// Deadlines are checked whenever an hour passes (including time lapses that skip past the deadline), so they have hour precision.
+type QuestDeadlineDef struct {
+	Hours         int             // number of game hours after the stage starts
+	At            *clock.GameTime // an absolute game time
+	FallbackStage QuestStageID    // REQ: the stage to move to when the deadline expires
+}
+
+func (d QuestDeadlineDef) Validate() {
+	if d.Hours == 0 && d.At == nil {
+		panic("quest deadline has neither Hours nor At set")
+	}
+	if d.Hours != 0 && d.At != nil {
+		panic("quest deadline has both Hours and At set; only one should be set")
+	}
+	if d.Hours < 0 {
+		logz.Panicln("QuestDeadlineDef", "deadline hours is negative:", d.Hours)
+	}
+	if d.At != nil {
+		d.At.Validate()
+	}
+	if d.FallbackStage == "" {
+		panic("quest deadline has no fallback stage")
+	}
+}
+
+// GetDeadlineTime gets the time the deadline expires at, for a stage that started at the given time.
+func (d QuestDeadlineDef) GetDeadlineTime(stageStart clock.GameTime) clock.GameTime {
+	if d.At != nil {
+		return *d.At
+	}
+	stageStart.AddTime(d.Hours)
+	return stageStart
 }
 
 func (stage QuestStageDef) Validate() {
 	if stage.ID == "" {
 		panic("stage ID was empty")
 	}
-	if len(stage.Reactions) == 0 && stage.TerminalStatus == 0 {
-		logz.Panicln("QuestStageDef", "no reactions, and no terminal status set; one of these must be set, or else there is no conclusion to the quest.", stage.ID)
+	if len(stage.Reactions) == 0 && len(stage.Objectives) == 0 && stage.TerminalStatus == 0 {
+		logz.Panicln("QuestStageDef", "no reactions, objectives, or terminal status set; one of these must be set, or else there is no conclusion to the quest.", stage.ID)
 	}
 	if stage.TerminalStatus != 0 {
 		if len(stage.Reactions) != 0 {
 			logz.Panicln("QuestStageDef", "a terminal status is set, but reactions are also set. only one or the other should be set.", stage.ID)
 		}
+		if len(stage.Objectives) != 0 {
+			logz.Panicln("QuestStageDef", "a terminal status is set, but objectives are also set.", stage.ID)
+		}
+		if stage.Deadline != nil {
+			logz.Panicln("QuestStageDef", "a terminal status is set, but a deadline is also set.", stage.ID)
+		}
 		if stage.Objective != "" {
 			logz.Panicln("QuestStageDef", "an objective is set for a terminal stage.", stage.ID)
 		}
@@ -109,6 +339,68 @@ func (stage QuestStageDef) Validate() {
 	for _, reaction := range stage.Reactions {
 		reaction.Validate()
 	}
+	if stage.Deadline != nil {
+		stage.Deadline.Validate()
+	}
+	if stage.Target != nil {
+		stage.Target.Validate()
+	}
+	if len(stage.Objectives) > 0 && stage.ObjectivesNextStage == "" {
+		logz.Panicln("QuestStageDef", "objectives are set, but there is no ObjectivesNextStage to move to once they're complete.", stage.ID)
+	}
+	objectiveIDs := make(map[QuestObjectiveID]bool)
+	for _, obj := range stage.Objectives {
+		obj.Validate()
+		if objectiveIDs[obj.ID] {
+			logz.Panicln("QuestStageDef", "duplicate objective ID in stage:", stage.ID, "objective:", obj.ID)
+		}
+		objectiveIDs[obj.ID] = true
+	}
+}
+
+// QuestObjectiveDef is a single countable goal within a quest stage.
+// Each time CountEvent fires and its conditions pass, the objective's counter goes up, until it reaches Required.
+//
+// Examples:
+//
+// - "Collect 5 wolf pelts": CountEvent = EventAddItem, condition event_data_equals itemID=wolf_pelt, CountKey = "quantity", Required = 5
+//
+// - "Speak to 2 elders": CountEvent = EventDialogEnded, condition event_data_contains profileID=elder, DistinctKey = "profileID", Required = 2
+type QuestObjectiveDef struct {
+	ID         QuestObjectiveID // REQ: identifies this objective's progress in the quest state. must be unique within the stage.
+	Text       string           // REQ: what the player sees in the journal, e.g. "Collect wolf pelts"
+	Required   int              // how many counts are needed to complete this objective. defaults to 1.
+	CountEvent EventType        // REQ: the event type that counts towards this objective
+	Conditions []QuestConditionDef
+
+	CountKey    string // OPT: event data key that holds the amount to count (e.g. "quantity"). if unset, each event counts as 1.
+	DistinctKey string // OPT: event data key whose value may only be counted once (e.g. "profileID", so talking to the same elder twice only counts once)
+}
+
+func (obj QuestObjectiveDef) Validate() {
+	if obj.ID == "" {
+		panic("objective ID was empty")
+	}
+	if obj.Text == "" {
+		logz.Panicln("QuestObjectiveDef", "objective has no text set:", obj.ID)
+	}
+	if obj.CountEvent == "" {
+		logz.Panicln("QuestObjectiveDef", "objective has no count event set; no way for it to make progress:", obj.ID)
+	}
+	if obj.Required < 0 {
+		logz.Panicln("QuestObjectiveDef", "objective required count is negative:", obj.ID)
+	}
+	for _, cond := range obj.Conditions {
+		cond.Validate()
+	}
+}
+
+// RequiredCount gets how many counts are needed to complete this objective.
+func (obj QuestObjectiveDef) RequiredCount() int {
+	if obj.Required <= 0 {
+		return 1
+	}
+	return obj.Required
 }
 
 // QuestReactionDef defins how a quest stage reacts, based on conditions.
@@ -129,9 +421,59 @@ func (qr QuestReactionDef) Validate() {
 	if qr.NextStage == "" {
 		logz.Panicln("QuestReactionDef", "no next stage defined.", qr.SubscribeEvent)
 	}
+	for _, cond := range qr.Conditions {
+		cond.Validate()
+	}
 }
 
+// QuestConditionDef is a single condition that must pass for a quest start trigger or stage reaction to run.
+// The Type decides how the condition is evaluated, and Params are the (type specific) arguments for it.
+// See the quest package for the built-in condition types and the params each of them takes.
 type QuestConditionDef struct {
 	Type   QuestConditionType
 	Params map[string]string
 }
+
+func (qc QuestConditionDef) Validate() {
+	if qc.Type == "" {
+		logz.Panicln("QuestConditionDef", "condition type was empty")
+	}
+	spec, exists := questConditionSpecs[qc.Type]
+	if !exists {
+		logz.Panicln("QuestConditionDef", "condition type is not registered:", qc.Type)
+	}
+	for _, param := range spec.RequiredParams {
+		if qc.Params[param] == "" {
+			logz.Panicln("QuestConditionDef", "condition is missing a required param:", qc.Type, "param:", param)
+		}
+	}
+	for _, param := range spec.IntParams {
+		val, exists := qc.Params[param]
+		if !exists {
+			continue
+		}
+		if _, err := strconv.Atoi(val); err != nil {
+			logz.Panicln("QuestConditionDef", "condition param should be an int:", qc.Type, "param:", param, "value:", val)
+		}
+	}
+}
+
+// QuestConditionSpec describes which params a quest condition type uses, so that broken quest data can be caught at load time.
+type QuestConditionSpec struct {
+	RequiredParams []string // params that must be set (and non-empty) on every condition of this type
+	IntParams      []string // params that must parse as an int, if they are set. can include optional params.
+}
+
+var questConditionSpecs = make(map[QuestConditionType]QuestConditionSpec)
+
+// RegisterQuestConditionSpec registers a quest condition type so that QuestConditionDef.Validate will recognize it.
+// You probably want quest.RegisterConditionType instead, which calls this and also registers the function that evaluates the condition.
+func RegisterQuestConditionSpec(condType QuestConditionType, spec QuestConditionSpec) {
+	if condType == "" {
+		panic("condition type was empty")
+	}
+	if _, exists := questConditionSpecs[condType]; exists {
+		logz.Panicln("RegisterQuestConditionSpec", "condition type was already registered:", condType)
+	}
+	questConditionSpecs[condType] = spec
+}
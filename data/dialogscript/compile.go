@@ -0,0 +1,580 @@
+package dialogscript
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+)
+
+// node is a single line of a script, along with the lines nested (indented) under it.
+type node struct {
+	line     int
+	indent   int
+	tokens   []string
+	children []*node
+}
+
+func (n *node) keyword() string {
+	return n.tokens[0]
+}
+
+// args gets the tokens after the keyword.
+func (n *node) args() []string {
+	return n.tokens[1:]
+}
+
+type compiler struct {
+	file string
+	errs ErrorList
+}
+
+func (c *compiler) errorf(line int, format string, args ...any) {
+	c.errs = append(c.errs, Error{File: c.file, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// check reports the problem found by a def's Check function (if any) as a script error at the given line.
+// Check is what Validate uses too, so scripts are held to the exact same rules as dialog defined in code.
+func (c *compiler) check(line int, what string, err error) {
+	if err != nil {
+		c.errorf(line, "invalid %s: %v", what, err)
+	}
+}
+
+// CompileFile reads and compiles a dialog script file.
+func CompileFile(path string) (Script, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return Script{}, fmt.Errorf("failed to read dialog script: %w", err)
+	}
+	return Compile(path, string(src))
+}
+
+// Compile compiles the source of a dialog script. The file name is only used for error messages.
+// If there are any errors, the returned error is an ErrorList.
+func Compile(file string, src string) (Script, error) {
+	c := compiler{file: file}
+	root := c.parseTree(src)
+
+	script := Script{}
+	for _, n := range root.children {
+		switch n.keyword() {
+		case "profile":
+			if profile := c.compileProfile(n); profile != nil {
+				script.Profiles = append(script.Profiles, profile)
+			}
+		case "topic":
+			if topic := c.compileTopic(n); topic != nil {
+				script.Topics = append(script.Topics, topic)
+			}
+		default:
+			c.errorf(n.line, "expected \"profile\" or \"topic\" at top level, but found %q", n.keyword())
+		}
+	}
+
+	if len(c.errs) > 0 {
+		return Script{}, c.errs
+	}
+	return script, nil
+}
+
+// parseTree splits the source into lines, tokenizes them, and nests them by indentation.
+func (c *compiler) parseTree(src string) *node {
+	root := &node{indent: -1}
+	stack := []*node{root}
+
+	for i, rawLine := range strings.Split(src, "\n") {
+		lineNum := i + 1
+		tokens, err := tokenize(rawLine)
+		if err != nil {
+			c.errorf(lineNum, "%v", err)
+			continue
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+
+		n := &node{line: lineNum, indent: indentWidth(rawLine), tokens: tokens}
+		for stack[len(stack)-1].indent >= n.indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, n)
+		stack = append(stack, n)
+	}
+
+	return root
+}
+
+// indentWidth counts the leading whitespace of a line. Tabs count as 4 spaces.
+func indentWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// tokenize splits a line into space separated tokens. Double quoted sections can contain spaces (and Go escapes),
+// and can be part of a larger token, e.g. key="some value". A "#" at the start of a token begins a comment.
+func tokenize(line string) ([]string, error) {
+	tokens := []string{}
+	var cur strings.Builder
+	inToken := false
+
+	for i := 0; i < len(line); {
+		ch := line[i]
+		switch {
+		case ch == '#' && !inToken:
+			return tokens, nil
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			if inToken {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				inToken = false
+			}
+			i++
+		case ch == '"':
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			s, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string %s: %w", line[i:end+1], err)
+			}
+			cur.WriteString(s)
+			inToken = true
+			i = end + 1
+		default:
+			cur.WriteByte(ch)
+			inToken = true
+			i++
+		}
+	}
+	if inToken {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+// textArg gets the text given after a keyword. Multiple tokens are joined with spaces, so quotes are optional for simple text.
+func (c *compiler) textArg(n *node) string {
+	if len(n.args()) == 0 {
+		c.errorf(n.line, "%q needs text after it", n.keyword())
+		return ""
+	}
+	return strings.Join(n.args(), " ")
+}
+
+func (c *compiler) noChildren(n *node) {
+	if len(n.children) > 0 {
+		c.errorf(n.children[0].line, "%q doesn't take any nested lines", n.keyword())
+	}
+}
+
+func (c *compiler) noArgs(n *node) {
+	if len(n.args()) > 0 {
+		c.errorf(n.line, "%q doesn't take any arguments", n.keyword())
+	}
+}
+
+func (c *compiler) compileProfile(n *node) *defs.DialogProfileDef {
+	if len(n.args()) != 1 {
+		c.errorf(n.line, "expected: profile <id>")
+		return nil
+	}
+	errCount := len(c.errs)
+	profile := &defs.DialogProfileDef{ProfileID: defs.DialogProfileID(n.args()[0])}
+
+	for _, child := range n.children {
+		switch child.keyword() {
+		case "topics":
+			c.noChildren(child)
+			for _, arg := range child.args() {
+				profile.TopicsIDs = append(profile.TopicsIDs, defs.TopicID(arg))
+			}
+		case "knowledge":
+			c.noChildren(child)
+			for _, arg := range child.args() {
+				profile.KnowledgeTopics = append(profile.KnowledgeTopics, defs.TopicID(arg))
+			}
+		case "greeting":
+			c.noArgs(child)
+			for _, respNode := range child.children {
+				if respNode.keyword() != "response" {
+					c.errorf(respNode.line, "expected \"response\" in greeting, but found %q", respNode.keyword())
+					continue
+				}
+				profile.Greeting = append(profile.Greeting, c.compileResponse(respNode))
+			}
+		default:
+			c.errorf(child.line, "unknown profile field %q", child.keyword())
+		}
+	}
+
+	if len(c.errs) == errCount {
+		c.check(n.line, "profile", profile.Check())
+	}
+	return profile
+}
+
+func (c *compiler) compileTopic(n *node) *defs.DialogTopic {
+	if len(n.args()) < 2 {
+		c.errorf(n.line, "expected: topic <id> <prompt>")
+		return nil
+	}
+	errCount := len(c.errs)
+	topic := &defs.DialogTopic{
+		ID:     defs.TopicID(n.args()[0]),
+		Prompt: strings.Join(n.args()[1:], " "),
+	}
+
+	for _, child := range n.children {
+		switch child.keyword() {
+		case "if":
+			if cond := c.compileCondition(child); cond != nil {
+				topic.Conditions = append(topic.Conditions, cond)
+			}
+		case "response":
+			topic.Responses = append(topic.Responses, c.compileResponse(child))
+		default:
+			c.errorf(child.line, "unknown topic field %q", child.keyword())
+		}
+	}
+
+	// only validate if everything inside compiled fine; otherwise the same problem would be reported twice
+	if len(c.errs) == errCount {
+		c.check(n.line, "topic", topic.Check())
+	}
+	return topic
+}
+
+// compileResponse compiles a "response", "next" or "option" block. The optional argument is the response ID.
+func (c *compiler) compileResponse(n *node) defs.DialogResponse {
+	errCount := len(c.errs)
+	resp := defs.DialogResponse{}
+	switch len(n.args()) {
+	case 0:
+	case 1:
+		resp.ID = n.args()[0]
+	default:
+		c.errorf(n.line, "expected: %s [id]", n.keyword())
+	}
+
+	textSet := false
+	for _, child := range n.children {
+		switch child.keyword() {
+		case "text":
+			c.noChildren(child)
+			if textSet {
+				c.errorf(child.line, "response text was already set")
+			}
+			resp.Text = c.textArg(child)
+			textSet = true
+		case "speaker":
+			c.noChildren(child)
+			if len(child.args()) != 1 {
+				c.errorf(child.line, "expected: speaker <character id>")
+				continue
+			}
+			resp.Speaker = id.CharacterStateID(child.args()[0])
+		case "expression":
+			c.noChildren(child)
+			if len(child.args()) != 1 {
+				c.errorf(child.line, "expected: expression <name>")
+				continue
+			}
+			resp.Expression = defs.PortraitExpression(child.args()[0])
+		case "emote":
+			if resp.Emote != nil {
+				c.errorf(child.line, "response already has an emote")
+				continue
+			}
+			resp.Emote = c.compileEmote(child)
+		case "if":
+			if cond := c.compileCondition(child); cond != nil {
+				resp.Conditions = append(resp.Conditions, cond)
+			}
+		case "effect":
+			if eff := c.compileDialogEffect(child); eff != nil {
+				resp.Effects = append(resp.Effects, eff)
+			}
+		case "world":
+			if eff := c.compileWorldEffect(child); eff != nil {
+				resp.WorldEffects = append(resp.WorldEffects, eff)
+			}
+		case "next_topics":
+			c.noChildren(child)
+			for _, arg := range child.args() {
+				resp.NextTopics = append(resp.NextTopics, defs.TopicID(arg))
+			}
+		case "once":
+			c.noArgs(child)
+			resp.Once = true
+		case "goodbye":
+			c.noArgs(child)
+			resp.Goodbye = true
+		case "exit":
+			c.noArgs(child)
+			resp.Exit = true
+		case "reply":
+			resp.Replies = append(resp.Replies, c.compileReply(child))
+		case "next":
+			if resp.NextResponse != nil {
+				c.errorf(child.line, "response already has a next response")
+				continue
+			}
+			next := c.compileResponse(child)
+			resp.NextResponse = &next
+		case "option":
+			resp.NextResponseOptions = append(resp.NextResponseOptions, c.compileResponse(child))
+		default:
+			c.errorf(child.line, "unknown response field %q", child.keyword())
+		}
+	}
+
+	if len(c.errs) == errCount {
+		c.check(n.line, "response", resp.Check())
+	}
+	return resp
+}
+
+func (c *compiler) compileReply(n *node) defs.DialogReply {
+	errCount := len(c.errs)
+	reply := defs.DialogReply{Text: c.textArg(n)}
+
+	for _, child := range n.children {
+		switch child.keyword() {
+		case "if":
+			if cond := c.compileCondition(child); cond != nil {
+				reply.Conditions = append(reply.Conditions, cond)
+			}
+		case "effect":
+			if eff := c.compileDialogEffect(child); eff != nil {
+				reply.Effects = append(reply.Effects, eff)
+			}
+		case "world":
+			if eff := c.compileWorldEffect(child); eff != nil {
+				reply.WorldEffects = append(reply.WorldEffects, eff)
+			}
+		case "goodbye":
+			c.noArgs(child)
+			reply.Goodbye = true
+		case "decoration":
+			c.noChildren(child)
+			switch strings.Join(child.args(), " ") {
+			case "good":
+				reply.Decoration = defs.ReplyDecoGood
+			case "bad":
+				reply.Decoration = defs.ReplyDecoBad
+			default:
+				c.errorf(child.line, "decoration should be \"good\" or \"bad\"")
+			}
+		case "info":
+			c.noChildren(child)
+			reply.InfoText = StaticInfoText(c.textArg(child))
+		case "next":
+			if reply.NextResponse != nil {
+				c.errorf(child.line, "reply already has a next response")
+				continue
+			}
+			next := c.compileResponse(child)
+			reply.NextResponse = &next
+		case "option":
+			reply.NextResponseOptions = append(reply.NextResponseOptions, c.compileResponse(child))
+		case "check":
+			if reply.SkillCheck != nil {
+				c.errorf(child.line, "reply already has a skill check")
+				continue
+			}
+			reply.SkillCheck = c.compileSkillCheck(child)
+		default:
+			c.errorf(child.line, "unknown reply field %q", child.keyword())
+		}
+	}
+
+	if len(c.errs) == errCount {
+		c.check(n.line, "reply", reply.Check())
+	}
+	return reply
+}
+
+// parseCall parses "<name> key=value ..." from the given tokens.
+func (c *compiler) parseCall(n *node, tokens []string) (name string, params *Params, ok bool) {
+	c.noChildren(n)
+	if len(tokens) == 0 {
+		c.errorf(n.line, "%q needs a name after it", n.keyword())
+		return "", nil, false
+	}
+	params, ok = c.parseParams(n, tokens[1:])
+	return tokens[0], params, ok
+}
+
+// parseParams parses key=value params from the given tokens.
+func (c *compiler) parseParams(n *node, tokens []string) (*Params, bool) {
+	vals := make(map[string]string)
+	for _, tok := range tokens {
+		key, val, found := strings.Cut(tok, "=")
+		if !found || key == "" {
+			c.errorf(n.line, "expected key=value param, but found %q", tok)
+			return nil, false
+		}
+		if _, exists := vals[key]; exists {
+			c.errorf(n.line, "param %q was given more than once", key)
+			return nil, false
+		}
+		vals[key] = val
+	}
+	return newParams(vals), true
+}
+
+// compileSkillCheck compiles "check <id> skill=<skill> difficulty=<n> [attr=<attribute>] [opinion=true] [xp=<n>]",
+// which must have a nested "success" and "failure" response.
+func (c *compiler) compileSkillCheck(n *node) *defs.DialogSkillCheck {
+	if len(n.args()) == 0 {
+		c.errorf(n.line, "expected: check <id> skill=<skill> difficulty=<n> ...")
+		return nil
+	}
+	params, ok := c.parseParams(n, n.args()[1:])
+	if !ok {
+		return nil
+	}
+	check := &defs.DialogSkillCheck{
+		ID:          n.args()[0],
+		SkillID:     defs.SkillID(params.String("skill")),
+		AttributeID: defs.AttributeID(params.OptString("attr", "")),
+		Difficulty:  params.Int("difficulty"),
+		UseOpinion:  params.Bool("opinion"),
+		XP:          params.OptInt("xp", 0),
+	}
+	if !c.checkParams(n, "check", params) {
+		return nil
+	}
+
+	for _, child := range n.children {
+		switch child.keyword() {
+		case "success":
+			if check.Success != nil {
+				c.errorf(child.line, "check already has a success response")
+				continue
+			}
+			resp := c.compileResponse(child)
+			check.Success = &resp
+		case "failure":
+			if check.Failure != nil {
+				c.errorf(child.line, "check already has a failure response")
+				continue
+			}
+			resp := c.compileResponse(child)
+			check.Failure = &resp
+		default:
+			c.errorf(child.line, "unknown check field %q (expected success or failure)", child.keyword())
+		}
+	}
+	return check
+}
+
+// compileEmote compiles "emote [face=<facing>] [gesture=<name>] [anim=<animation>] [hold=true] [icon=<text>]".
+func (c *compiler) compileEmote(n *node) *defs.DialogEmote {
+	c.noChildren(n)
+	params, ok := c.parseParams(n, n.args())
+	if !ok {
+		return nil
+	}
+	emote := &defs.DialogEmote{
+		Face:          defs.DialogFacing(params.OptString("face", "")),
+		Gesture:       params.OptString("gesture", ""),
+		Animation:     params.OptString("anim", ""),
+		HoldAnimation: params.Bool("hold"),
+		Icon:          params.OptString("icon", ""),
+	}
+	if !c.checkParams(n, "emote", params) {
+		return nil
+	}
+	return emote
+}
+
+// checkParams reports any problems with params once a builder has used them.
+func (c *compiler) checkParams(n *node, name string, params *Params) bool {
+	probs := params.problems()
+	for _, prob := range probs {
+		c.errorf(n.line, "%s: %s", name, prob)
+	}
+	return len(probs) == 0
+}
+
+func (c *compiler) compileCondition(n *node) defs.DialogCondition {
+	tokens := n.args()
+	negate := len(tokens) > 0 && tokens[0] == "not"
+	if negate {
+		tokens = tokens[1:]
+	}
+	name, params, ok := c.parseCall(n, tokens)
+	if !ok {
+		return nil
+	}
+	build, exists := conditionBuilders[name]
+	if !exists {
+		c.errorf(n.line, "unknown condition %q", name)
+		return nil
+	}
+	cond := build(params)
+	if !c.checkParams(n, name, params) {
+		return nil
+	}
+	if negate {
+		return notCondition{arg: cond}
+	}
+	return cond
+}
+
+func (c *compiler) compileDialogEffect(n *node) defs.DialogEffect {
+	name, params, ok := c.parseCall(n, n.args())
+	if !ok {
+		return nil
+	}
+	build, exists := dialogEffectBuilders[name]
+	if !exists {
+		c.errorf(n.line, "unknown dialog effect %q", name)
+		return nil
+	}
+	eff := build(params)
+	if !c.checkParams(n, name, params) {
+		return nil
+	}
+	return eff
+}
+
+func (c *compiler) compileWorldEffect(n *node) defs.WorldEffect {
+	name, params, ok := c.parseCall(n, n.args())
+	if !ok {
+		return nil
+	}
+	build, exists := worldEffectBuilders[name]
+	if !exists {
+		c.errorf(n.line, "unknown world effect %q", name)
+		return nil
+	}
+	eff := build(params)
+	if !c.checkParams(n, name, params) {
+		return nil
+	}
+	return eff
+}
@@ -0,0 +1,128 @@
+package dialogscript
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+type testCondition struct {
+	Key string
+	Min int
+}
+
+func (c testCondition) IsMet(ctx defs.ConditionContext) bool { return true }
+
+func init() {
+	RegisterCondition("test_cond", func(p *Params) defs.DialogCondition {
+		return testCondition{Key: p.String("key"), Min: p.OptInt("min", 0)}
+	})
+}
+
+const validScript = `
+# a comment
+profile guard
+  topics rumors
+  greeting
+    response
+      text "Halt."
+
+topic rumors "Rumors"
+  response told_rumor
+    once
+    if test_cond key=met min=2
+    text "They say a [lost sword] lies in the mine."
+    next_topics lost_sword
+    reply "Where?"
+      next
+        text "North."
+    reply "Bye."
+      goodbye
+`
+
+func TestCompileValidScript(t *testing.T) {
+	script, err := Compile("test.dlg", validScript)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(script.Profiles) != 1 || len(script.Topics) != 1 {
+		t.Fatalf("expected 1 profile and 1 topic, got %v and %v", len(script.Profiles), len(script.Topics))
+	}
+
+	profile := script.Profiles[0]
+	if profile.ProfileID != "guard" || len(profile.TopicsIDs) != 1 || len(profile.Greeting) != 1 {
+		t.Errorf("profile wasn't compiled correctly: %+v", profile)
+	}
+
+	topic := script.Topics[0]
+	if topic.ID != "rumors" || topic.Prompt != "Rumors" || len(topic.Responses) != 1 {
+		t.Fatalf("topic wasn't compiled correctly: %+v", topic)
+	}
+	resp := topic.Responses[0]
+	if resp.ID != "told_rumor" || !resp.Once || len(resp.Replies) != 2 || len(resp.NextTopics) != 1 {
+		t.Errorf("response wasn't compiled correctly: %+v", resp)
+	}
+	cond, ok := resp.Conditions[0].(testCondition)
+	if !ok || cond.Key != "met" || cond.Min != 2 {
+		t.Errorf("condition wasn't compiled correctly: %+v", resp.Conditions[0])
+	}
+	if resp.Replies[0].NextResponse == nil || resp.Replies[0].NextResponse.Text != "North." {
+		t.Errorf("reply's next response wasn't compiled correctly: %+v", resp.Replies[0])
+	}
+	if !resp.Replies[1].Goodbye {
+		t.Error("goodbye reply wasn't compiled correctly")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		msg  string
+	}{
+		{"unknown top level", "dialog foo", 1, `expected "profile" or "topic"`},
+		{"unknown condition", "topic t \"T\"\n  response\n    if nope\n    text \"hi\"", 3, `unknown condition "nope"`},
+		{"missing param", "topic t \"T\"\n  response\n    if test_cond\n    text \"hi\"", 3, `missing param "key"`},
+		{"unknown param", "topic t \"T\"\n  response\n    if test_cond key=a bad=1\n    text \"hi\"", 3, "bad"},
+		{"once without id", "topic t \"T\"\n  response\n    once\n    text \"hi\"", 2, "Once must have an ID"},
+		{"topic without responses", "topic t \"T\"", 1, "responses was empty"},
+		{"reply without text", "topic t \"T\"\n  response\n    text \"hi\"\n    reply \"\"", 4, "must have text"},
+		{"bad emote", "topic t \"T\"\n  response\n    emote face=sideways\n    text \"hi\"", 2, "unknown facing"},
+		{"profile without greeting", "profile p\n  topics a", 1, "no greeting"},
+		{"profile with duplicate topic", "profile p\n  topics a a\n  greeting\n    response\n      text \"hi\"", 1, "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile("test.dlg", tt.src)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			var errs ErrorList
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected an ErrorList, got %T", err)
+			}
+			for _, e := range errs {
+				if e.Line == tt.line && strings.Contains(e.Msg, tt.msg) {
+					return
+				}
+			}
+			t.Errorf("expected an error at line %v containing %q, got: %v", tt.line, tt.msg, err)
+		})
+	}
+}
+
+func TestCompileReportsAllErrors(t *testing.T) {
+	src := "topic a \"A\"\n  response\n    if nope\n    text \"hi\"\ntopic b \"B\"\n  response\n    bogus"
+	_, err := Compile("test.dlg", src)
+	var errs ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected an ErrorList, got %v", err)
+	}
+	if len(errs) < 2 {
+		t.Errorf("expected errors from both topics, got: %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+// Package dialogscript compiles dialog script files into dialog profile and topic defs, so that dialog can be written
+// in plain text instead of nested Go structs.
+//
+// A script is made of "profile" and "topic" blocks. Nesting is done by indentation, "#" starts a comment, and any value with spaces
+// goes in double quotes (Go string escapes work inside quotes). For example:
+//
+//	profile blacksmith
+//	  topics rumors
+//	  knowledge lost_sword
+//	  greeting
+//	    response
+//	      if social_rank rank=3 player=true geq=true
+//	      text "Good day to you, sir. Need anything forged?"
+//	    response
+//	      text "What do you want?"
+//
+//	topic rumors "Rumors"
+//	  response told_rumor
+//	    once
+//	    text "They say a [lost sword] lies somewhere in the old mine."
+//	    next_topics lost_sword
+//	    reply "Where is the mine?"
+//	      next
+//	        text "North of town. Be careful."
+//	        next
+//	          speaker old_miner
+//	          expression happy
+//	          emote gesture=shake_head icon=!
+//	          text "Careful? Ha! I worked that mine for thirty years."
+//	    reply "Not interested."
+//	      goodbye
+//	    reply "Surely you can tell me more. For a friend?"
+//	      check rumors_persuade skill=speechcraft attr=personality difficulty=30 opinion=true
+//	        success
+//	          text "Alright, alright. The entrance is behind the waterfall."
+//	        failure
+//	          text "Friend? I hardly know you."
+//
+// Conditions ("if"), dialog effects ("effect") and world effects ("world") are referenced by the names they were registered with,
+// followed by key=value params. "if not <condition>" negates a condition.
+package dialogscript
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// Script is the result of compiling a dialog script.
+type Script struct {
+	Profiles []*defs.DialogProfileDef
+	Topics   []*defs.DialogTopic
+}
+
+// Error is a problem found in a dialog script, along with where it was found.
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// ErrorList is all the errors found in a dialog script. Compiling doesn't stop at the first error, so writers can fix them all at once.
+type ErrorList []Error
+
+func (el ErrorList) Error() string {
+	lines := []string{}
+	for _, e := range el {
+		lines = append(lines, e.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type (
+	ConditionBuilder    func(p *Params) defs.DialogCondition
+	DialogEffectBuilder func(p *Params) defs.DialogEffect
+	WorldEffectBuilder  func(p *Params) defs.WorldEffect
+)
+
+var (
+	conditionBuilders    = make(map[string]ConditionBuilder)
+	dialogEffectBuilders = make(map[string]DialogEffectBuilder)
+	worldEffectBuilders  = make(map[string]WorldEffectBuilder)
+)
+
+// RegisterCondition makes a dialog condition usable in scripts, as "if <name> key=value ...".
+// Registering should be done before any scripts are compiled (e.g. in an init function).
+func RegisterCondition(name string, build ConditionBuilder) {
+	if _, exists := conditionBuilders[name]; exists {
+		logz.Panicln("dialogscript", "condition already registered:", name)
+	}
+	conditionBuilders[name] = build
+}
+
+// RegisterDialogEffect makes a dialog effect usable in scripts, as "effect <name> key=value ...".
+func RegisterDialogEffect(name string, build DialogEffectBuilder) {
+	if _, exists := dialogEffectBuilders[name]; exists {
+		logz.Panicln("dialogscript", "dialog effect already registered:", name)
+	}
+	dialogEffectBuilders[name] = build
+}
+
+// RegisterWorldEffect makes a world effect usable in scripts, as "world <name> key=value ...".
+func RegisterWorldEffect(name string, build WorldEffectBuilder) {
+	if _, exists := worldEffectBuilders[name]; exists {
+		logz.Panicln("dialogscript", "world effect already registered:", name)
+	}
+	worldEffectBuilders[name] = build
+}
+
+// Params are the key=value params given to a condition or effect in a script.
+// Builders read their params through the getters; any problems (missing or malformed params) are collected
+// and reported with the script's line number once the builder returns. Params that are never read are reported as unknown.
+type Params struct {
+	vals map[string]string
+	used map[string]bool
+	errs []string
+}
+
+func newParams(vals map[string]string) *Params {
+	return &Params{vals: vals, used: make(map[string]bool)}
+}
+
+func (p *Params) get(key string, required bool) (string, bool) {
+	p.used[key] = true
+	val, exists := p.vals[key]
+	if !exists && required {
+		p.errs = append(p.errs, fmt.Sprintf("missing param %q", key))
+	}
+	return val, exists
+}
+
+// String gets a required string param.
+func (p *Params) String(key string) string {
+	val, _ := p.get(key, true)
+	return val
+}
+
+// OptString gets an optional string param.
+func (p *Params) OptString(key string, defaultVal string) string {
+	val, exists := p.get(key, false)
+	if !exists {
+		return defaultVal
+	}
+	return val
+}
+
+// Int gets a required int param.
+func (p *Params) Int(key string) int {
+	val, exists := p.get(key, true)
+	if !exists {
+		return 0
+	}
+	return p.parseInt(key, val)
+}
+
+// OptInt gets an optional int param.
+func (p *Params) OptInt(key string, defaultVal int) int {
+	val, exists := p.get(key, false)
+	if !exists {
+		return defaultVal
+	}
+	return p.parseInt(key, val)
+}
+
+func (p *Params) parseInt(key, val string) int {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Sprintf("param %q should be an int, but was %q", key, val))
+	}
+	return n
+}
+
+// Float gets a required float param.
+func (p *Params) Float(key string) float64 {
+	val, exists := p.get(key, true)
+	if !exists {
+		return 0
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Sprintf("param %q should be a number, but was %q", key, val))
+	}
+	return f
+}
+
+// Bool gets an optional bool param (false if not set).
+func (p *Params) Bool(key string) bool {
+	val, exists := p.get(key, false)
+	if !exists {
+		return false
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Sprintf("param %q should be true or false, but was %q", key, val))
+	}
+	return b
+}
+
+// problems gets all the problems found with the params, including any params that the builder never read.
+func (p *Params) problems() []string {
+	probs := append([]string{}, p.errs...)
+	unknown := []string{}
+	for key := range p.vals {
+		if !p.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	slices.Sort(unknown)
+	for _, key := range unknown {
+		probs = append(probs, fmt.Sprintf("unknown param %q", key))
+	}
+	return probs
+}
+
+// StaticInfoText is info text for a dialog reply that is always the same.
+type StaticInfoText string
+
+func (t StaticInfoText) GetInfoText(ctx defs.ConditionContext) string {
+	return string(t)
+}
+
+// notCondition negates a condition ("if not ...").
+type notCondition struct {
+	arg defs.DialogCondition
+}
+
+func (c notCondition) IsMet(ctx defs.ConditionContext) bool {
+	return !c.arg.IsMet(ctx)
+}
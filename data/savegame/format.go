@@ -0,0 +1,182 @@
+package savegame
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+// Save files come in two formats, and when loading, the format is detected from the first bytes of the file:
+//
+// - plain JSON (".json"): what saves always used to be. Written when config.PlainJSONSaves is set, since it's easy to read and edit.
+//
+// - compressed (".sav"): a small binary container around the same JSON, laid out like this:
+//
+//	"2DSV" | format version (1 byte) | header length (uint32) | header JSON | gzipped save JSON | CRC-32 of everything before it (uint32)
+//
+// The header section isn't compressed, so previews can read it without decompressing the whole save.
+// The checksum at the end catches saves that were truncated or corrupted on disk.
+
+const (
+	PlainSaveExt      = ".json"
+	CompressedSaveExt = ".sav"
+)
+
+var compressedSaveMagic = []byte("2DSV")
+
+const compressedSaveFormatVersion byte = 1
+
+const (
+	compressedSavePrefixLen = 4 + 1 + 4 // magic + format version + header length
+	maxSaveHeaderLen        = 1 << 20   // the header is small; anything bigger than this means the length is corrupted
+)
+
+// saveFileHeader is the part of a save that's read for previews: the fields at the top of SaveFile, up to and including the header.
+type saveFileHeader struct {
+	Version  int
+	SaveTime time.Time
+	SlotType defs.SaveSlotType
+	Label    string
+	Header   SaveHeader
+}
+
+func (sf SaveFile) fileHeader() saveFileHeader {
+	return saveFileHeader{
+		Version:  sf.Version,
+		SaveTime: sf.SaveTime,
+		SlotType: sf.SlotType,
+		Label:    sf.Label,
+		Header:   sf.Header,
+	}
+}
+
+// HasSaveFileExt checks if a file name has the extension of one of the save file formats.
+func HasSaveFileExt(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == PlainSaveExt || ext == CompressedSaveExt
+}
+
+// saveFileExt gets the extension for new save files, based on which format is configured.
+func saveFileExt() string {
+	if config.PlainJSONSaves {
+		return PlainSaveExt
+	}
+	return CompressedSaveExt
+}
+
+func isCompressedSave(data []byte) bool {
+	return bytes.HasPrefix(data, compressedSaveMagic)
+}
+
+// encodeSaveFile encodes a save file in either the compressed or plain JSON format.
+func encodeSaveFile(sf SaveFile, compressed bool) ([]byte, error) {
+	if !compressed {
+		return json.MarshalIndent(sf, "", "  ")
+	}
+	saveJSON, err := json.Marshal(sf)
+	if err != nil {
+		return nil, err
+	}
+	return encodeCompressedSave(sf.fileHeader(), saveJSON)
+}
+
+func encodeCompressedSave(header saveFileHeader, saveJSON []byte) ([]byte, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode save header: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(compressedSaveMagic)
+	buf.WriteByte(compressedSaveFormatVersion)
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(headerJSON))))
+	buf.Write(headerJSON)
+
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(saveJSON); err != nil {
+		return nil, fmt.Errorf("failed to compress save data: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress save data: %w", err)
+	}
+
+	checksum := crc32.ChecksumIEEE(buf.Bytes())
+	buf.Write(binary.LittleEndian.AppendUint32(nil, checksum))
+	return buf.Bytes(), nil
+}
+
+// DecodeSaveFile gets the save JSON out of the contents of a save file, in whichever format it was written.
+// For compressed saves, the checksum is verified first, so a truncated or corrupted save gives an error instead of bad data.
+func DecodeSaveFile(data []byte) (saveJSON []byte, compressed bool, err error) {
+	if !isCompressedSave(data) {
+		return data, false, nil
+	}
+	if len(data) < compressedSavePrefixLen+4 {
+		return nil, true, fmt.Errorf("compressed save is too short (%v bytes); it may have been truncated", len(data))
+	}
+
+	body, checksum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != checksum {
+		return nil, true, fmt.Errorf("compressed save checksum doesn't match; the file is truncated or corrupted")
+	}
+
+	if v := body[len(compressedSaveMagic)]; v != compressedSaveFormatVersion {
+		return nil, true, fmt.Errorf("unknown compressed save format version: %v", v)
+	}
+	headerLen := int(binary.LittleEndian.Uint32(body[len(compressedSaveMagic)+1:]))
+	if compressedSavePrefixLen+headerLen > len(body) {
+		return nil, true, fmt.Errorf("compressed save header length (%v) is past the end of the file", headerLen)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(body[compressedSavePrefixLen+headerLen:]))
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to decompress save data: %w", err)
+	}
+	defer zr.Close()
+	saveJSON, err = io.ReadAll(zr)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to decompress save data: %w", err)
+	}
+	return saveJSON, true, nil
+}
+
+// readAnySaveHeader reads the header of a save in either format, without loading the rest of the save.
+// The checksum isn't verified here, since that means reading the whole file; a corrupted save is caught once it's actually loaded.
+func readAnySaveHeader(r io.Reader) (h saveFileHeader, found bool, err error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(compressedSaveMagic))
+	if err != nil || !bytes.Equal(magic, compressedSaveMagic) {
+		// not a compressed save (or too short to be one); let the JSON reader deal with it
+		return readSaveHeader(br)
+	}
+
+	prefix := make([]byte, compressedSavePrefixLen)
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		return h, false, fmt.Errorf("failed to read compressed save header: %w", err)
+	}
+	if v := prefix[len(compressedSaveMagic)]; v != compressedSaveFormatVersion {
+		return h, false, fmt.Errorf("unknown compressed save format version: %v", v)
+	}
+	headerLen := binary.LittleEndian.Uint32(prefix[len(compressedSaveMagic)+1:])
+	if headerLen > maxSaveHeaderLen {
+		return h, false, fmt.Errorf("compressed save header length (%v) is too big; the file is probably corrupted", headerLen)
+	}
+	headerJSON := make([]byte, headerLen)
+	if _, err := io.ReadFull(br, headerJSON); err != nil {
+		return h, false, fmt.Errorf("failed to read compressed save header: %w", err)
+	}
+	if err := json.Unmarshal(headerJSON, &h); err != nil {
+		return h, false, fmt.Errorf("failed to decode compressed save header: %w", err)
+	}
+	return h, true, nil
+}
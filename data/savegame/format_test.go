@@ -0,0 +1,96 @@
+package savegame
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+func testSaveFile() SaveFile {
+	return SaveFile{
+		Version:         SaveFileVersion,
+		SaveTime:        time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		SlotType:        defs.SaveSlotAuto,
+		Label:           "slept",
+		Header:          SaveHeader{CharacterName: "Tester", Gold: 42, MapID: "town"},
+		CurrentMapID:    "town",
+		CurrentGameTime: "0001-01-01-08-00",
+	}
+}
+
+func TestCompressedSaveRoundTrip(t *testing.T) {
+	sf := testSaveFile()
+	data, err := encodeSaveFile(sf, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isCompressedSave(data) {
+		t.Fatal("encoded save doesn't start with the compressed save magic")
+	}
+
+	saveJSON, compressed, err := DecodeSaveFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !compressed {
+		t.Error("compressed save wasn't detected as compressed")
+	}
+	var loaded SaveFile
+	if err := json.Unmarshal(saveJSON, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CurrentMapID != sf.CurrentMapID || loaded.Header != sf.Header || !loaded.SaveTime.Equal(sf.SaveTime) {
+		t.Errorf("loaded save doesn't match: got %+v", loaded)
+	}
+}
+
+func TestCompressedSaveCorruption(t *testing.T) {
+	data, err := encodeSaveFile(testSaveFile(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := DecodeSaveFile(data[:len(data)-10]); err == nil {
+		t.Error("truncated save was decoded without an error")
+	}
+
+	corrupted := bytes.Clone(data)
+	corrupted[len(corrupted)/2] ^= 0xFF
+	if _, _, err := DecodeSaveFile(corrupted); err == nil {
+		t.Error("corrupted save was decoded without an error")
+	}
+}
+
+func TestReadAnySaveHeader(t *testing.T) {
+	sf := testSaveFile()
+	for _, compressed := range []bool{true, false} {
+		data, err := encodeSaveFile(sf, compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, found, err := readAnySaveHeader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("compressed=%v: %s", compressed, err)
+		}
+		if !found {
+			t.Fatalf("compressed=%v: header not found", compressed)
+		}
+		if h.Version != sf.Version || h.SlotType != sf.SlotType || h.Label != sf.Label || h.Header != sf.Header {
+			t.Errorf("compressed=%v: header doesn't match: got %+v", compressed, h)
+		}
+	}
+}
+
+func TestPlainSaveIsNotDecompressed(t *testing.T) {
+	data := []byte(`{"Version": 0}`)
+	saveJSON, compressed, err := DecodeSaveFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compressed || !bytes.Equal(saveJSON, data) {
+		t.Error("plain JSON save was changed by decoding")
+	}
+}
@@ -0,0 +1,144 @@
+package savegame
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	characterstate "github.com/webbben/2d-game-engine/entity/characterState"
+	"github.com/webbben/2d-game-engine/item"
+	"github.com/webbben/2d-game-engine/logz"
+	"github.com/webbben/2d-game-engine/quest"
+	"github.com/webbben/2d-game-engine/skills"
+	"github.com/webbben/2d-game-engine/utils/files"
+)
+
+// SaveHeader is a summary of a save, for showing previews on load screens. It's written near the top of the save file,
+// so it can be read without loading the rest of the world state (see GetSaveInfo).
+type SaveHeader struct {
+	CharacterName   string
+	UniquePlayerID  defs.UniquePlayerID
+	PlayerLevel     int // 0 if the game doesn't have a level system loaded
+	Gold            int
+	MapID           defs.MapID
+	MapDisplayName  string
+	RegionID        defs.RegionID
+	GameTime        clock.GameTimestamp
+	PlayTime        time.Duration // total time spent playing this character, across all sessions
+	ActiveQuestName string        // the quest the player is tracking, if any
+	HasThumbnail    bool          // if set, there's a thumbnail image next to the save file (see ThumbnailPath)
+}
+
+func buildSaveHeader(sf SaveFile, dataman *datamanager.DataManager, questMgr *quest.QuestManager, playTime time.Duration) SaveHeader {
+	playerState := dataman.GetCharacterState(id.CharacterStateID(defs.PlayerID))
+	mapInfo, _, _ := dataman.GetAllMapData(sf.CurrentMapID)
+
+	header := SaveHeader{
+		CharacterName:  sf.PlayerCharacterDef.DisplayName,
+		UniquePlayerID: sf.PlayerCharacterDef.UniquePlayerID,
+		Gold:           item.CountMoney(playerState.StandardInventory, dataman),
+		MapID:          sf.CurrentMapID,
+		MapDisplayName: mapInfo.DisplayName,
+		RegionID:       mapInfo.RegionID,
+		GameTime:       sf.CurrentGameTime,
+		PlayTime:       playTime,
+	}
+
+	if dataman.LevelSysParams != nil && sf.PlayerCharacterDef.ClassDefID != "" {
+		skillLevels, _ := characterstate.CalculateSkillsAndAttributes(playerState.ID, dataman)
+		classDef := dataman.GetClassDef(sf.PlayerCharacterDef.ClassDefID)
+		header.PlayerLevel = skills.CalculateLevelFromSkills(skillLevels, classDef.SkillCategories, *dataman.LevelSysParams)
+	}
+
+	if sf.Quests.Tracked != "" {
+		header.ActiveQuestName = questMgr.GetQuestDef(sf.Quests.Tracked).Name
+	}
+
+	return header
+}
+
+// ThumbnailPath gets the path of a save's thumbnail image, which is kept next to the save file.
+func ThumbnailPath(saveFilePath string) string {
+	return strings.TrimSuffix(saveFilePath, filepath.Ext(saveFilePath)) + ".png"
+}
+
+func writeThumbnail(saveFilePath string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return files.WriteFileAtomic(ThumbnailPath(saveFilePath), buf.Bytes())
+}
+
+// LoadThumbnail loads the thumbnail image of a save. Returns nil if the save has no thumbnail.
+// Each call creates a new ebiten image, so don't call this repeatedly in a loop.
+func LoadThumbnail(saveFilePath string) *ebiten.Image {
+	data, err := os.ReadFile(ThumbnailPath(saveFilePath))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logz.Warnln("LoadThumbnail", "failed to read thumbnail:", err)
+		}
+		return nil
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		logz.Warnln("LoadThumbnail", "failed to decode thumbnail:", err)
+		return nil
+	}
+	return ebiten.NewImageFromImage(img)
+}
+
+// readSaveHeader reads just the fields at the top of a plain JSON save file, up to and including the header, and stops there.
+// found is false if the save doesn't have a header where we expect it (e.g. it's from an older version).
+func readSaveHeader(r io.Reader) (sf saveFileHeader, found bool, err error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return sf, false, err
+	}
+	if tok != json.Delim('{') {
+		return sf, false, fmt.Errorf("save file doesn't start with a JSON object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return sf, false, err
+		}
+		key, _ := tok.(string)
+		var dst any
+		switch key {
+		case "Version":
+			dst = &sf.Version
+		case "SaveTime":
+			dst = &sf.SaveTime
+		case "SlotType":
+			dst = &sf.SlotType
+		case "Label":
+			dst = &sf.Label
+		case "Header":
+			if err := dec.Decode(&sf.Header); err != nil {
+				return sf, false, fmt.Errorf("failed to decode save header: %w", err)
+			}
+			return sf, true, nil
+		default:
+			// the header comes before the world state, so if we got here there's no header
+			return sf, false, nil
+		}
+		if err := dec.Decode(dst); err != nil {
+			return sf, false, fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+	}
+	return sf, false, nil
+}
@@ -0,0 +1,247 @@
+package savegame
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/logz"
+	"github.com/webbben/2d-game-engine/utils/files"
+)
+
+// SaveFileVersion is the version of the save file format. Any time the shape of SaveFile (or any of the states inside it) changes
+// in a way that would break older saves, bump this and register a migration that upgrades saves from the previous version.
+//
+// Saves from before versioning existed have no version field, and are treated as version 0.
+var SaveFileVersion int = 3
+
+// SaveData is the raw JSON data of a save file, decoded into generic maps and slices so that migrations can reshape it freely.
+// Numbers are decoded as json.Number, so they keep their exact value.
+type SaveData map[string]any
+
+// Migration upgrades save data from one version to the next.
+type Migration struct {
+	From        int    // the version this migration upgrades from; it upgrades to From+1.
+	Description string // a short note on what changed, for logs and the upgrade tool.
+	Migrate     func(data SaveData) error
+}
+
+// migrations are keyed by the version they upgrade from
+var migrations = make(map[int]Migration)
+
+func init() {
+	registerMigration(Migration{
+		From:        0,
+		Description: "add save file version",
+		// nothing changed in the format itself; saves from before versioning just get a version number now.
+		Migrate: func(data SaveData) error { return nil },
+	})
+	registerMigration(Migration{
+		From:        1,
+		Description: "tag the types of scheduled event data",
+		Migrate:     migrateEventPayloads,
+	})
+	registerMigration(Migration{
+		From:        2,
+		Description: "add save header",
+		Migrate:     migrateSaveHeader,
+	})
+}
+
+// migrateEventPayloads wraps the data values of scheduled events in the payload format (see defs.Payload).
+// The original types weren't saved, so these are left untyped; they load the same way they did before.
+func migrateEventPayloads(data SaveData) error {
+	schedule, ok := data["FutureScheduledEvents"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	for timestamp, val := range schedule {
+		events, ok := val.([]any)
+		if !ok {
+			return fmt.Errorf("scheduled events at %s aren't a list", timestamp)
+		}
+		for _, ev := range events {
+			event, ok := ev.(map[string]any)
+			if !ok {
+				return fmt.Errorf("scheduled event at %s isn't an object", timestamp)
+			}
+			eventData, ok := event["Data"].(map[string]any)
+			if !ok {
+				continue
+			}
+			for k, v := range eventData {
+				eventData[k] = map[string]any{"Value": v}
+			}
+		}
+	}
+	return nil
+}
+
+// migrateSaveHeader adds a header with what can be found in the save data itself. Things like gold and level need the game's defs
+// to work out, so those are left empty until the character is saved again.
+func migrateSaveHeader(data SaveData) error {
+	header := map[string]any{
+		"MapID":    data["CurrentMapID"],
+		"GameTime": data["CurrentGameTime"],
+	}
+	if playerDef, ok := data["PlayerCharacterDef"].(map[string]any); ok {
+		header["CharacterName"] = playerDef["DisplayName"]
+		header["UniquePlayerID"] = playerDef["UniquePlayerID"]
+	}
+	data["Header"] = header
+	return nil
+}
+
+func registerMigration(m Migration) {
+	if m.From < 0 {
+		logz.Panicln("registerMigration", "migration has a negative version:", m.From)
+	}
+	if m.Migrate == nil {
+		logz.Panicln("registerMigration", "migration has no migrate function:", m.From)
+	}
+	if _, exists := migrations[m.From]; exists {
+		logz.Panicln("registerMigration", "a migration already exists for version", m.From)
+	}
+	migrations[m.From] = m
+}
+
+// GetSaveDataVersion gets the version a save was written with. Saves without a version are version 0.
+func GetSaveDataVersion(data SaveData) (int, error) {
+	val, exists := data["Version"]
+	if !exists || val == nil {
+		return 0, nil
+	}
+	num, ok := val.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("save version is not a number: %v", val)
+	}
+	v, err := num.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("save version is not an integer: %w", err)
+	}
+	return int(v), nil
+}
+
+// MigrateSaveData upgrades raw save file JSON to the current SaveFileVersion, running each migration in order.
+// For compressed saves, get the JSON out with DecodeSaveFile first.
+// Returns the migrated JSON (in the plain JSON save format), along with the version the save was originally on.
+func MigrateSaveData(raw []byte) (migrated []byte, fromVersion int, err error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var data SaveData
+	if err := dec.Decode(&data); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode save data: %w", err)
+	}
+	if data == nil {
+		return nil, 0, fmt.Errorf("save data is empty")
+	}
+
+	fromVersion, err = GetSaveDataVersion(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if fromVersion > SaveFileVersion {
+		return nil, fromVersion, fmt.Errorf("save is version %v, which is newer than this game's save version (%v)", fromVersion, SaveFileVersion)
+	}
+	if fromVersion == SaveFileVersion {
+		return raw, fromVersion, nil
+	}
+
+	for v := fromVersion; v < SaveFileVersion; v++ {
+		m, exists := migrations[v]
+		if !exists {
+			return nil, fromVersion, fmt.Errorf("no migration found for upgrading saves from version %v", v)
+		}
+		if err := m.Migrate(data); err != nil {
+			return nil, fromVersion, fmt.Errorf("migration from version %v (%s) failed: %w", v, m.Description, err)
+		}
+		data["Version"] = v + 1
+	}
+
+	// the migrated data is a map, which would be written with its keys sorted alphabetically. Going through SaveFile puts the fields
+	// back in their usual order, so the header is at the top of the file again (see readSaveHeader).
+	migratedMap, err := json.Marshal(data)
+	if err != nil {
+		return nil, fromVersion, fmt.Errorf("failed to encode migrated save data: %w", err)
+	}
+	var sf SaveFile
+	if err := json.Unmarshal(migratedMap, &sf); err != nil {
+		return nil, fromVersion, fmt.Errorf("migrated save data doesn't fit the current save format: %w", err)
+	}
+	migrated, err = encodeSaveFile(sf, false)
+	if err != nil {
+		return nil, fromVersion, fmt.Errorf("failed to encode migrated save data: %w", err)
+	}
+	return migrated, fromVersion, nil
+}
+
+// GetMigrationNotes lists the descriptions of the migrations that would run on a save of the given version.
+func GetMigrationNotes(fromVersion int) []string {
+	notes := []string{}
+	for v := fromVersion; v < SaveFileVersion; v++ {
+		if m, exists := migrations[v]; exists {
+			notes = append(notes, fmt.Sprintf("v%v -> v%v: %s", v, v+1, m.Description))
+		}
+	}
+	return notes
+}
+
+// UpgradeSaveFile migrates a save file on disk to the current version, overwriting it.
+// If backup is set, the original is first copied into a "backups" directory next to the save (which isn't picked up as a save itself).
+// If the save was already on the current version, nothing is written and upgraded is false.
+func UpgradeSaveFile(saveFilePath string, backup bool) (upgraded bool, fromVersion int, err error) {
+	raw, err := os.ReadFile(saveFilePath)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to read save file: %w", err)
+	}
+	saveJSON, compressed, err := DecodeSaveFile(raw)
+	if err != nil {
+		return false, 0, err
+	}
+	migrated, fromVersion, err := MigrateSaveData(saveJSON)
+	if err != nil {
+		return false, fromVersion, err
+	}
+	if fromVersion == SaveFileVersion {
+		return false, fromVersion, nil
+	}
+
+	// the upgraded save is written back in the same format it was in
+	if compressed {
+		var header saveFileHeader
+		if err := json.Unmarshal(migrated, &header); err != nil {
+			return false, fromVersion, fmt.Errorf("failed to read header of migrated save: %w", err)
+		}
+		migrated, err = encodeCompressedSave(header, migrated)
+		if err != nil {
+			return false, fromVersion, err
+		}
+	}
+
+	if backup {
+		backupPath := GetBackupPath(saveFilePath, fromVersion)
+		if err := os.MkdirAll(filepath.Dir(backupPath), os.ModePerm); err != nil {
+			return false, fromVersion, fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		if err := os.WriteFile(backupPath, raw, 0o644); err != nil {
+			return false, fromVersion, fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+
+	if err := files.WriteFileAtomic(saveFilePath, migrated); err != nil {
+		return false, fromVersion, fmt.Errorf("failed to write upgraded save file: %w", err)
+	}
+	return true, fromVersion, nil
+}
+
+// GetBackupPath gets where the backup of a save is kept before it's upgraded from the given version.
+// e.g. saves/player/20250101120000.json -> saves/player/backups/20250101120000.v0.json
+func GetBackupPath(saveFilePath string, fromVersion int) string {
+	dir, name := filepath.Split(saveFilePath)
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	return filepath.Join(dir, "backups", fmt.Sprintf("%s.v%v%s", base, fromVersion, ext))
+}
@@ -0,0 +1,192 @@
+package savegame
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// v0Save is a save from before versioning: no version, slot type or header, and scheduled event data isn't in the payload format.
+const v0Save = `{
+  "SaveTime": "2024-06-01T10:00:00Z",
+  "PlayerCharacterDef": {"ID": "player", "DisplayName": "Tester", "UniquePlayerID": "tester_1234"},
+  "CurrentMapID": "town",
+  "MapCoords": {"X": 3, "Y": 4},
+  "CurrentGameTime": "0001-01-01-08-00",
+  "FutureScheduledEvents": {
+    "0001-01-02-08-00": [{"Type": "old_event", "Data": {"count": 5, "pos": {"X": 1, "Y": 2}}, "RequireSubscriber": false}]
+  }
+}`
+
+func TestMigrateV0Save(t *testing.T) {
+	migrated, fromVersion, err := MigrateSaveData([]byte(v0Save))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromVersion != 0 {
+		t.Errorf("expected the save to be detected as version 0, got %v", fromVersion)
+	}
+
+	var sf SaveFile
+	if err := json.Unmarshal(migrated, &sf); err != nil {
+		t.Fatal("migrated save doesn't load:", err)
+	}
+	if sf.Version != SaveFileVersion {
+		t.Errorf("expected version %v, got %v", SaveFileVersion, sf.Version)
+	}
+	if sf.CurrentMapID != "town" || sf.MapCoords.X != 3 || sf.MapCoords.Y != 4 || sf.PlayerCharacterDef.DisplayName != "Tester" {
+		t.Errorf("migrated save lost some of its data: %+v", sf)
+	}
+
+	// 1 -> 2: scheduled event data still loads, untyped
+	events := sf.FutureScheduledEvents["0001-01-02-08-00"]
+	if len(events) != 1 {
+		t.Fatalf("expected 1 scheduled event, got %v", len(events))
+	}
+	if count, ok := events[0].Data["count"].(float64); !ok || count != 5 {
+		t.Errorf("scheduled event data didn't migrate: %#v", events[0].Data)
+	}
+	if pos, ok := events[0].Data["pos"].(map[string]any); !ok || pos["X"] != float64(1) {
+		t.Errorf("scheduled event data didn't migrate: %#v", events[0].Data)
+	}
+
+	// 2 -> 3: the header is filled in from the rest of the save
+	h := sf.Header
+	if h.CharacterName != "Tester" || h.UniquePlayerID != "tester_1234" || h.MapID != "town" || h.GameTime != "0001-01-01-08-00" {
+		t.Errorf("header wasn't filled in: %+v", h)
+	}
+
+	// the header has to be near the top of the file again, for previews
+	fh, found, err := readSaveHeader(bytes.NewReader(migrated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Fatal("header of the migrated save can't be found by readSaveHeader")
+	}
+	if fh.Version != SaveFileVersion || fh.Header != h {
+		t.Errorf("header read from the top of the file doesn't match: %+v", fh)
+	}
+}
+
+func TestMigrateFromEachVersion(t *testing.T) {
+	want, _, err := MigrateSaveData([]byte(v0Save))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same save as it would have been written by each version, which should end up the same once it's upgraded
+	for from := 1; from < SaveFileVersion; from++ {
+		t.Run(fmt.Sprintf("from v%v", from), func(t *testing.T) {
+			var data SaveData
+			if err := json.Unmarshal([]byte(v0Save), &data); err != nil {
+				t.Fatal(err)
+			}
+			for v := range from {
+				if err := migrations[v].Migrate(data); err != nil {
+					t.Fatal(err)
+				}
+			}
+			data["Version"] = from
+			raw, err := json.Marshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			migrated, fromVersion, err := MigrateSaveData(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fromVersion != from {
+				t.Errorf("expected version %v, got %v", from, fromVersion)
+			}
+			if !bytes.Equal(migrated, want) {
+				t.Errorf("upgrading from v%v gave a different save than upgrading from v0:\nwant %s\ngot  %s", from, want, migrated)
+			}
+		})
+	}
+}
+
+func TestMigrateCurrentAndNewerSaves(t *testing.T) {
+	current, err := encodeSaveFile(testSaveFile(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, fromVersion, err := MigrateSaveData(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromVersion != SaveFileVersion || !bytes.Equal(migrated, current) {
+		t.Error("a save on the current version was changed by migrating it")
+	}
+
+	newer := testSaveFile()
+	newer.Version = SaveFileVersion + 1
+	data, err := encodeSaveFile(newer, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := MigrateSaveData(data); err == nil {
+		t.Error("expected an error for a save from a newer version")
+	}
+}
+
+func TestUpgradeSaveFile(t *testing.T) {
+	dir := t.TempDir()
+	// compressed saves didn't exist yet in v0, but any compressed save from before a future version bump goes through the same path
+	v0Compressed, err := encodeCompressedSave(saveFileHeader{}, []byte(v0Save))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, original := range map[string][]byte{"20240601100000.json": []byte(v0Save), "20240601100000.sav": v0Compressed} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, original, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		upgraded, fromVersion, err := UpgradeSaveFile(path, true)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if !upgraded || fromVersion != 0 {
+			t.Errorf("%s: expected an upgrade from version 0, got upgraded=%v from=%v", name, upgraded, fromVersion)
+		}
+
+		backup, err := os.ReadFile(GetBackupPath(path, 0))
+		if err != nil || !bytes.Equal(backup, original) {
+			t.Errorf("%s: backup doesn't match the original save (err: %v)", name, err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, found, err := readAnySaveHeader(f)
+		f.Close()
+		if err != nil || !found {
+			t.Fatalf("%s: failed to read header of upgraded save (found=%v): %v", name, found, err)
+		}
+		if h.Version != SaveFileVersion || h.Header.CharacterName != "Tester" {
+			t.Errorf("%s: upgraded save has the wrong header: %+v", name, h)
+		}
+
+		// it's written back in the same format it was in
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isCompressedSave(data) != strings.HasSuffix(name, CompressedSaveExt) {
+			t.Errorf("%s: upgraded save changed format", name)
+		}
+
+		// and upgrading again does nothing
+		if upgraded, _, err := UpgradeSaveFile(path, false); err != nil || upgraded {
+			t.Errorf("%s: upgrading an already upgraded save should do nothing (upgraded=%v, err=%v)", name, upgraded, err)
+		}
+	}
+}
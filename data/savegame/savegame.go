@@ -3,10 +3,10 @@ package savegame
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
 	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/webbben/2d-game-engine/clock"
@@ -24,12 +24,20 @@ import (
 )
 
 const (
-	TimestampLayout string = "20060102150405"
+	TimestampLayout string = "20060102150405.000" // milliseconds are included, so that saves made in the same second don't get the same file name
+	// save files from before milliseconds were added to the timestamp
+	oldTimestampLayout string = "20060102150405"
 )
 
 // SaveFile contains all the data that can be saved and loaded for an individual playthrough.
 type SaveFile struct {
+	Version  int // the save file format version (see SaveFileVersion). older saves are migrated when they're loaded.
 	SaveTime time.Time
+	SlotType defs.SaveSlotType // what kind of save this is (manual, autosave, etc). empty for old saves, which were all manual.
+	Label    string            // for autosaves, what triggered it
+	// NOTE: the fields above and the header need to stay at the top of the file, so that previews of plain JSON saves can read them
+	// without loading the whole save (see readSaveHeader). Compressed saves keep a copy of them in a separate section (see saveFileHeader).
+	Header SaveHeader
 
 	// Player data that is only defined in the save file:
 	// we store the player's character def directly in the save file, instead of a JSON file like the other character defs.
@@ -63,15 +71,18 @@ type SaveFile struct {
 	FutureScheduledEvents map[clock.GameTimestamp][]defs.Event
 }
 
-func (sf SaveFile) validate() {
+func (sf SaveFile) check() error {
+	if sf.Version != SaveFileVersion {
+		return fmt.Errorf("save file is the wrong version! current version: %v, save version: %v", SaveFileVersion, sf.Version)
+	}
 	if sf.SaveTime.IsZero() {
-		logz.Panic("save time is zero value")
+		return errors.New("save time is zero value")
 	}
 	if sf.CurrentMapID == "" {
-		logz.Panic("current map ID is empty")
+		return errors.New("current map ID is empty")
 	}
 	if sf.CurrentGameTime == "" {
-		logz.Panic("CurrentGameTime is empty")
+		return errors.New("CurrentGameTime is empty")
 	}
 	if len(sf.CharacterStates) == 0 {
 		logz.Warnln("SaveFile", "no character states... this probably shouldn't happen, right?")
@@ -85,12 +96,25 @@ func (sf SaveFile) validate() {
 	if len(sf.ShopkeeperStates) == 0 {
 		logz.Warnln("SaveFile", "no shopkeeper states... this probably shouldn't happen, right?")
 	}
+	return nil
 }
 
 type QuestStates struct {
 	Active    []state.QuestState
 	Completed []state.QuestState
 	Failed    []state.QuestState
+	Tracked   defs.QuestID // the quest the player is tracking in the HUD, if any
+	// how many instances each quest template has had, and when the last one ended. saves from before this was added don't have it,
+	// but it's also worked out from the instance states that are loaded.
+	Templates []state.QuestTemplateState
+}
+
+// SaveOptions are the details of a save that don't come from the game's data.
+type SaveOptions struct {
+	Slot      defs.SaveSlotType
+	Label     string        // OPT: for autosaves, what triggered it
+	PlayTime  time.Duration // total time played on this character, including this session
+	Thumbnail image.Image   // OPT: a small screenshot of the game, for previews
 }
 
 func SaveGame(
@@ -100,9 +124,20 @@ func SaveGame(
 	gameTime clock.GameTime,
 	mapID defs.MapID,
 	mapCoords model.Coords,
+	opts SaveOptions,
 ) (saveFilePath string) {
+	slot := opts.Slot
+	switch slot {
+	case defs.SaveSlotManual, defs.SaveSlotAuto, defs.SaveSlotQuick:
+	default:
+		logz.Panicln("SAVEGAME", "invalid save slot type:", slot)
+	}
+
 	sf := SaveFile{
+		Version:         SaveFileVersion,
 		SaveTime:        time.Now(),
+		SlotType:        slot,
+		Label:           opts.Label,
 		CurrentGameTime: gameTime.GetTimestamp(),
 		CurrentMapID:    mapID,
 		MapCoords:       mapCoords,
@@ -150,6 +185,8 @@ func SaveGame(
 	sf.Quests.Active = active
 	sf.Quests.Completed = comp
 	sf.Quests.Failed = fail
+	sf.Quests.Tracked = questMgr.GetTrackedQuest()
+	sf.Quests.Templates = questMgr.GetAllTemplateStates()
 
 	// FUTURE EVENT SCHEDULE
 
@@ -158,21 +195,46 @@ func SaveGame(
 		sf.FutureScheduledEvents[k.GetTimestamp()] = v
 	}
 
-	// Done: Prepare to Save
+	// HEADER
 
-	sf.validate()
+	sf.Header = buildSaveHeader(sf, dataman, questMgr, opts.PlayTime)
 
-	timestamp := time.Now().Format(TimestampLayout)
-	filename := fmt.Sprintf("%s.json", timestamp)
+	// Done: Prepare to Save
+
+	if err := sf.check(); err != nil {
+		logz.Panicln("SAVEGAME", "invalid save file:", err)
+	}
 
 	config.EnsurePlayerSaveDirExists(uniqueID)
-	saveFilePath = config.ResolveSaveFilePath(uniqueID, filename)
+	saveFilePath = config.ResolveSaveFilePath(uniqueID, saveFileName(slot, sf.SaveTime))
+	for config.FileExists(saveFilePath) {
+		// a save was already made at this exact time; nudge this one forward so it doesn't replace it, and still sorts as the newer one
+		sf.SaveTime = sf.SaveTime.Add(time.Millisecond)
+		saveFilePath = config.ResolveSaveFilePath(uniqueID, saveFileName(slot, sf.SaveTime))
+	}
 
-	err := files.WriteToJSON(sf, saveFilePath)
+	// a missing thumbnail isn't worth losing the save over, so just warn if it fails
+	if opts.Thumbnail != nil {
+		if err := writeThumbnail(saveFilePath, opts.Thumbnail); err != nil {
+			logz.Warnln("SAVEGAME", "failed to write thumbnail:", err)
+		} else {
+			sf.Header.HasThumbnail = true
+		}
+	}
+
+	data, err := encodeSaveFile(sf, !config.PlainJSONSaves)
+	if err != nil {
+		logz.Panicln("SAVEGAME", "error while encoding save game file:", err.Error())
+	}
+	// written atomically, so that a crash mid-write never leaves a corrupted save behind
+	err = files.WriteFileAtomic(saveFilePath, data)
 	if err != nil {
 		logz.Panicln("SAVEGAME", "error while writing save game file:", err.Error())
 	}
 
+	// only clean up old saves once the new one is safely written
+	rotateSaves(uniqueID, slot)
+
 	logz.Println("GAME SAVED", saveFilePath)
 
 	return saveFilePath
@@ -182,6 +244,7 @@ type LoadedWorldInfo struct {
 	CurrentTime      clock.GameTime
 	CurrentMapID     defs.MapID
 	CurrentMapCoords model.Coords
+	PlayTime         time.Duration
 }
 
 // LoadSave loads the data from a save file. You must pass data and quest managers that already have all of their defs loaded into them.
@@ -206,12 +269,17 @@ func LoadSave(saveFilePath string, dataman *datamanager.DataManager, questMgr *q
 	}
 
 	// load JSON data into SaveFile
-	sf := loadSaveFileStruct(saveFilePath)
+	sf, err := loadSaveFileStruct(saveFilePath)
+	if err != nil {
+		logz.Warnln("LOADGAME", "failed to load save file:", err)
+		return info, err
+	}
 
 	// get world info
 	info.CurrentTime = clock.TimestampToGameTime(sf.CurrentGameTime)
 	info.CurrentMapID = sf.CurrentMapID
 	info.CurrentMapCoords = sf.MapCoords
+	info.PlayTime = sf.Header.PlayTime
 
 	// load data and quest managers
 	// player defs
@@ -244,8 +312,14 @@ func LoadSave(saveFilePath string, dataman *datamanager.DataManager, questMgr *q
 	for _, st := range allQuestStates {
 		questMgr.LoadQuestState(st)
 	}
+	for _, st := range sf.Quests.Templates {
+		questMgr.LoadQuestTemplateState(st)
+	}
 
 	questMgr.CreateEventTypeIndices()
+	if sf.Quests.Tracked != "" {
+		questMgr.SetTrackedQuest(sf.Quests.Tracked)
+	}
 
 	// future events schedule
 	for k, v := range sf.FutureScheduledEvents {
@@ -272,31 +346,31 @@ func GetAllExistingCharacters() []defs.ExistingCharacterInfo {
 	// for each character save directory, get info about the character and the recent save
 	for _, saveDir := range characterSaveDirs {
 		charInfo := defs.ExistingCharacterInfo{}
-		saveFiles := files.GetListOfFiles(saveDir, true)
-		if len(saveFiles) == 0 {
+		saves := listSaves(saveDir)
+		if len(saves) == 0 {
 			logz.Panicln("GetAllExistingCharacters", "a character saves directory was empty, and had no save files.")
 		}
-		// find the most recent save file
-		var mostRecentSavePath string
-		var mostRecentTime time.Time
-		for _, saveFilePath := range saveFiles {
-			charInfo.SaveFilePaths = append(charInfo.SaveFilePaths, saveFilePath)
-			// the time of the save is in the filename; it's a timestamp.
-			// so, parse the filenames into time data, and get the most recent one.
-			name := filepath.Base(saveFilePath)
-			timestamp := strings.Split(name, ".")[0]
-			t, err := time.Parse(TimestampLayout, timestamp)
-			if err != nil {
-				logz.Panicln("GetAllExistingCharacters", "found save file with malformed timestamp filename. error:", err)
+
+		// saves are sorted newest first; if the newest one can't be read (e.g. the game crashed while writing it), fall back to the next one
+		found := false
+		for _, save := range saves {
+			charInfo.SaveFilePaths = append(charInfo.SaveFilePaths, save.path)
+			if found {
+				continue
 			}
-			if t.After(mostRecentTime) || mostRecentSavePath == "" {
-				mostRecentTime = t
-				mostRecentSavePath = saveFilePath
+			si, err := GetSaveInfo(save.path)
+			if err != nil {
+				logz.Warnln("GetAllExistingCharacters", "save file is corrupt:", save.path, err)
+				charInfo.CorruptSaveFilePaths = append(charInfo.CorruptSaveFilePaths, save.path)
+				continue
 			}
+			charInfo.RecentSave = si
+			found = true
+		}
+		if !found {
+			logz.Warnln("GetAllExistingCharacters", "none of the saves in this directory could be read; skipping it:", saveDir)
+			continue
 		}
-
-		// load the data of the most recent save
-		charInfo.RecentSave = GetSaveInfo(mostRecentSavePath)
 		charInfo.DisplayName = charInfo.RecentSave.CharacterName
 		charInfo.UniquePlayerID = charInfo.RecentSave.UniquePlayerID
 
@@ -306,36 +380,93 @@ func GetAllExistingCharacters() []defs.ExistingCharacterInfo {
 	return existingChars
 }
 
-func GetSaveInfo(saveFilePath string) defs.SaveInfo {
+// GetSaveInfo gets the overview of a save file. This only reads the header at the top of the file, so it's fast even for big saves;
+// saves from older versions don't have a header though, so those have to be loaded in full.
+// Returns an error if the save file can't be read.
+func GetSaveInfo(saveFilePath string) (defs.SaveInfo, error) {
 	logz.Println("GetSaveInfo", saveFilePath)
-	si := defs.SaveInfo{}
-	si.SaveFilePath = saveFilePath
 
-	sf := loadSaveFileStruct(saveFilePath)
+	sf, found := readSaveFileHeader(saveFilePath)
+	if !found || sf.Version != SaveFileVersion {
+		full, err := loadSaveFileStruct(saveFilePath)
+		if err != nil {
+			return defs.SaveInfo{}, err
+		}
+		sf = full.fileHeader()
+	}
 
-	si.CharacterName = sf.PlayerCharacterDef.DisplayName
-	si.LastPlay = sf.SaveTime
-	si.UniquePlayerID = sf.PlayerCharacterDef.UniquePlayerID
-	si.CurrentMapID = sf.CurrentMapID
-	si.CurrentGameTime = clock.TimestampToGameTime(sf.CurrentGameTime)
+	si := defs.SaveInfo{
+		SaveFilePath:    saveFilePath,
+		UniquePlayerID:  sf.Header.UniquePlayerID,
+		CharacterName:   sf.Header.CharacterName,
+		LastPlay:        sf.SaveTime,
+		CurrentMapID:    sf.Header.MapID,
+		CurrentGameTime: clock.TimestampToGameTime(sf.Header.GameTime),
+		SlotType:        sf.SlotType,
+		Label:           sf.Label,
+		PlayerLevel:     sf.Header.PlayerLevel,
+		Gold:            sf.Header.Gold,
+		MapDisplayName:  sf.Header.MapDisplayName,
+		RegionID:        sf.Header.RegionID,
+		PlayTime:        sf.Header.PlayTime,
+		ActiveQuestName: sf.Header.ActiveQuestName,
+	}
+	if si.SlotType == "" {
+		si.SlotType = defs.SaveSlotManual
+	}
+	if sf.Header.HasThumbnail {
+		si.Thumbnail = LoadThumbnail(saveFilePath)
+	}
 
-	return si
+	return si, nil
 }
 
-func loadSaveFileStruct(saveFilePath string) SaveFile {
+func readSaveFileHeader(saveFilePath string) (saveFileHeader, bool) {
+	f, err := os.Open(saveFilePath)
+	if err != nil {
+		logz.Warnln("readSaveFileHeader", "failed to open save file:", err)
+		return saveFileHeader{}, false
+	}
+	defer f.Close()
+
+	sf, found, err := readAnySaveHeader(f)
+	if err != nil {
+		logz.Warnln("readSaveFileHeader", "failed to read save header; loading the whole file instead:", err)
+		return sf, false
+	}
+	return sf, found
+}
+
+func loadSaveFileStruct(saveFilePath string) (SaveFile, error) {
 	var sf SaveFile
 
 	fileData, err := os.ReadFile(saveFilePath)
 	if err != nil {
-		logz.Panicln("loadSaveFileStruct", "failed to read save file:", err)
+		return sf, fmt.Errorf("failed to read save file: %w", err)
+	}
+
+	fileData, _, err = DecodeSaveFile(fileData)
+	if err != nil {
+		return sf, fmt.Errorf("failed to decode save file: %w", err)
+	}
+
+	// saves from older versions of the game are upgraded in memory; the file itself is only changed by the save_upgrade tool.
+	fileData, fromVersion, err := MigrateSaveData(fileData)
+	if err != nil {
+		return sf, fmt.Errorf("failed to migrate save file: %w", err)
+	}
+	if fromVersion != SaveFileVersion {
+		logz.Println("loadSaveFileStruct", "migrated save file from version", fromVersion, "to", SaveFileVersion)
 	}
 
 	err = json.Unmarshal(fileData, &sf)
 	if err != nil {
-		logz.Panicln("loadSaveFileStruct", "failed to unmarshal save file data:", err)
+		return sf, fmt.Errorf("failed to unmarshal save file data: %w", err)
 	}
 
-	sf.validate()
+	if err := sf.check(); err != nil {
+		return sf, fmt.Errorf("save file is invalid: %w", err)
+	}
 
-	return sf
+	return sf, nil
 }
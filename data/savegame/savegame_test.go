@@ -0,0 +1,69 @@
+package savegame
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+func TestLoadCorruptSave(t *testing.T) {
+	data, err := encodeSaveFile(testSaveFile(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "20250101120000.000.sav")
+	if err := os.WriteFile(path, data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSaveFileStruct(path); err == nil {
+		t.Error("expected an error for a truncated save")
+	}
+}
+
+func TestGetAllExistingCharactersSkipsCorruptSaves(t *testing.T) {
+	prevOverride := config.GameDataPathOverride
+	config.GameDataPathOverride = t.TempDir()
+	defer func() { config.GameDataPathOverride = prevOverride }()
+
+	saveDir := filepath.Join(config.GameDataPathOverride, "saves", "tester_1234")
+	if err := os.MkdirAll(saveDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	older := testSaveFile()
+	older.Header.UniquePlayerID = "tester_1234"
+	older.Header.GameTime = older.CurrentGameTime
+	olderData, err := encodeSaveFile(older, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	olderPath := filepath.Join(saveDir, saveFileName(defs.SaveSlotManual, older.SaveTime))
+	if err := os.WriteFile(olderPath, olderData, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the newest save was cut off before its header was even written
+	newerPath := filepath.Join(saveDir, saveFileName(defs.SaveSlotQuick, older.SaveTime.Add(time.Hour)))
+	if err := os.WriteFile(newerPath, olderData[:8], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	chars := GetAllExistingCharacters()
+	if len(chars) != 1 {
+		t.Fatalf("expected 1 character, got %v", len(chars))
+	}
+	char := chars[0]
+	if char.RecentSave.SaveFilePath != olderPath || char.UniquePlayerID != "tester_1234" {
+		t.Errorf("expected the older save to be used, got %+v", char.RecentSave)
+	}
+	if len(char.CorruptSaveFilePaths) != 1 || char.CorruptSaveFilePaths[0] != newerPath {
+		t.Errorf("expected the newest save to be marked corrupt, got %v", char.CorruptSaveFilePaths)
+	}
+	if len(char.SaveFilePaths) != 2 {
+		t.Errorf("expected both saves to be listed, got %v", char.SaveFilePaths)
+	}
+}
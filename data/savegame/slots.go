@@ -0,0 +1,122 @@
+package savegame
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/logz"
+	"github.com/webbben/2d-game-engine/utils/files"
+)
+
+// Save file names have the time of the save in them, so we can sort them without opening every file.
+// The time goes down to milliseconds, so that two saves made in quick succession don't collide during rotation.
+// Manual saves are just "<timestamp>.sav" (as they always have been, apart from the extension), and other slot types get a prefix:
+// "auto_<timestamp>.sav". Saves written in the plain JSON format use ".json" instead (see format.go).
+
+func saveFileName(slot defs.SaveSlotType, t time.Time) string {
+	timestamp := t.Format(TimestampLayout)
+	if slot == defs.SaveSlotManual {
+		return timestamp + saveFileExt()
+	}
+	return fmt.Sprintf("%s_%s%s", slot, timestamp, saveFileExt())
+}
+
+// ParseSaveFileName gets the slot type and save time from the name of a save file.
+// ok is false if the file isn't a save file (e.g. a temp file from an interrupted write).
+func ParseSaveFileName(name string) (slot defs.SaveSlotType, t time.Time, ok bool) {
+	if !HasSaveFileExt(name) {
+		return "", time.Time{}, false
+	}
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	slot = defs.SaveSlotManual
+	if prefix, timestamp, found := strings.Cut(base, "_"); found {
+		slot = defs.SaveSlotType(prefix)
+		base = timestamp
+	}
+	switch slot {
+	case defs.SaveSlotManual, defs.SaveSlotAuto, defs.SaveSlotQuick:
+	default:
+		return "", time.Time{}, false
+	}
+	t, err := time.Parse(TimestampLayout, base)
+	if err != nil {
+		t, err = time.Parse(oldTimestampLayout, base)
+		if err != nil {
+			return "", time.Time{}, false
+		}
+	}
+	return slot, t, true
+}
+
+type saveFileEntry struct {
+	path string
+	slot defs.SaveSlotType
+	time time.Time
+}
+
+// listSaves gets all the save files in a character's save directory, newest first.
+func listSaves(saveDir string) []saveFileEntry {
+	saves := []saveFileEntry{}
+	for _, path := range files.GetListOfFiles(saveDir, true) {
+		if filepath.Ext(path) == ".png" {
+			continue // thumbnail
+		}
+		slot, t, ok := ParseSaveFileName(filepath.Base(path))
+		if !ok {
+			logz.Warnln("listSaves", "skipping file in save directory that isn't a save file:", path)
+			continue
+		}
+		saves = append(saves, saveFileEntry{path: path, slot: slot, time: t})
+	}
+	slices.SortFunc(saves, func(a, b saveFileEntry) int {
+		return b.time.Compare(a.time)
+	})
+	return saves
+}
+
+// GetLatestSave gets the most recent save of a character in the given slot type. If slot is empty, any slot type counts.
+func GetLatestSave(uniquePlayerID defs.UniquePlayerID, slot defs.SaveSlotType) (saveFilePath string, found bool) {
+	saveDir := config.ResolvePlayerSaveDir(uniquePlayerID)
+	if !config.FileExists(saveDir) {
+		return "", false
+	}
+	for _, save := range listSaves(saveDir) {
+		if slot == "" || save.slot == slot {
+			return save.path, true
+		}
+	}
+	return "", false
+}
+
+// rotateSaves deletes the oldest saves of a slot type, so that only as many as config.SaveRetention allows are kept.
+func rotateSaves(uniquePlayerID defs.UniquePlayerID, slot defs.SaveSlotType) {
+	limit := config.SaveRetention[slot]
+	if limit <= 0 {
+		return
+	}
+	saveDir := config.ResolvePlayerSaveDir(uniquePlayerID)
+	kept := 0
+	for _, save := range listSaves(saveDir) {
+		if save.slot != slot {
+			continue
+		}
+		kept++
+		if kept <= limit {
+			continue
+		}
+		logz.Println("rotateSaves", "removing old save:", save.path)
+		if err := os.Remove(save.path); err != nil {
+			logz.Warnln("rotateSaves", "failed to remove old save:", err)
+			continue
+		}
+		if err := os.Remove(ThumbnailPath(save.path)); err != nil && !os.IsNotExist(err) {
+			logz.Warnln("rotateSaves", "failed to remove old save's thumbnail:", err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package savegame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+func TestSaveFileNameRoundTrip(t *testing.T) {
+	saveTime := time.Date(2025, 1, 1, 12, 0, 0, 123_000_000, time.UTC)
+	for _, slot := range []defs.SaveSlotType{defs.SaveSlotManual, defs.SaveSlotAuto, defs.SaveSlotQuick} {
+		name := saveFileName(slot, saveTime)
+		gotSlot, gotTime, ok := ParseSaveFileName(name)
+		if !ok {
+			t.Fatalf("%s: failed to parse save file name %q", slot, name)
+		}
+		if gotSlot != slot || !gotTime.Equal(saveTime) {
+			t.Errorf("%s: got slot %q and time %v from %q", slot, gotSlot, gotTime, name)
+		}
+	}
+}
+
+func TestSaveFileNamesInSameSecond(t *testing.T) {
+	first := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Millisecond)
+	if saveFileName(defs.SaveSlotQuick, first) == saveFileName(defs.SaveSlotQuick, second) {
+		t.Fatal("saves made in the same second got the same file name")
+	}
+	_, t1, _ := ParseSaveFileName(saveFileName(defs.SaveSlotQuick, first))
+	_, t2, _ := ParseSaveFileName(saveFileName(defs.SaveSlotQuick, second))
+	if !t2.After(t1) {
+		t.Error("the later save doesn't sort as newer")
+	}
+}
+
+func TestParseSaveFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		slot defs.SaveSlotType
+		ok   bool
+	}{
+		{"20250101120000.json", defs.SaveSlotManual, true},
+		{"quick_20250101120000.sav", defs.SaveSlotQuick, true},
+		{"auto_20250101120000.123.sav", defs.SaveSlotAuto, true},
+		{"bogus_20250101120000.sav", "", false},
+		{"20250101120000.sav.tmp", "", false},
+		{"notatime.sav", "", false},
+	}
+	for _, tt := range tests {
+		slot, _, ok := ParseSaveFileName(tt.name)
+		if ok != tt.ok || slot != tt.slot {
+			t.Errorf("%s: got slot %q, ok=%v; want slot %q, ok=%v", tt.name, slot, ok, tt.slot, tt.ok)
+		}
+	}
+}
@@ -49,6 +49,7 @@ type CharacterState struct {
 
 	BaseAttributes map[defs.AttributeID]int // Base attribute levels (not including modifiers from traits, etc)
 	BaseSkills     map[defs.SkillID]int     // Base skill levels (not including modifiers from traits, etc)
+	SkillXP        map[defs.SkillID]int     // progress towards the next base level of each skill. see characterstate.AddSkillXP.
 	Traits         []defs.TraitID
 
 	Health     int
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"github.com/webbben/2d-game-engine/clock"
 	"github.com/webbben/2d-game-engine/data/defs"
 )
 
@@ -20,4 +21,32 @@ type DialogProfileState struct {
 	// Note: avoid using directly. better to use the specific functions for setting and reading memory map.
 	// This is profile specific - global knowledge of things like world lore topics should be stored in the Player instead.
 	Memory map[string]bool
+
+	// values recorded during dialog, like text the player entered or an item they chose (see the dialog action types).
+	// Note: like Memory, better to use the dialog context functions for reading and setting these.
+	MemoryValues map[string]string
+
+	// a log of what was said in past conversations with this profile, oldest first.
+	// The length is capped (see config.DialogTranscriptMaxEntries), so old entries eventually fall off.
+	Transcript []DialogTranscriptEntry
+}
+
+// DialogTranscriptEntry is a single line of a conversation; either something an NPC said, or something the player said.
+type DialogTranscriptEntry struct {
+	Time     clock.GameTime
+	Speaker  string // display name of whoever said this
+	Text     string
+	IsPlayer bool
+}
+
+// AddTranscriptEntry adds an entry to the transcript, dropping the oldest entries if it goes over maxEntries.
+// If maxEntries is 0 or less, nothing is recorded.
+func (dps *DialogProfileState) AddTranscriptEntry(entry DialogTranscriptEntry, maxEntries int) {
+	if maxEntries <= 0 {
+		return
+	}
+	dps.Transcript = append(dps.Transcript, entry)
+	if over := len(dps.Transcript) - maxEntries; over > 0 {
+		dps.Transcript = append([]DialogTranscriptEntry{}, dps.Transcript[over:]...)
+	}
 }
@@ -1,10 +1,63 @@
 package state
 
-import "github.com/webbben/2d-game-engine/data/defs"
+import (
+	"fmt"
+
+	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/data/defs"
+)
 
 // QuestState defines the current state of a quest.
 type QuestState struct {
 	DefID        defs.QuestID
 	CurrentStage defs.QuestStageID
 	Status       defs.QuestStatus
+
+	// only set for instances of quest templates. used to recreate the instance's quest def when loading a save.
+	TemplateID   defs.QuestID
+	Instance     int
+	Placeholders map[string]string
+
+	// progress of the current stage's objectives. reset whenever the quest changes stages.
+	Objectives map[defs.QuestObjectiveID]QuestObjectiveProgress
+
+	// when the current stage's deadline expires, if it has one. reset whenever the quest changes stages.
+	Deadline *clock.GameTime
+
+	// every stage the quest has gone through, plus its completion or failure, in order.
+	// used for showing the full story of a quest in the journal (and for debugging broken quest chains from save files).
+	History []QuestHistoryEntry
+}
+
+// QuestTemplateState tracks the instances of a quest template. Only the last few finished instances are kept
+// (see defs.QuestTemplateDef.KeepFinished), so this is what remembers how many there have been, and when the last one ended.
+type QuestTemplateState struct {
+	TemplateID    defs.QuestID
+	InstanceCount int             // how many instances have been started so far; each new instance is numbered after this
+	LastEnded     *clock.GameTime // when the most recent instance was completed or failed. nil if none have ended yet.
+}
+
+// QuestHistoryEntry is a single entry in a quest's history.
+// Stage title and description are copied in at the time of the entry, so the journal can show them without needing the quest def.
+type QuestHistoryEntry struct {
+	Time        clock.GameTime
+	StageID     defs.QuestStageID
+	Title       string
+	Description string
+	Status      defs.QuestStatus // ACTIVE for stage transitions; COMPLETED or FAILED when the quest ended
+}
+
+// QuestObjectiveProgress tracks how far along the player is on a single quest objective.
+type QuestObjectiveProgress struct {
+	Count    int
+	Required int
+	Counted  []string // values already counted, for objectives that only count distinct values
+}
+
+func (p QuestObjectiveProgress) Complete() bool {
+	return p.Count >= p.Required
+}
+
+func (p QuestObjectiveProgress) String() string {
+	return fmt.Sprintf("%v/%v", min(p.Count, p.Required), p.Required)
 }
@@ -0,0 +1,307 @@
+package dialogv2
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	"github.com/webbben/2d-game-engine/data/state"
+	"github.com/webbben/2d-game-engine/display"
+	characterstate "github.com/webbben/2d-game-engine/entity/characterState"
+	"github.com/webbben/2d-game-engine/imgutil/rendering"
+	"github.com/webbben/2d-game-engine/locale"
+	"github.com/webbben/2d-game-engine/logz"
+	"github.com/webbben/2d-game-engine/ui/box"
+	"github.com/webbben/2d-game-engine/ui/button"
+	"github.com/webbben/2d-game-engine/ui/modal"
+	"github.com/webbben/2d-game-engine/ui/text"
+)
+
+// The results of these actions are stored in dialog memory values, so later responses can check them with ConditionMemoryValue,
+// or show them with the "memory_value" dialog variable.
+
+// TextInputActionParams prompts the player to enter some text, like naming a pet or answering a riddle.
+type TextInputActionParams struct {
+	ResultKey     string // REQ: memory key that the entered text is stored under
+	Title         string // OPT: prompt shown above the text field
+	NumericOnly   bool
+	MaxCharLength int // OPT: defaults to 20
+}
+
+// SelectItemActionParams has the player pick an item from their inventory. If neither filter is set, any item can be chosen.
+type SelectItemActionParams struct {
+	ResultKey string          // REQ: memory key that the chosen item's ID is stored under. If the player cancels (or has no matching items), the key is cleared.
+	Title     string          // OPT: text shown above the item list
+	ItemTypes []defs.ItemType // OPT: only items of these types can be chosen
+	ItemIDs   []defs.ItemID   // OPT: only these items can be chosen
+}
+
+// GiveItemActionParams hands an item from the player's inventory over to the NPC who is speaking.
+// If the player doesn't have enough of the item, or the NPC has no room for it, nothing is given.
+type GiveItemActionParams struct {
+	ItemID      defs.ItemID // the item to give. Either this or ItemFromKey must be set.
+	ItemFromKey string      // memory key of an item chosen earlier, e.g. by a select item action
+	Quantity    int         // OPT: defaults to 1
+	ResultKey   string      // OPT: if set, the given item's ID is stored under this key (or it's cleared if nothing was given)
+}
+
+func (ds *DialogSession) startTextInput(params TextInputActionParams) {
+	if params.ResultKey == "" {
+		panic("text input action: result key was empty")
+	}
+	m := modal.NewTextInputModal(modal.TextInputModalParams{
+		BoxTilesetSrc:  ds.boxTilesetSrc,
+		BoxOriginIndex: ds.boxOriginID,
+		TitleText:      params.Title,
+		NumericOnly:    params.NumericOnly,
+		MaxCharLength:  params.MaxCharLength,
+	}, ds.audioman)
+	ds.textInputModal = &m
+}
+
+func (ds *DialogSession) updateTextInput() {
+	if ds.textInputModal == nil {
+		panic("text input modal was nil during text input action")
+	}
+	resp := ds.textInputModal.Update()
+	if !resp.Done {
+		return
+	}
+	params := ds.currentResponse.Action.Params.(TextInputActionParams)
+	value := resp.InputText
+	if params.NumericOnly {
+		value = strconv.Itoa(resp.InputNumber)
+	}
+	ds.Ctx.SetMemoryValue(params.ResultKey, value)
+	ds.textInputModal = nil
+	ds.continueApplyResponse()
+}
+
+func (ds *DialogSession) startSelectItem(params SelectItemActionParams) {
+	if params.ResultKey == "" {
+		panic("select item action: result key was empty")
+	}
+	playerState := ds.dataman.GetCharacterState(id.CharacterStateID(defs.PlayerID))
+
+	// group the player's matching items by item ID
+	itemIDs := []defs.ItemID{}
+	counts := make(map[defs.ItemID]int)
+	for _, itemState := range playerState.StandardInventory.InventoryItems {
+		if itemState == nil {
+			continue
+		}
+		if len(params.ItemIDs) > 0 && !slices.Contains(params.ItemIDs, itemState.DefID) {
+			continue
+		}
+		if len(params.ItemTypes) > 0 && !slices.Contains(params.ItemTypes, ds.dataman.GetItemDef(itemState.DefID).Type) {
+			continue
+		}
+		if _, exists := counts[itemState.DefID]; !exists {
+			itemIDs = append(itemIDs, itemState.DefID)
+		}
+		counts[itemState.DefID] += itemState.Quantity
+	}
+
+	if len(itemIDs) == 0 {
+		logz.Println("DialogSession", "select item action: player has no matching items")
+		ds.Ctx.SetMemoryValue(params.ResultKey, "")
+		ds.continueApplyResponse()
+		return
+	}
+
+	menu := newItemSelectMenu(ds, params.Title, itemIDs, counts)
+	ds.itemSelect = &menu
+}
+
+func (ds *DialogSession) updateSelectItem() {
+	if ds.itemSelect == nil {
+		panic("item select menu was nil during select item action")
+	}
+	done, itemID := ds.itemSelect.update()
+	if !done {
+		return
+	}
+	params := ds.currentResponse.Action.Params.(SelectItemActionParams)
+	ds.Ctx.SetMemoryValue(params.ResultKey, string(itemID))
+	ds.itemSelect = nil
+	ds.continueApplyResponse()
+}
+
+func (ds *DialogSession) giveItem(params GiveItemActionParams) {
+	itemID := params.ItemID
+	if params.ItemFromKey != "" {
+		val, _ := ds.Ctx.GetMemoryValue(params.ItemFromKey)
+		itemID = defs.ItemID(val)
+	}
+	quantity := max(params.Quantity, 1)
+
+	given := ds.Ctx.GiveItemToSpeaker(itemID, quantity)
+	if params.ResultKey != "" {
+		if given {
+			ds.Ctx.SetMemoryValue(params.ResultKey, string(itemID))
+		} else {
+			ds.Ctx.SetMemoryValue(params.ResultKey, "")
+		}
+	}
+}
+
+// GiveItemToSpeaker moves an item from the player's inventory to the inventory of the NPC who is speaking.
+// Returns false (and gives nothing) if the player doesn't have enough of the item, or the NPC has no room for it.
+func (ctx DialogContext) GiveItemToSpeaker(itemID defs.ItemID, quantity int) bool {
+	if itemID == "" {
+		logz.Println("DialogContext", "give item: no item to give")
+		return false
+	}
+	playerState := ctx.dataman.GetCharacterState(id.CharacterStateID(defs.PlayerID))
+
+	// find the item first, so we keep its durability, and make sure there's enough of it before removing anything
+	var toGive *state.ItemState
+	count := 0
+	for _, itemState := range playerState.StandardInventory.InventoryItems {
+		if itemState != nil && itemState.DefID == itemID {
+			if toGive == nil {
+				toGive = itemState
+			}
+			count += itemState.Quantity
+		}
+	}
+	if toGive == nil || count < quantity {
+		logz.Println("DialogContext", "give item: player doesn't have enough of the item:", itemID, "have:", count, "need:", quantity)
+		return false
+	}
+	given := *toGive
+	given.Quantity = quantity
+
+	// give it to the NPC first, so that if their inventory is full, the player just keeps the item
+	npcState := ctx.dataman.GetCharacterState(ctx.GetNPCCharStateID())
+	success, _ := characterstate.AddItemToInventory(npcState, given, ctx.dataman)
+	if !success {
+		logz.Println("DialogContext", "give item: NPC inventory is full:", itemID)
+		return false
+	}
+	success, _ = characterstate.RemoveItemFromInventory(playerState, given, ctx.dataman)
+	if !success {
+		logz.Panicln("DialogContext", "failed to remove item from player's inventory, even though there was enough of it:", itemID)
+	}
+	return true
+}
+
+// itemSelectMenu shows a list of items for the player to choose from (for the select item action).
+// If there are more items than fit on the screen, they're split into pages.
+type itemSelectMenu struct {
+	title       string
+	boxImg      *ebiten.Image
+	itemIDs     []defs.ItemID
+	buttons     []*button.Button
+	cancelBtn   *button.Button
+	prevBtn     *button.Button // only set if there's more than one page
+	nextBtn     *button.Button
+	rowsPerPage int
+	page        int
+}
+
+func newItemSelectMenu(ds *DialogSession, title string, itemIDs []defs.ItemID, counts map[defs.ItemID]int) itemSelectMenu {
+	if title == "" {
+		title = locale.Text(KeyChooseItem, "Choose an item")
+	}
+	tileSize := int(config.GetScaledTilesize())
+	menu := itemSelectMenu{
+		title:   title,
+		itemIDs: itemIDs,
+	}
+
+	width := tileSize * 10
+	for _, itemID := range itemIDs {
+		label := ds.dataman.GetItemDef(itemID).Name
+		if counts[itemID] > 1 {
+			label = fmt.Sprintf("%s (%v)", label, counts[itemID])
+		}
+		btn := button.NewButton(label, config.DefaultFont, width-(tileSize*2), tileSize, ds.audioman)
+		menu.buttons = append(menu.buttons, btn)
+	}
+	menu.cancelBtn = button.NewButton(locale.Text(KeyCancel, "Cancel"), config.DefaultFont, 0, 0, ds.audioman)
+
+	// only show as many rows as fit in most of the screen; the rest go on other pages
+	titleDy, _ := text.GetRealisticFontMetrics(config.DefaultTitleFont)
+	fixedHeight := tileSize + titleDy + tileSize/2 + tileSize*2
+	maxRows := max((display.SCREEN_HEIGHT*3/4-fixedHeight)/tileSize, 1)
+	menu.rowsPerPage = min(len(menu.buttons), maxRows)
+	if len(menu.buttons) > menu.rowsPerPage {
+		menu.prevBtn = button.NewButton("<", config.DefaultFont, tileSize, 0, ds.audioman)
+		menu.nextBtn = button.NewButton(">", config.DefaultFont, tileSize, 0, ds.audioman)
+	}
+
+	height := fixedHeight + (menu.rowsPerPage * tileSize)
+	b := box.NewBox(ds.boxTilesetSrc, ds.boxOriginID)
+	menu.boxImg = b.BuildBoxImage(width, ((height/tileSize)+1)*tileSize, config.UIScale)
+
+	return menu
+}
+
+func (m itemSelectMenu) pageCount() int {
+	return (len(m.buttons) + m.rowsPerPage - 1) / m.rowsPerPage
+}
+
+// pageButtons gets the item buttons on the current page, and the index of the first one.
+func (m itemSelectMenu) pageButtons() ([]*button.Button, int) {
+	start := m.page * m.rowsPerPage
+	end := min(start+m.rowsPerPage, len(m.buttons))
+	return m.buttons[start:end], start
+}
+
+// update returns done = true once the player has chosen an item, or cancelled (in which case itemID is empty).
+func (m *itemSelectMenu) update() (done bool, itemID defs.ItemID) {
+	buttons, start := m.pageButtons()
+	for i, btn := range buttons {
+		if btn.Update().Clicked {
+			return true, m.itemIDs[start+i]
+		}
+	}
+	if m.prevBtn != nil {
+		if m.prevBtn.Update().Clicked && m.page > 0 {
+			m.page--
+		}
+		if m.nextBtn.Update().Clicked && m.page < m.pageCount()-1 {
+			m.page++
+		}
+	}
+	if m.cancelBtn.Update().Clicked {
+		return true, ""
+	}
+	return false, ""
+}
+
+func (m *itemSelectMenu) draw(screen *ebiten.Image) {
+	tileSize := int(config.GetScaledTilesize())
+	dx := m.boxImg.Bounds().Dx()
+	dy := m.boxImg.Bounds().Dy()
+	x := (display.SCREEN_WIDTH - dx) / 2
+	y := (display.SCREEN_HEIGHT - dy) / 2
+	rendering.DrawImage(screen, m.boxImg, float64(x), float64(y), 0)
+
+	titleDy, _ := text.GetRealisticFontMetrics(config.DefaultTitleFont)
+	drawY := y + tileSize/2 + titleDy
+	text.DrawShadowText(screen, m.title, config.DefaultTitleFont, x+tileSize, drawY, nil, nil, 0, 0)
+	drawY += tileSize / 2
+
+	buttons, _ := m.pageButtons()
+	for _, btn := range buttons {
+		btn.Draw(screen, x+tileSize, drawY)
+		drawY += btn.Height
+	}
+
+	bottomY := y + dy - tileSize - (tileSize / 2)
+	if m.prevBtn != nil {
+		m.prevBtn.Draw(screen, x+tileSize, bottomY)
+		pageString := fmt.Sprintf("%v/%v", m.page+1, m.pageCount())
+		pageDx, _, _ := text.GetStringSize(pageString, config.DefaultFont)
+		pageX := x + tileSize + m.prevBtn.Width + tileSize/4
+		text.DrawShadowText(screen, pageString, config.DefaultFont, pageX, bottomY+m.prevBtn.Height*3/4, nil, nil, 0, 0)
+		m.nextBtn.Draw(screen, pageX+pageDx+tileSize/4, bottomY)
+	}
+	m.cancelBtn.Draw(screen, x+dx-tileSize-m.cancelBtn.Width, bottomY)
+}
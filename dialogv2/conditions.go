@@ -2,6 +2,7 @@ package dialogv2
 
 import (
 	"math/rand"
+	"strings"
 
 	"github.com/webbben/2d-game-engine/data/defs"
 	"github.com/webbben/2d-game-engine/data/id"
@@ -184,6 +185,38 @@ func (c ConditionKnowledge) IsMet(ctx defs.ConditionContext) bool {
 	return ctx.PlayerHasKnowledge(c.TopicID)
 }
 
+// ConditionMemoryValue checks a value recorded in dialog memory, such as the result of a text input or select item action.
+type ConditionMemoryValue struct {
+	Key        string
+	Value      string // if empty, just checks that the key has a value set.
+	IgnoreCase bool   // if set, the value is compared case insensitively (and ignoring surrounding spaces), e.g. for riddle answers
+}
+
+func (c ConditionMemoryValue) IsMet(ctx defs.ConditionContext) bool {
+	val, exists := ctx.GetMemoryValue(c.Key)
+	if !exists {
+		return false
+	}
+	if c.Value == "" {
+		return true
+	}
+	if c.IgnoreCase {
+		return strings.EqualFold(strings.TrimSpace(val), strings.TrimSpace(c.Value))
+	}
+	return val == c.Value
+}
+
+// ConditionSkillCheck checks the outcome of a dialog skill check (see defs.DialogSkillCheck).
+// Checks that haven't been attempted yet are neither passed nor failed.
+type ConditionSkillCheck struct {
+	CheckID string
+	Passed  bool // if set, checks that the skill check was passed. otherwise, checks that it was failed.
+}
+
+func (c ConditionSkillCheck) IsMet(ctx defs.ConditionContext) bool {
+	return ctx.HasMemory(defs.SkillCheckMemoryKey(c.CheckID, c.Passed))
+}
+
 type ConditionSkillLevel struct {
 	SkillID defs.SkillID
 	Level   int
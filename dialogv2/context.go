@@ -2,10 +2,12 @@ package dialogv2
 
 import (
 	"fmt"
+	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/config"
 	"github.com/webbben/2d-game-engine/data/datamanager"
 	"github.com/webbben/2d-game-engine/data/defs"
 	"github.com/webbben/2d-game-engine/data/id"
@@ -34,6 +36,11 @@ type DialogContext struct {
 	opinion    int
 	culture    defs.CultureDef
 
+	// for group conversations: the character who is currently speaking, if it isn't the NPC the player is talking to.
+	// NPC specific context functions (like GetNPCCharStateID or GetOpinionOfPlayer) use the speaker instead.
+	speakerID      id.CharacterStateID
+	speakerOpinion int
+
 	seenTopics      map[defs.TopicID]bool // topics the player has already discussed before (with this NPC)
 	knowledgeTopics map[defs.TopicID]bool // topics which both the player and NPC have knowledge of
 	seenResponses   map[string]bool
@@ -95,6 +102,19 @@ func NewDialogContext(npcID string, profile *state.DialogProfileState, profDef d
 	return ds
 }
 
+var topicLinkRegexp = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// RecordTranscript adds a line to this dialog profile's transcript.
+// Topic link brackets are removed, since the transcript is just for reading.
+func (ctx *DialogContext) RecordTranscript(speaker, text string, isPlayer bool) {
+	ctx.Profile.AddTranscriptEntry(state.DialogTranscriptEntry{
+		Time:     ctx.GetCurrentGameTime(),
+		Speaker:  speaker,
+		Text:     topicLinkRegexp.ReplaceAllString(text, "$1"),
+		IsPlayer: isPlayer,
+	}, config.DialogTranscriptMaxEntries)
+}
+
 func (ctx *DialogContext) RecordTopicSeen(topicID defs.TopicID) {
 	if topicID == "" {
 		panic("topicID was empty")
@@ -184,15 +204,61 @@ func (ctx DialogContext) HasMemory(key string) bool {
 	return ctx.Profile.Memory[key]
 }
 
+// SetMemoryValue records a value in dialog memory, such as the result of a dialog action. An empty value clears the key.
+func (ctx DialogContext) SetMemoryValue(key, value string) {
+	if key == "" {
+		panic("memory value key was empty")
+	}
+	if value == "" {
+		delete(ctx.Profile.MemoryValues, key)
+		return
+	}
+	if ctx.Profile.MemoryValues == nil {
+		ctx.Profile.MemoryValues = make(map[string]string)
+	}
+	ctx.Profile.MemoryValues[key] = value
+}
+
+func (ctx DialogContext) GetMemoryValue(key string) (string, bool) {
+	val, exists := ctx.Profile.MemoryValues[key]
+	return val, exists
+}
+
 func (ctx DialogContext) GetCharacterSocialRank(id id.CharacterStateID) defs.SocialRank {
 	charState := ctx.dataman.GetCharacterState(id)
 	return charState.SocialRank
 }
 
+// GetNPCCharStateID gets the NPC who is currently speaking. This is usually the NPC the player is talking to,
+// but in group conversations it can be another character (see defs.DialogResponse.Speaker).
 func (ctx DialogContext) GetNPCCharStateID() id.CharacterStateID {
+	if ctx.speakerID != "" {
+		return ctx.speakerID
+	}
 	return id.CharacterStateID(ctx.NPCID)
 }
 
+// withSpeaker gets a copy of this context, where the given character is the one speaking.
+// If speaker is empty, the NPC the player is talking to is the speaker.
+func (ctx DialogContext) withSpeaker(speaker id.CharacterStateID) DialogContext {
+	if speaker == "" || speaker == id.CharacterStateID(ctx.NPCID) {
+		ctx.speakerID = ""
+		ctx.speakerOpinion = 0
+		return ctx
+	}
+	if speaker == ctx.speakerID {
+		return ctx
+	}
+	speakerState := ctx.dataman.GetCharacterState(speaker)
+	playerState := ctx.dataman.GetCharacterState(id.CharacterStateID(defs.PlayerID))
+	if speakerState.CurrentMap != ctx.GetActiveMapDef().ID {
+		logz.Warnln("DialogContext", "dialog speaker is not in the current map:", speaker)
+	}
+	ctx.speakerID = speaker
+	_, ctx.speakerOpinion = characterstate.CalculateOpinion(speakerState, playerState, ctx.GetCurrentGameTime(), ctx.dataman)
+	return ctx
+}
+
 func (ctx DialogContext) CharacterHasRole(id id.CharacterStateID, roleID defs.RoleID) bool {
 	charState := ctx.dataman.GetCharacterState(id)
 	return charState.Roles[roleID]
@@ -242,6 +308,15 @@ func (ctx DialogContext) RemoveRole(roleID defs.RoleID) {
 	ctx.GameState.RemoveRole(roleID)
 }
 
+func (ctx DialogContext) AddSkillXP(skillID defs.SkillID, xp int) {
+	ctx.GameState.AddSkillXP(skillID, xp)
+}
+
+// AddKnowledge goes through RecordTopicUnlocked, so that the new topic also shows up in the current dialog session.
+func (ctx *DialogContext) AddKnowledge(topicID defs.TopicID) {
+	ctx.RecordTopicUnlocked(topicID)
+}
+
 func (ctx DialogContext) AssignTaskToNPC(id defs.CharacterDefID, taskDef defs.TaskDef, requireListener bool) {
 	ctx.GameState.AssignTaskToNPC(id, taskDef, requireListener)
 }
@@ -291,20 +366,32 @@ func (ctx DialogContext) SetMapLock(mapID defs.MapID, lockID string, lockLevel i
 }
 
 func (ctx DialogContext) GetNPCClassDef() defs.ClassDef {
-	charState := ctx.dataman.GetCharacterState(id.CharacterStateID(ctx.NPCID))
+	charState := ctx.dataman.GetCharacterState(ctx.GetNPCCharStateID())
 	characterDef := ctx.dataman.GetCharacterDef(charState.DefID)
 	classDef := ctx.dataman.GetClassDef(characterDef.ClassDefID)
 	return classDef
 }
 
 func (ctx DialogContext) GetOpinionOfPlayer() int {
+	if ctx.speakerID != "" {
+		return ctx.speakerOpinion
+	}
 	return ctx.opinion
 }
 
+func (ctx DialogContext) GetSkillDef(skillID defs.SkillID) defs.SkillDef {
+	return ctx.dataman.GetSkillDef(skillID)
+}
+
 func (ctx DialogContext) AddOpinionModifier(holder, subject id.CharacterStateID, mod defs.OpinionModifier) {
 	characterstate.AddOpinionModifier(holder, subject, mod, ctx.dataman)
 }
 
+// GetDialogNPC gets the NPC who is currently speaking (see GetNPCCharStateID).
 func (ctx DialogContext) GetDialogNPC() id.CharacterStateID {
-	return id.CharacterStateID(ctx.NPCID)
+	return ctx.GetNPCCharStateID()
+}
+
+func (ctx DialogContext) PlayEmote(charID id.CharacterStateID, emote defs.DialogEmote) {
+	ctx.GameState.PlayEmote(charID, emote)
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/webbben/2d-game-engine/data/state"
 	"github.com/webbben/2d-game-engine/display"
 	characterstate "github.com/webbben/2d-game-engine/entity/characterState"
+	"github.com/webbben/2d-game-engine/locale"
 	"github.com/webbben/2d-game-engine/logz"
 	"github.com/webbben/2d-game-engine/model"
 	"github.com/webbben/2d-game-engine/pubsub"
@@ -22,7 +23,9 @@ import (
 	"github.com/webbben/2d-game-engine/screen"
 	"github.com/webbben/2d-game-engine/ui/box"
 	"github.com/webbben/2d-game-engine/ui/button"
+	"github.com/webbben/2d-game-engine/ui/modal"
 	"github.com/webbben/2d-game-engine/ui/text"
+	"github.com/webbben/2d-game-engine/ui/transcript"
 	"github.com/webbben/2d-game-engine/utils"
 	"golang.org/x/image/font"
 )
@@ -41,8 +44,19 @@ const (
 	Goodbye string = "Goodbye"
 )
 
+// localization keys for the dialog UI
+const (
+	KeyGoodbye         locale.Key = "ui.dialog.goodbye"
+	KeyTranscriptTitle locale.Key = "ui.dialog.transcript_title"
+	KeyChooseItem      locale.Key = "ui.dialog.choose_item"
+	KeyCancel          locale.Key = "ui.dialog.cancel"
+)
+
 const (
 	ActionTypeShowScreen defs.DialogActionType = "show_screen"
+	ActionTypeTextInput  defs.DialogActionType = "text_input"  // params: TextInputActionParams
+	ActionTypeSelectItem defs.DialogActionType = "select_item" // params: SelectItemActionParams
+	ActionTypeGiveItem   defs.DialogActionType = "give_item"   // params: GiveItemActionParams
 )
 
 const (
@@ -50,10 +64,6 @@ const (
 	VarPlayerCulture string = "{player_culture}"
 )
 
-var AllDialogVariables = []string{
-	VarPlayerName, VarPlayerCulture,
-}
-
 type ShowScreenActionParams struct {
 	ScreenID     defs.ScreenID
 	ScreenParams any
@@ -73,6 +83,11 @@ type DialogSession struct {
 	showCharInfo bool
 
 	nameTitle             box.BoxTitle
+	speakerTitles         map[id.CharacterStateID]box.BoxTitle // name titles for each speaker in a group conversation
+	currentSpeaker        id.CharacterStateID
+	speakerName           string
+	portrait              speakerPortrait                       // portrait of the current speaker (if portraits are enabled)
+	portraitAvatars       map[id.CharacterStateID]*ebiten.Image // avatars rendered for speakers that use them as portraits
 	boxSrc                box.Box
 	TextBoxImg            *ebiten.Image
 	TopicBoxImg           *ebiten.Image
@@ -111,8 +126,15 @@ type DialogSession struct {
 	currentResponse *defs.DialogResponse // the "response" indicates what node we are in the convesation progression, which started from the topic.
 	responseStatus  dialogResponseStatus
 
+	transcriptView *transcript.TranscriptView // if set, the transcript of past conversations is open
+	boxTilesetSrc  string
+	boxOriginID    int
+
 	// possible action modals
 
+	textInputModal *modal.TextInputModal
+	itemSelect     *itemSelectMenu
+
 	screenViewer *screen.ScreenViewer
 	ctxForScreen defs.GameContext
 }
@@ -230,11 +252,16 @@ func (ds *DialogSession) dialogSetup(params DialogSessionParams) {
 
 	b := box.NewBox(params.BoxTilesetSrc, params.BoxOriginID)
 	ds.boxSrc = b
+	ds.boxTilesetSrc = params.BoxTilesetSrc
+	ds.boxOriginID = params.BoxOriginID
 
 	npcState := ds.dataman.GetCharacterState(id.CharacterStateID(params.NPCID))
 	npcName := npcState.DisplayName
 
 	ds.nameTitle = box.NewBoxTitle(params.BoxTilesetSrc, 111, npcName, config.DefaultTitleFont)
+	ds.currentSpeaker = npcState.ID
+	ds.speakerName = npcName
+	ds.speakerTitles = map[id.CharacterStateID]box.BoxTitle{npcState.ID: ds.nameTitle}
 
 	ds.TextBoxImg = b.BuildBoxImage(textBoxWidth, textBoxHeight, config.UIScale)
 	ds.buildTopicBox(textBoxHeight)
@@ -257,10 +284,11 @@ func (ds *DialogSession) dialogSetup(params DialogSessionParams) {
 		FontFace:              params.TextFont,
 		UseShadow:             true,
 		TextBlipSfx:           config.DefaultTextBlipSfx,
-		TextBlipTickInterval:  5,
+		TextBlipTickInterval:  defs.DefaultVoiceTickInterval,
 		SupportSpecialSymbols: true,
 	}
 	ds.LineWriter = text.NewLineWriter(ds.audioman, lwParams)
+	ds.portraitAvatars = make(map[id.CharacterStateID]*ebiten.Image)
 }
 
 func (ds *DialogSession) refreshOpinion() {
@@ -353,12 +381,13 @@ func (ds *DialogSession) setupTopicOptions() {
 	w := ds.TopicBoxImg.Bounds().Dx() - int(tileSize)
 
 	for _, topic := range options {
-		dx, _, _ := text.GetStringSize(topic.Prompt, ds.f)
+		prompt := topicPrompt(topic)
+		dx, _, _ := text.GetStringSize(prompt, ds.f)
 		if dx > w {
 			// TODO: probably at some point we can just make this a warn instead of panic. just want this here for now to catch oversized topic prompts early on.
 			logz.Panicln("setupTopicOptions", "topic prompt was too long for the topic box. prompt width:", dx, "boxWidth:", w)
 		}
-		ds.topicButtons = append(ds.topicButtons, button.NewButton(topic.Prompt, ds.f, w, h, ds.audioman))
+		ds.topicButtons = append(ds.topicButtons, button.NewButton(prompt, ds.f, w, h, ds.audioman))
 		ds.topicList = append(ds.topicList, topic.ID)
 	}
 
@@ -418,11 +447,12 @@ func (ds *DialogSession) setupReplyOptions() {
 		// setup the 'Goodbye' reply
 		ds.replyButtons = make([]*button.Button, 0)
 		ds.replyList = make([]defs.DialogReply, 0)
+		goodbye := locale.Text(KeyGoodbye, Goodbye)
 		ds.replyList = append(ds.replyList, defs.DialogReply{
-			Text:    Goodbye,
+			Text:    goodbye,
 			Goodbye: true,
 		})
-		ds.replyButtons = append(ds.replyButtons, button.NewButton(Goodbye, ds.f, maxReplyWidth, h, ds.audioman))
+		ds.replyButtons = append(ds.replyButtons, button.NewButton(goodbye, ds.f, maxReplyWidth, h, ds.audioman))
 		return
 	}
 
@@ -446,14 +476,16 @@ func (ds *DialogSession) setupReplyOptions() {
 
 	// first, find out if all replies can fit in the topic box, and get the maximum reply width.
 	replies := []defs.DialogReply{}
+	ctx := ds.npcCtx()
 	for _, reply := range ds.currentResponse.Replies {
-		if ConditionsMet(reply.Conditions, ds.Ctx) {
+		if ConditionsMet(reply.Conditions, ctx) {
+			reply.Text = locale.Resolve(reply.Text)
 			replies = append(replies, reply)
 			dx, _, _ := text.GetStringSize(reply.Text, ds.f)
 			if dx > maxReplyWidth {
 				maxReplyWidth = dx
 			}
-			if reply.InfoText != nil || reply.Decoration != "" {
+			if reply.InfoText != nil || reply.SkillCheck != nil || reply.Decoration != "" {
 				hasFancyReplies = true
 			}
 		}
@@ -488,9 +520,18 @@ func (ds *DialogSession) setupReplyOptions() {
 	}
 }
 
+// topicPrompt gets the prompt of a topic in the active locale.
+func topicPrompt(topic defs.DialogTopic) string {
+	if topic.ID == quitTopic.ID {
+		return locale.Text(KeyGoodbye, Goodbye)
+	}
+	return locale.Text(datamanager.TopicPromptKey(topic.ID), locale.Resolve(topic.Prompt))
+}
+
 func (ds *DialogSession) GetTopicOptions() []defs.DialogTopic {
 	seenTopics := make(map[defs.TopicID]bool) // ensure no duplicates
 	topicOptions := []defs.DialogTopic{}
+	ctx := ds.npcCtx()
 
 	// first, get them from the profile
 	for _, topicID := range ds.ProfileDef.TopicsIDs {
@@ -500,13 +541,13 @@ func (ds *DialogSession) GetTopicOptions() []defs.DialogTopic {
 		seenTopics[topicID] = true
 
 		topic := ds.dataman.GetDialogTopic(topicID)
-		if ConditionsMet(topic.Conditions, ds.Ctx) {
+		if ConditionsMet(topic.Conditions, ctx) {
 			topicOptions = append(topicOptions, *topic)
 		}
 	}
 
 	// next, get knowledge topics that both player and NPC have access to
-	for _, topicID := range ds.Ctx.GetKnowledgeTopics() {
+	for _, topicID := range ctx.GetKnowledgeTopics() {
 		if seenTopics[topicID] {
 			continue
 		}
@@ -516,7 +557,7 @@ func (ds *DialogSession) GetTopicOptions() []defs.DialogTopic {
 		// TODO: I wonder if we should find a way to avoid checking conditions everytime.
 		// one idea is to cache the result, and only recalculate whenever any effect happens, since that's probably the only
 		// time conditions could be affected.
-		if ConditionsMet(topic.Conditions, ds.Ctx) {
+		if ConditionsMet(topic.Conditions, ctx) {
 			topicOptions = append(topicOptions, *topic)
 		}
 	}
@@ -537,9 +578,18 @@ func (ds *DialogSession) ApplyResponse(dr defs.DialogResponse) {
 	ds.topicList = []defs.TopicID{}
 	ds.topicLinks = []topicLink{}
 
+	// text can be a reference to a localized string, so resolve that before looking for links
+	dr.Text = locale.Resolve(dr.Text)
+
 	// find any topic links that might exist in the text
 	ds.topicLinks = parseTextLinks(dr)
 
+	ds.setSpeaker(dr.Speaker)
+	ds.setSpeakerLook(dr.Expression)
+	if dr.Emote != nil {
+		ds.Ctx.PlayEmote(ds.currentSpeaker, *dr.Emote)
+	}
+
 	// if the response has an ID, mark it as seen
 	if dr.ID != "" {
 		ds.Ctx.RecordResponseSeen(dr.ID)
@@ -556,6 +606,32 @@ func (ds *DialogSession) ApplyResponse(dr defs.DialogResponse) {
 	ds.continueApplyResponse()
 }
 
+// setSpeaker switches who is speaking (for group conversations); the name title shows the speaker's name, and the dialog context
+// evaluates things against the speaker. An empty speaker means the NPC the player is talking to.
+func (ds *DialogSession) setSpeaker(speaker id.CharacterStateID) {
+	ds.Ctx = ds.Ctx.withSpeaker(speaker)
+	speakerID := ds.Ctx.GetNPCCharStateID()
+	if speakerID == ds.currentSpeaker {
+		return
+	}
+	ds.currentSpeaker = speakerID
+	ds.speakerName = ds.dataman.GetCharacterState(speakerID).DisplayName
+
+	// box titles are sized to fit their title, so we build one for each speaker
+	if title, exists := ds.speakerTitles[speakerID]; exists {
+		ds.nameTitle = title
+		return
+	}
+	ds.nameTitle = box.NewBoxTitle(ds.boxTilesetSrc, 111, ds.speakerName, config.DefaultTitleFont)
+	ds.speakerTitles[speakerID] = ds.nameTitle
+}
+
+// npcCtx gets the dialog context of the NPC the player is talking to, even if someone else spoke last.
+// Topics and replies belong to that NPC's profile, so their conditions are checked against this rather than ds.Ctx.
+func (ds *DialogSession) npcCtx() DialogContext {
+	return ds.Ctx.withSpeaker("")
+}
+
 // topicLink is used to denote a link that is found in a dialog response text.
 type topicLink struct {
 	text    string       // text of the actual link
@@ -624,6 +700,26 @@ func (ds *DialogSession) startAction() {
 		s := ds.scrMgr.GetScreen(params.ScreenID)
 		sv := screen.NewScreenViewer(s, ds.dataman, ds.eventBus, ds.audioman, ds.Ctx.questman, ds.ctxForScreen, params.ScreenParams)
 		ds.screenViewer = &sv
+	case ActionTypeTextInput:
+		params, ok := action.Params.(TextInputActionParams)
+		if !ok {
+			panic("unable to resolve params as TextInputActionParams... was the wrong params type chosen?")
+		}
+		ds.startTextInput(params)
+	case ActionTypeSelectItem:
+		params, ok := action.Params.(SelectItemActionParams)
+		if !ok {
+			panic("unable to resolve params as SelectItemActionParams... was the wrong params type chosen?")
+		}
+		ds.startSelectItem(params)
+	case ActionTypeGiveItem:
+		params, ok := action.Params.(GiveItemActionParams)
+		if !ok {
+			panic("unable to resolve params as GiveItemActionParams... was the wrong params type chosen?")
+		}
+		// no UI for this one, so the response can continue right away
+		ds.giveItem(params)
+		ds.continueApplyResponse()
 	default:
 		logz.Panicln("startAction", "action type not recognized:", action.Type)
 	}
@@ -696,11 +792,36 @@ func (ds *DialogSession) setResponseText(s string) {
 	ds.LineWriter.Clear()
 
 	// fill in any dialog variables
-	s = InsertDialogVariables(s, ds.Ctx.GameState.GetPlayerInfo(), ds.dataman)
+	s = InsertDialogVariables(s, DialogTextContext{
+		PlayerInfo: ds.Ctx.GameState.GetPlayerInfo(),
+		Dataman:    ds.dataman,
+		World:      ds.Ctx.GameState,
+		Dialog:     &ds.Ctx,
+	})
+	ds.Ctx.RecordTranscript(ds.speakerName, s, false)
 
 	ds.LineWriter.SetSourceText(s)
 }
 
+func (ds *DialogSession) recordPlayerLine(s string) {
+	ds.Ctx.RecordTranscript(ds.Ctx.GameState.GetPlayerInfo().PlayerName, s, true)
+}
+
+// toggleTranscript opens (or closes) the transcript of past conversations with this dialog profile.
+func (ds *DialogSession) toggleTranscript() {
+	if ds.transcriptView != nil {
+		ds.transcriptView = nil
+		return
+	}
+	tv := transcript.NewTranscriptView(locale.Text(KeyTranscriptTitle, "Past conversations"), ds.Ctx.Profile.Transcript, transcript.TranscriptViewParams{
+		Width:          display.SCREEN_WIDTH * 2 / 3,
+		Height:         display.SCREEN_HEIGHT * 2 / 3,
+		BoxTilesetSrc:  ds.boxTilesetSrc,
+		BoxOriginIndex: ds.boxOriginID,
+	})
+	ds.transcriptView = &tv
+}
+
 func (ds *DialogSession) ApplyReply(dr defs.DialogReply) {
 	if ds.responseStatus != dialogResponseUserReply {
 		panic("applying reply while status is incorrect")
@@ -709,6 +830,8 @@ func (ds *DialogSession) ApplyReply(dr defs.DialogReply) {
 		panic("applying reply while linewriter is still writing")
 	}
 
+	ds.recordPlayerLine(dr.Text)
+
 	// the player has chosen a reply, so we no longer need the reply buttons.
 	ds.replyButtons = []*button.Button{}
 	ds.replyList = []defs.DialogReply{}
@@ -727,6 +850,15 @@ func (ds *DialogSession) ApplyReply(dr defs.DialogReply) {
 		return
 	}
 
+	if dr.SkillCheck != nil {
+		if ds.Ctx.RollSkillCheck(*dr.SkillCheck) {
+			ds.ApplyResponse(*dr.SkillCheck.Success)
+		} else {
+			ds.ApplyResponse(*dr.SkillCheck.Failure)
+		}
+		return
+	}
+
 	if dr.NextResponse == nil {
 		if len(dr.NextResponseOptions) > 0 {
 			resp := chooseResponse(dr.NextResponseOptions, ds.Ctx)
@@ -757,6 +889,7 @@ func (ds *DialogSession) SetTopic(topicID defs.TopicID) {
 	}
 	topic := ds.dataman.GetDialogTopic(topicID)
 	ds.currentTopic = topic
+	ds.recordPlayerLine(topicPrompt(*topic))
 
 	ds.Ctx.RecordTopicSeen(topicID)
 
@@ -794,7 +927,8 @@ func chooseResponse(responses []defs.DialogResponse, ctx DialogContext) defs.Dia
 		if response.ID != "" && response.Once && ctx.HasSeenResponse(response.ID) {
 			continue
 		}
-		if ConditionsMet(response.Conditions, ctx) {
+		// conditions are evaluated against whoever would say this response
+		if ConditionsMet(response.Conditions, ctx.withSpeaker(response.Speaker)) {
 			return response
 		}
 	}
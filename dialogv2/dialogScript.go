@@ -0,0 +1,80 @@
+package dialogv2
+
+import (
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/dialogscript"
+)
+
+// registers the dialog conditions and effects defined in this package, so they can be used in dialog scripts.
+func init() {
+	dialogscript.RegisterCondition("memory", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionDialogMemory{Key: p.String("key")}
+	})
+	dialogscript.RegisterCondition("culture", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionCulture{CharDefID: defs.CharacterDefID(p.String("char")), IsCulture: defs.CultureID(p.String("culture"))}
+	})
+	dialogscript.RegisterCondition("has_gold", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionHasGold{Amount: p.Int("amount")}
+	})
+	dialogscript.RegisterCondition("has_item", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionHasItem{ItemID: defs.ItemID(p.String("item"))}
+	})
+	dialogscript.RegisterCondition("dialog_profile", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionDialogProfile{ProfileID: defs.DialogProfileID(p.String("profile"))}
+	})
+	dialogscript.RegisterCondition("rand", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionRand{Percent: float32(p.Float("percent"))}
+	})
+	dialogscript.RegisterCondition("map", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionMapID{MapID: defs.MapID(p.String("map"))}
+	})
+	dialogscript.RegisterCondition("region", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionRegion{RegionID: defs.RegionID(p.String("region"))}
+	})
+	dialogscript.RegisterCondition("social_rank", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionSocialRank{Player: p.Bool("player"), Rank: defs.SocialRank(p.Int("rank")), GEQ: p.Bool("geq"), LEQ: p.Bool("leq")}
+	})
+	dialogscript.RegisterCondition("has_role", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionHasRole{Player: p.Bool("player"), RoleID: defs.RoleID(p.String("role"))}
+	})
+	dialogscript.RegisterCondition("quest_stage", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionQuestStage{
+			QuestID:    defs.QuestID(p.String("quest")),
+			StageID:    defs.QuestStageID(p.OptString("stage", "")),
+			NotStarted: p.Bool("not_started"),
+			Completed:  p.Bool("completed"),
+			Failed:     p.Bool("failed"),
+		}
+	})
+	dialogscript.RegisterCondition("item_equipped", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionItemEquipped{ItemID: defs.ItemID(p.String("item"))}
+	})
+	dialogscript.RegisterCondition("knowledge", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionKnowledge{TopicID: defs.TopicID(p.String("topic"))}
+	})
+	dialogscript.RegisterCondition("skill_level", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionSkillLevel{SkillID: defs.SkillID(p.String("skill")), Level: p.Int("level"), GEQ: p.Bool("geq"), LEQ: p.Bool("leq")}
+	})
+	dialogscript.RegisterCondition("memory_value", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionMemoryValue{Key: p.String("key"), Value: p.OptString("value", ""), IgnoreCase: p.Bool("ignore_case")}
+	})
+	dialogscript.RegisterCondition("skill_check", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionSkillCheck{CheckID: p.String("check"), Passed: p.Bool("passed")}
+	})
+	dialogscript.RegisterCondition("attribute_level", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionAttributeLevel{AttrID: defs.AttributeID(p.String("attr")), Level: p.Int("level"), GEQ: p.Bool("geq"), LEQ: p.Bool("leq")}
+	})
+	dialogscript.RegisterCondition("class", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionClass{ClassDefID: defs.ClassDefID(p.String("class"))}
+	})
+	dialogscript.RegisterCondition("opinion", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionOpinion{Value: p.Int("value"), GEQ: p.Bool("geq"), LEQ: p.Bool("leq")}
+	})
+	dialogscript.RegisterCondition("seen_topic", func(p *dialogscript.Params) defs.DialogCondition {
+		return ConditionSeenTopic{TopicID: defs.TopicID(p.String("topic"))}
+	})
+
+	dialogscript.RegisterDialogEffect("set_memory", func(p *dialogscript.Params) defs.DialogEffect {
+		return SetDialogMemoryEffect{MemoryKey: p.String("key")}
+	})
+}
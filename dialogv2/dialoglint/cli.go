@@ -0,0 +1,140 @@
+package dialoglint
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+)
+
+// Main runs the dialog_lint command (see cmd/dialog_lint) on the dialog profiles and topics in a data manager, and returns the exit code.
+// args are the command line arguments, without the program name.
+//
+// Games that define dialog in Go can make their own lint command by loading their dialog into a data manager first:
+//
+//	func main() {
+//		dataman := datamanager.NewDataManager()
+//		mygame.LoadDialog(dataman)
+//		os.Exit(dialoglint.Main(dataman, os.Args[1:]))
+//	}
+//
+// If a -dir is given, the dialog scripts in it are loaded on top of what's already in the data manager.
+func Main(dataman *datamanager.DataManager, args []string) int {
+	lintCmd := flag.NewFlagSet("lint", flag.ContinueOnError)
+	lintDir := lintCmd.String("dir", "", "directory of dialog scripts to check")
+	noWarnings := lintCmd.Bool("errors-only", false, "only report errors, not warnings")
+
+	walkCmd := flag.NewFlagSet("walk", flag.ContinueOnError)
+	walkDir := walkCmd.String("dir", "", "directory of dialog scripts to load")
+	profileID := walkCmd.String("profile", "", "dialog profile to walk through")
+	npcID := walkCmd.String("npc", "", "character state ID of the NPC being talked to")
+	gold := walkCmd.Int("gold", 0, "player gold")
+	opinion := walkCmd.Int("opinion", 0, "NPC's opinion of the player")
+	memory := walkCmd.String("memory", "", "comma separated dialog memory keys that are already set")
+	knowledge := walkCmd.String("know", "", "comma separated topics the player already knows")
+	items := walkCmd.String("items", "", "comma separated items the player has")
+
+	if len(args) < 1 {
+		fmt.Println("Error: no command provided (lint or walk)")
+		return 1
+	}
+
+	switch args[0] {
+	case "lint":
+		if err := lintCmd.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if !loadDialog(dataman, *lintDir) {
+			lintCmd.Usage()
+			return 1
+		}
+		issues := Lint(dataman)
+		count := 0
+		for _, issue := range issues {
+			if *noWarnings && issue.Severity != SeverityError {
+				continue
+			}
+			fmt.Println(issue)
+			count++
+		}
+		fmt.Printf("checked %d profiles and %d topics; found %d issues\n", len(dataman.DialogProfiles), len(dataman.DialogTopics), count)
+		if HasErrors(issues) {
+			return 1
+		}
+	case "walk":
+		if err := walkCmd.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if *profileID == "" {
+			fmt.Println("Error: profile argument is required for walk command")
+			walkCmd.Usage()
+			return 1
+		}
+		if !loadDialog(dataman, *walkDir) {
+			walkCmd.Usage()
+			return 1
+		}
+
+		ctx := NewMockContext(dataman, defs.DialogProfileID(*profileID))
+		ctx.NPCID = id.CharacterStateID(*npcID)
+		ctx.Gold = *gold
+		ctx.Opinion = *opinion
+		for _, key := range splitList(*memory) {
+			ctx.Memory[key] = true
+		}
+		for _, topicID := range splitList(*knowledge) {
+			ctx.Knowledge[defs.TopicID(topicID)] = true
+		}
+		for _, itemID := range splitList(*items) {
+			ctx.Items[defs.ItemID(itemID)] = true
+		}
+
+		w, err := NewWalkthrough(dataman, defs.DialogProfileID(*profileID), ctx, os.Stdin, os.Stdout)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return 1
+		}
+		if err := w.Run(); err != nil {
+			fmt.Println("Error:", err)
+			return 1
+		}
+	default:
+		fmt.Println("command not recognized.")
+		lintCmd.Usage()
+		walkCmd.Usage()
+		return 1
+	}
+	return 0
+}
+
+// loadDialog loads the dialog scripts in dir (if given) into the data manager. Returns false if there's nothing to check,
+// or the scripts didn't compile.
+func loadDialog(dataman *datamanager.DataManager, dir string) bool {
+	if dir != "" {
+		if err := dataman.LoadDialogScripts(dir); err != nil {
+			fmt.Println(err)
+			fmt.Println("Error: failed to compile dialog scripts")
+			return false
+		}
+	}
+	if len(dataman.DialogProfiles) == 0 && len(dataman.DialogTopics) == 0 {
+		fmt.Println("Error: no dialog is loaded; give a directory of dialog scripts with -dir")
+		return false
+	}
+	return true
+}
+
+func splitList(s string) []string {
+	list := []string{}
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
@@ -0,0 +1,224 @@
+// Package dialoglint checks dialog profiles and topics for mistakes that would otherwise only show up during a playtest,
+// and has a text-mode walkthrough for trying out dialog without running the game.
+package dialoglint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // will cause a panic or broken dialog in game
+	SeverityWarning Severity = "warning" // probably a mistake, but might be intended
+)
+
+// Issue is a problem found in a dialog profile or topic.
+type Issue struct {
+	Severity Severity
+	Where    string // path to the problem, e.g. `topic rumors > response 2 > reply "Where?"`
+	Msg      string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Where, i.Msg)
+}
+
+var linkRegex = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// Lint checks all the dialog profiles and topics in the data manager. Issues are sorted by where they were found.
+//
+// Things that are checked:
+//   - topics (TopicsIDs, KnowledgeTopics, NextTopics) that don't exist
+//   - responses that can never be shown, because an earlier response in the same list has no conditions
+//   - response lists where every response has conditions (if none are met, the game panics)
+//   - Once responses without IDs
+//   - "[links]" in response text that don't have a matching NextTopic, and unbalanced brackets
+//   - replies that all have conditions
+func Lint(dataman *datamanager.DataManager) []Issue {
+	l := linter{dataman: dataman}
+
+	for _, profileID := range sortedKeys(dataman.DialogProfiles) {
+		profile := dataman.DialogProfiles[profileID]
+		where := fmt.Sprintf("profile %s", profileID)
+		for _, topicID := range profile.TopicsIDs {
+			l.checkTopicExists(where+" > topics", topicID)
+		}
+		for _, topicID := range profile.KnowledgeTopics {
+			l.checkTopicExists(where+" > knowledge topics", topicID)
+		}
+		if len(profile.Greeting) == 0 {
+			l.errorf(where, "profile has no greeting")
+		}
+		l.checkResponseList(where+" > greeting", profile.Greeting)
+	}
+
+	for _, topicID := range sortedKeys(dataman.DialogTopics) {
+		topic := dataman.DialogTopics[topicID]
+		where := fmt.Sprintf("topic %s", topicID)
+		if topic.Prompt == "" {
+			l.errorf(where, "topic has no prompt")
+		}
+		if len(topic.Responses) == 0 {
+			l.errorf(where, "topic has no responses")
+		}
+		l.checkResponseList(where, topic.Responses)
+	}
+
+	slices.SortStableFunc(l.issues, func(a, b Issue) int {
+		return strings.Compare(a.Where, b.Where)
+	})
+	return l.issues
+}
+
+// HasErrors tells if any of the issues are errors (rather than just warnings).
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type linter struct {
+	dataman *datamanager.DataManager
+	issues  []Issue
+}
+
+func (l *linter) errorf(where, format string, args ...any) {
+	l.issues = append(l.issues, Issue{Severity: SeverityError, Where: where, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) warnf(where, format string, args ...any) {
+	l.issues = append(l.issues, Issue{Severity: SeverityWarning, Where: where, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) checkTopicExists(where string, topicID defs.TopicID) {
+	if _, exists := l.dataman.DialogTopics[topicID]; !exists {
+		l.errorf(where, "topic %q doesn't exist", topicID)
+	}
+}
+
+// checkResponseList checks a list of responses that the game chooses one from (the first one whose conditions are met).
+func (l *linter) checkResponseList(where string, responses []defs.DialogResponse) {
+	alwaysChosen := -1
+	for i, resp := range responses {
+		respWhere := fmt.Sprintf("%s > response %d", where, i+1)
+		if resp.ID != "" {
+			respWhere = fmt.Sprintf("%s > response %d (%s)", where, i+1, resp.ID)
+		}
+		if alwaysChosen >= 0 {
+			l.errorf(respWhere, "response can never be shown; response %d has no conditions, so it's always chosen first", alwaysChosen+1)
+		} else if len(resp.Conditions) == 0 && !resp.Once {
+			alwaysChosen = i
+		}
+		l.checkResponse(respWhere, resp)
+	}
+	if len(responses) > 0 && alwaysChosen < 0 {
+		l.warnf(where, "every response has conditions (or is only shown once); if none can be chosen, the game will panic. consider adding a fallback response with no conditions at the end")
+	}
+}
+
+func (l *linter) checkResponse(where string, resp defs.DialogResponse) {
+	if resp.Once && resp.ID == "" {
+		l.errorf(where, "response is marked Once, but has no ID")
+	}
+	if resp.Text == "" && len(resp.Conditions) == 0 && resp.NextResponse == nil && len(resp.NextResponseOptions) == 0 {
+		l.errorf(where, "response has no text, and doesn't lead to any other responses")
+	}
+	if resp.NextResponse != nil && len(resp.NextResponseOptions) > 0 {
+		l.errorf(where, "response has both NextResponse and NextResponseOptions; only one can be used")
+	}
+	if resp.Goodbye && len(resp.Replies) > 0 {
+		l.errorf(where, "goodbye response has replies; goodbye responses get a single 'Goodbye' reply automatically")
+	}
+
+	for _, topicID := range resp.NextTopics {
+		l.checkTopicExists(where+" > next topics", topicID)
+	}
+	l.checkLinks(where, resp)
+
+	if err := defs.ValidateDialogText(resp.Text); err != nil {
+		l.errorf(where, "%s", err)
+	}
+
+	if resp.NextResponse != nil {
+		l.checkResponse(where+" > next", *resp.NextResponse)
+	}
+	if len(resp.NextResponseOptions) > 0 {
+		l.checkResponseList(where+" > next options", resp.NextResponseOptions)
+	}
+
+	allConditional := len(resp.Replies) > 0
+	for _, reply := range resp.Replies {
+		if len(reply.Conditions) == 0 {
+			allConditional = false
+		}
+		l.checkReply(fmt.Sprintf("%s > reply %q", where, reply.Text), reply)
+	}
+	if allConditional {
+		l.warnf(where, "every reply has conditions; if none are met, the game will panic")
+	}
+}
+
+// checkLinks makes sure each "[link]" in the text has a NextTopic to go with it. The links are matched with NextTopics in order.
+func (l *linter) checkLinks(where string, resp defs.DialogResponse) {
+	if strings.Count(resp.Text, "[") != strings.Count(resp.Text, "]") {
+		l.errorf(where, "text has unbalanced square brackets: %q", resp.Text)
+		return
+	}
+	links := linkRegex.FindAllStringSubmatch(resp.Text, -1)
+	if len(links) > len(resp.NextTopics) {
+		missing := []string{}
+		for _, link := range links[len(resp.NextTopics):] {
+			missing = append(missing, link[1])
+		}
+		l.errorf(where, "text has %d links, but only %d next topics; no topic for: %s", len(links), len(resp.NextTopics), strings.Join(missing, ", "))
+	}
+}
+
+func (l *linter) checkReply(where string, reply defs.DialogReply) {
+	if reply.Text == "" {
+		l.errorf(where, "reply has no text")
+	}
+	if reply.NextResponse != nil && len(reply.NextResponseOptions) > 0 {
+		l.errorf(where, "reply has both NextResponse and NextResponseOptions; only one can be used")
+	}
+	if reply.SkillCheck != nil {
+		check := reply.SkillCheck
+		if reply.NextResponse != nil || len(reply.NextResponseOptions) > 0 {
+			l.errorf(where, "reply has a skill check, but also has next responses set")
+		}
+		if check.Success == nil || check.Failure == nil {
+			l.errorf(where, "skill check %q needs both a success and failure response", check.ID)
+		}
+		if check.Success != nil {
+			l.checkResponse(where+" > success", *check.Success)
+		}
+		if check.Failure != nil {
+			l.checkResponse(where+" > failure", *check.Failure)
+		}
+	}
+	if reply.NextResponse != nil {
+		l.checkResponse(where+" > next", *reply.NextResponse)
+	}
+	if len(reply.NextResponseOptions) > 0 {
+		l.checkResponseList(where+" > next options", reply.NextResponseOptions)
+	}
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := []K{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
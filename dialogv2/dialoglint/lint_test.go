@@ -0,0 +1,193 @@
+package dialoglint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+type testCondition struct{}
+
+func (testCondition) IsMet(ctx defs.ConditionContext) bool { return true }
+
+var cond = []defs.DialogCondition{testCondition{}}
+
+// newTestDataman makes a data manager with a valid profile and topic, which the tests add broken dialog to.
+func newTestDataman() *datamanager.DataManager {
+	dataman := datamanager.NewDataManager()
+	dataman.DialogTopics["rumors"] = &defs.DialogTopic{
+		ID:     "rumors",
+		Prompt: "Rumors",
+		Responses: []defs.DialogResponse{
+			{Text: "They say a [lost sword] lies in the mine.", NextTopics: []defs.TopicID{"lost_sword"}},
+		},
+	}
+	dataman.DialogTopics["lost_sword"] = &defs.DialogTopic{
+		ID:        "lost_sword",
+		Prompt:    "Lost sword",
+		Responses: []defs.DialogResponse{{Text: "It's north of here.", Replies: []defs.DialogReply{{Text: "Thanks."}}}},
+	}
+	dataman.DialogProfiles["guard"] = &defs.DialogProfileDef{
+		ProfileID: "guard",
+		TopicsIDs: []defs.TopicID{"rumors"},
+		Greeting:  []defs.DialogResponse{{Text: "Halt."}},
+	}
+	return dataman
+}
+
+func TestLintValidDialog(t *testing.T) {
+	if issues := Lint(newTestDataman()); len(issues) != 0 {
+		t.Errorf("expected no issues, got: %v", issues)
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		topic    *defs.DialogTopic      // added to the test data manager, if set
+		profile  *defs.DialogProfileDef // added to the test data manager, if set
+		severity Severity
+		where    string
+		msg      string
+	}{
+		{
+			name:     "missing profile topic",
+			profile:  &defs.DialogProfileDef{ProfileID: "p", TopicsIDs: []defs.TopicID{"nope"}, Greeting: []defs.DialogResponse{{Text: "Hi."}}},
+			severity: SeverityError,
+			where:    "profile p > topics",
+			msg:      `topic "nope" doesn't exist`,
+		},
+		{
+			name:     "missing knowledge topic",
+			profile:  &defs.DialogProfileDef{ProfileID: "p", KnowledgeTopics: []defs.TopicID{"nope"}, Greeting: []defs.DialogResponse{{Text: "Hi."}}},
+			severity: SeverityError,
+			where:    "profile p > knowledge topics",
+			msg:      `topic "nope" doesn't exist`,
+		},
+		{
+			name:     "missing next topic",
+			topic:    &defs.DialogTopic{ID: "t", Prompt: "T", Responses: []defs.DialogResponse{{Text: "Go [there].", NextTopics: []defs.TopicID{"nope"}}}},
+			severity: SeverityError,
+			where:    "topic t > response 1 > next topics",
+			msg:      `topic "nope" doesn't exist`,
+		},
+		{
+			name:     "profile without greeting",
+			profile:  &defs.DialogProfileDef{ProfileID: "p"},
+			severity: SeverityError,
+			where:    "profile p",
+			msg:      "no greeting",
+		},
+		{
+			name: "unreachable response",
+			topic: &defs.DialogTopic{ID: "t", Prompt: "T", Responses: []defs.DialogResponse{
+				{Text: "Always."},
+				{Text: "Never.", Conditions: cond},
+			}},
+			severity: SeverityError,
+			where:    "topic t > response 2",
+			msg:      "can never be shown",
+		},
+		{
+			name:     "once without id",
+			topic:    &defs.DialogTopic{ID: "t", Prompt: "T", Responses: []defs.DialogResponse{{Text: "Hi.", Once: true}, {Text: "Hi again."}}},
+			severity: SeverityError,
+			where:    "topic t > response 1",
+			msg:      "marked Once, but has no ID",
+		},
+		{
+			name:     "link without next topic",
+			topic:    &defs.DialogTopic{ID: "t", Prompt: "T", Responses: []defs.DialogResponse{{Text: "Ask about [the mine]."}}},
+			severity: SeverityError,
+			where:    "topic t > response 1",
+			msg:      "no topic for: the mine",
+		},
+		{
+			name:     "unbalanced brackets",
+			topic:    &defs.DialogTopic{ID: "t", Prompt: "T", Responses: []defs.DialogResponse{{Text: "Ask about [the mine."}}},
+			severity: SeverityError,
+			where:    "topic t > response 1",
+			msg:      "unbalanced square brackets",
+		},
+		{
+			name:     "all responses conditional",
+			topic:    &defs.DialogTopic{ID: "t", Prompt: "T", Responses: []defs.DialogResponse{{Text: "Maybe.", Conditions: cond}}},
+			severity: SeverityWarning,
+			where:    "topic t",
+			msg:      "every response has conditions",
+		},
+		{
+			name: "all replies conditional",
+			topic: &defs.DialogTopic{ID: "t", Prompt: "T", Responses: []defs.DialogResponse{
+				{Text: "Well?", Replies: []defs.DialogReply{{Text: "Yes.", Conditions: cond}}},
+			}},
+			severity: SeverityWarning,
+			where:    "topic t > response 1",
+			msg:      "every reply has conditions",
+		},
+		{
+			name: "skill check without failure",
+			topic: &defs.DialogTopic{ID: "t", Prompt: "T", Responses: []defs.DialogResponse{
+				{Text: "Well?", Replies: []defs.DialogReply{{Text: "Trust me.", SkillCheck: &defs.DialogSkillCheck{ID: "persuade", Success: &defs.DialogResponse{Text: "Fine."}}}}},
+			}},
+			severity: SeverityError,
+			where:    `topic t > response 1 > reply "Trust me."`,
+			msg:      "needs both a success and failure response",
+		},
+		{
+			name:     "topic without prompt",
+			topic:    &defs.DialogTopic{ID: "t", Responses: []defs.DialogResponse{{Text: "Hi."}}},
+			severity: SeverityError,
+			where:    "topic t",
+			msg:      "no prompt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataman := newTestDataman()
+			if tt.topic != nil {
+				dataman.DialogTopics[tt.topic.ID] = tt.topic
+			}
+			if tt.profile != nil {
+				dataman.DialogProfiles[tt.profile.ProfileID] = tt.profile
+			}
+
+			issues := Lint(dataman)
+			for _, issue := range issues {
+				if issue.Severity == tt.severity && issue.Where == tt.where && strings.Contains(issue.Msg, tt.msg) {
+					return
+				}
+			}
+			t.Errorf("expected %s at %q containing %q, got: %v", tt.severity, tt.where, tt.msg, issues)
+		})
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if HasErrors([]Issue{{Severity: SeverityWarning}}) {
+		t.Error("warnings alone shouldn't count as errors")
+	}
+	if !HasErrors([]Issue{{Severity: SeverityWarning}, {Severity: SeverityError}}) {
+		t.Error("expected an error to be found")
+	}
+}
+
+func TestMainLintsLoadedDialog(t *testing.T) {
+	// dialog defined in Go (i.e. already in the data manager) is linted without any scripts
+	if code := Main(newTestDataman(), []string{"lint"}); code != 0 {
+		t.Errorf("expected exit code 0 for valid dialog, got %v", code)
+	}
+
+	dataman := newTestDataman()
+	dataman.DialogProfiles["guard"].TopicsIDs = append(dataman.DialogProfiles["guard"].TopicsIDs, "nope")
+	if code := Main(dataman, []string{"lint"}); code != 1 {
+		t.Errorf("expected exit code 1 for broken dialog, got %v", code)
+	}
+
+	if code := Main(datamanager.NewDataManager(), []string{"lint"}); code != 1 {
+		t.Errorf("expected exit code 1 when there's no dialog to check, got %v", code)
+	}
+}
@@ -0,0 +1,246 @@
+package dialoglint
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	"github.com/webbben/2d-game-engine/quest"
+)
+
+// MockQuestStage is the state a quest is in, for a MockContext.
+type MockQuestStage struct {
+	StageID defs.QuestStageID
+	Status  defs.QuestStatus
+}
+
+// MockContext is a stand-in for the game's dialog context, so dialog can be run without the game world.
+// Conditions are checked against its fields, which can be set up however you like to try out different situations.
+// Dialog effects (like memory) update it, and world effects are just printed to Out, since there's no world to change.
+type MockContext struct {
+	Dataman *datamanager.DataManager // OPT: if set, character, class and skill defs are looked up here
+
+	NPCID     id.CharacterStateID
+	ProfileID defs.DialogProfileID
+	ClassDef  defs.ClassDef
+	MapDef    defs.MapDef
+	GameTime  clock.GameTime
+
+	PlayerInfo  defs.PlayerInfo
+	Gold        int
+	Opinion     int
+	Skills      map[defs.SkillID]int
+	Attributes  map[defs.AttributeID]int
+	Items       map[defs.ItemID]bool
+	Equipped    map[defs.ItemID]bool
+	Knowledge   map[defs.TopicID]bool
+	SocialRanks map[id.CharacterStateID]defs.SocialRank
+	Roles       map[id.CharacterStateID][]defs.RoleID
+	QuestStages map[defs.QuestID]MockQuestStage
+
+	Memory        map[string]bool
+	MemoryValues  map[string]string
+	SeenTopics    map[defs.TopicID]bool
+	SeenResponses map[string]bool
+
+	Out io.Writer // where world effects are reported. if nil, they're ignored.
+}
+
+// NewMockContext creates a mock context with all its maps ready to use.
+func NewMockContext(dataman *datamanager.DataManager, profileID defs.DialogProfileID) *MockContext {
+	return &MockContext{
+		Dataman:       dataman,
+		ProfileID:     profileID,
+		Skills:        make(map[defs.SkillID]int),
+		Attributes:    make(map[defs.AttributeID]int),
+		Items:         make(map[defs.ItemID]bool),
+		Equipped:      make(map[defs.ItemID]bool),
+		Knowledge:     make(map[defs.TopicID]bool),
+		SocialRanks:   make(map[id.CharacterStateID]defs.SocialRank),
+		Roles:         make(map[id.CharacterStateID][]defs.RoleID),
+		QuestStages:   make(map[defs.QuestID]MockQuestStage),
+		Memory:        make(map[string]bool),
+		MemoryValues:  make(map[string]string),
+		SeenTopics:    make(map[defs.TopicID]bool),
+		SeenResponses: make(map[string]bool),
+	}
+}
+
+var (
+	_ defs.ConditionContext    = (*MockContext)(nil)
+	_ defs.DialogEffectContext = (*MockContext)(nil)
+	_ defs.WorldEffectContext  = (*MockContext)(nil)
+)
+
+func (ctx *MockContext) report(format string, args ...any) {
+	if ctx.Out == nil {
+		return
+	}
+	fmt.Fprintf(ctx.Out, "  [world] "+format+"\n", args...)
+}
+
+// Condition context
+
+func (ctx *MockContext) GetQuestStage(qid defs.QuestID) (defs.QuestStageDef, defs.QuestStatus) {
+	stage, exists := ctx.QuestStages[qid]
+	if !exists {
+		return defs.QuestStageDef{}, quest.NotStarted
+	}
+	return defs.QuestStageDef{ID: stage.StageID}, stage.Status
+}
+
+func (ctx *MockContext) GetNPCCharStateID() id.CharacterStateID { return ctx.NPCID }
+func (ctx *MockContext) GetNPCClassDef() defs.ClassDef          { return ctx.ClassDef }
+func (ctx *MockContext) GetActiveMapDef() defs.MapDef           { return ctx.MapDef }
+func (ctx *MockContext) HasSeenTopic(id defs.TopicID) bool      { return ctx.SeenTopics[id] }
+func (ctx *MockContext) HasMemory(key string) bool              { return ctx.Memory[key] }
+func (ctx *MockContext) GetPlayerGold() int                     { return ctx.Gold }
+func (ctx *MockContext) GetNPCDialogProfileID() defs.DialogProfileID {
+	return ctx.ProfileID
+}
+func (ctx *MockContext) IsItemEquipped(itemID defs.ItemID) bool       { return ctx.Equipped[itemID] }
+func (ctx *MockContext) PlayerHasKnowledge(topicID defs.TopicID) bool { return ctx.Knowledge[topicID] }
+func (ctx *MockContext) PlayerHasItem(itemID defs.ItemID) bool        { return ctx.Items[itemID] }
+func (ctx *MockContext) GetPlayerSkillLevel(skillID defs.SkillID) int { return ctx.Skills[skillID] }
+func (ctx *MockContext) GetOpinionOfPlayer() int                      { return ctx.Opinion }
+
+func (ctx *MockContext) GetPlayerAttributeLevel(attrID defs.AttributeID) int {
+	return ctx.Attributes[attrID]
+}
+
+func (ctx *MockContext) GetMemoryValue(key string) (string, bool) {
+	val, exists := ctx.MemoryValues[key]
+	return val, exists
+}
+
+func (ctx *MockContext) GetCharacterDef(id defs.CharacterDefID) defs.CharacterDef {
+	if ctx.Dataman != nil {
+		if def, exists := ctx.Dataman.CharacterDefs[id]; exists {
+			return def
+		}
+	}
+	return defs.CharacterDef{ID: id}
+}
+
+func (ctx *MockContext) GetCharacterSocialRank(id id.CharacterStateID) defs.SocialRank {
+	return ctx.SocialRanks[id]
+}
+
+func (ctx *MockContext) CharacterHasRole(id id.CharacterStateID, roleID defs.RoleID) bool {
+	return slices.Contains(ctx.Roles[id], roleID)
+}
+
+func (ctx *MockContext) GetSkillDef(skillID defs.SkillID) defs.SkillDef {
+	if ctx.Dataman != nil {
+		if def, exists := ctx.Dataman.SkillDefs[skillID]; exists {
+			return def
+		}
+	}
+	return defs.SkillDef{ID: skillID, DisplayName: string(skillID)}
+}
+
+// Dialog effect context
+
+func (ctx *MockContext) RecordTopicSeen(id defs.TopicID) {
+	ctx.SeenTopics[id] = true
+}
+
+func (ctx *MockContext) RecordTopicUnlocked(id defs.TopicID) {
+	ctx.Knowledge[id] = true
+}
+
+func (ctx *MockContext) RecordMiscDialogMemory(key string) {
+	ctx.Memory[key] = true
+}
+
+// SetMemoryValue works the same as the real dialog context; an empty value clears the key.
+func (ctx *MockContext) SetMemoryValue(key, value string) {
+	if value == "" {
+		delete(ctx.MemoryValues, key)
+		return
+	}
+	ctx.MemoryValues[key] = value
+}
+
+// World effect context
+
+func (ctx *MockContext) TravelToMap(mapID defs.MapID, spawnIndex int, hours int) {
+	ctx.report("travel to map %s (spawn %d, %d hours)", mapID, spawnIndex, hours)
+}
+
+func (ctx *MockContext) QueueScenario(id defs.ScenarioID) {
+	ctx.report("queue scenario %s", id)
+}
+
+func (ctx *MockContext) UnlockMapLock(mapID defs.MapID, lockID string) {
+	ctx.report("unlock %s in map %s", lockID, mapID)
+}
+
+func (ctx *MockContext) SetMapLock(mapID defs.MapID, lockID string, lockLevel int) {
+	ctx.report("set lock %s in map %s to level %d", lockID, mapID, lockLevel)
+}
+
+func (ctx *MockContext) GetCurrentGameTime() clock.GameTime { return ctx.GameTime }
+
+func (ctx *MockContext) BroadcastEvent(event defs.Event) {
+	ctx.report("event %s %v", event.Type, event.Data)
+}
+
+func (ctx *MockContext) AddGold(amount int) {
+	ctx.Gold += amount
+	ctx.report("add %d gold (now %d)", amount, ctx.Gold)
+}
+
+func (ctx *MockContext) RemoveGold(amount int) {
+	ctx.Gold -= amount
+	ctx.report("remove %d gold (now %d)", amount, ctx.Gold)
+}
+
+func (ctx *MockContext) AddItem(itemID defs.ItemID, quantity int) {
+	ctx.Items[itemID] = true
+	ctx.report("add item %s x%d", itemID, quantity)
+}
+
+func (ctx *MockContext) AddRole(roleID defs.RoleID) {
+	playerID := id.CharacterStateID(defs.PlayerID)
+	if !slices.Contains(ctx.Roles[playerID], roleID) {
+		ctx.Roles[playerID] = append(ctx.Roles[playerID], roleID)
+	}
+	ctx.report("add role %s", roleID)
+}
+
+func (ctx *MockContext) RemoveRole(roleID defs.RoleID) {
+	playerID := id.CharacterStateID(defs.PlayerID)
+	ctx.Roles[playerID] = slices.DeleteFunc(ctx.Roles[playerID], func(r defs.RoleID) bool { return r == roleID })
+	ctx.report("remove role %s", roleID)
+}
+
+func (ctx *MockContext) AddSkillXP(skillID defs.SkillID, xp int) {
+	ctx.report("add %d %s xp", xp, skillID)
+}
+
+func (ctx *MockContext) AddKnowledge(topicID defs.TopicID) {
+	ctx.Knowledge[topicID] = true
+	ctx.report("add knowledge %s", topicID)
+}
+
+func (ctx *MockContext) AssignTaskToNPC(id defs.CharacterDefID, taskDef defs.TaskDef, requireListener bool) {
+	ctx.report("assign task %s to %s", taskDef.TaskID, id)
+}
+
+func (ctx *MockContext) AddOpinionModifier(holder, subject id.CharacterStateID, mod defs.OpinionModifier) {
+	if holder == ctx.NPCID && subject == id.CharacterStateID(defs.PlayerID) {
+		ctx.Opinion += mod.Mod
+	}
+	ctx.report("opinion modifier for %s of %s: %s", holder, subject, mod)
+}
+
+func (ctx *MockContext) GetDialogNPC() id.CharacterStateID { return ctx.NPCID }
+
+func (ctx *MockContext) PlayEmote(charID id.CharacterStateID, emote defs.DialogEmote) {
+	ctx.report("%s emotes: %+v", charID, emote)
+}
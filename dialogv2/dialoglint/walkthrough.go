@@ -0,0 +1,414 @@
+package dialoglint
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/dialogv2"
+)
+
+// errQuit is returned when the input runs out, so the walkthrough just ends.
+var errQuit = errors.New("walkthrough quit")
+
+// Walkthrough runs a dialog profile in the terminal. NPC text is printed, and the writer picks topics and replies by number.
+// Conditions are checked against a MockContext, so you can set it up beforehand to try out different situations.
+//
+// Text is shown as written (dialog variables aren't filled in), so you can see exactly what's in the data.
+type Walkthrough struct {
+	Ctx *MockContext
+
+	dataman *datamanager.DataManager
+	profile *defs.DialogProfileDef
+	in      *bufio.Reader
+	out     io.Writer
+
+	links []defs.TopicID // topics linked in the last response's text
+	done  bool
+}
+
+func NewWalkthrough(dataman *datamanager.DataManager, profileID defs.DialogProfileID, ctx *MockContext, in io.Reader, out io.Writer) (*Walkthrough, error) {
+	profile, exists := dataman.DialogProfiles[profileID]
+	if !exists {
+		return nil, fmt.Errorf("dialog profile %q doesn't exist", profileID)
+	}
+	if ctx == nil {
+		ctx = NewMockContext(dataman, profileID)
+	}
+	if ctx.Out == nil {
+		ctx.Out = out
+	}
+	return &Walkthrough{
+		Ctx:     ctx,
+		dataman: dataman,
+		profile: profile,
+		in:      bufio.NewReader(in),
+		out:     out,
+	}, nil
+}
+
+// Run plays the dialog until the player says goodbye, the dialog exits, or the input runs out.
+// An error is returned if the dialog breaks (e.g. no response could be chosen), which would be a panic in the game.
+func (w *Walkthrough) Run() error {
+	w.printf("== %s ==\n(type a number to choose, 'state' to see the mock context, or 'q' to quit)\n\n", w.profile.ProfileID)
+
+	err := w.walk()
+	if errors.Is(err, errQuit) {
+		return nil
+	}
+	return err
+}
+
+func (w *Walkthrough) walk() error {
+	greeting, err := w.chooseResponse(w.profile.Greeting, "greeting")
+	if err != nil {
+		return err
+	}
+	if err := w.applyResponse(greeting); err != nil {
+		return err
+	}
+
+	for !w.done {
+		topics := w.topicOptions()
+		w.printf("\nTopics:\n")
+		for i, topic := range topics {
+			w.printf("  %d) %s\n", i+1, topic.Prompt)
+		}
+		choice, err := w.choose(len(topics))
+		if err != nil {
+			return err
+		}
+		topic := topics[choice]
+		w.printf("> %s\n", topic.Prompt)
+		w.Ctx.RecordTopicSeen(topic.ID)
+
+		resp, err := w.chooseResponse(topic.Responses, "topic "+string(topic.ID))
+		if err != nil {
+			return err
+		}
+		if err := w.applyResponse(resp); err != nil {
+			return err
+		}
+	}
+	w.printf("\n== dialog ended ==\n")
+	return nil
+}
+
+func (w *Walkthrough) printf(format string, args ...any) {
+	fmt.Fprintf(w.out, format, args...)
+}
+
+// topicOptions gets the topics the player can bring up, the same way the dialog session does.
+// Topics linked in the last response are included too, since those can be clicked in the game.
+func (w *Walkthrough) topicOptions() []defs.DialogTopic {
+	seen := make(map[defs.TopicID]bool)
+	options := []defs.DialogTopic{}
+	add := func(topicID defs.TopicID, checkConditions bool) {
+		if seen[topicID] {
+			return
+		}
+		seen[topicID] = true
+		topic, exists := w.dataman.DialogTopics[topicID]
+		if !exists {
+			w.printf("  (skipping missing topic %s)\n", topicID)
+			return
+		}
+		if !checkConditions || dialogv2.ConditionsMet(topic.Conditions, w.Ctx) {
+			options = append(options, *topic)
+		}
+	}
+	for _, topicID := range w.links {
+		add(topicID, false)
+	}
+	for _, topicID := range w.profile.TopicsIDs {
+		add(topicID, true)
+	}
+	for _, topicID := range w.profile.KnowledgeTopics {
+		if w.Ctx.PlayerHasKnowledge(topicID) {
+			add(topicID, true)
+		}
+	}
+	return options
+}
+
+// choose reads the player's choice of n options, and returns its index. Quitting (or running out of input) returns errQuit.
+func (w *Walkthrough) choose(n int) (int, error) {
+	for {
+		w.printf("choice> ")
+		line, err := w.in.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" && err != nil {
+			return 0, errQuit
+		}
+		switch line {
+		case "q", "quit":
+			return 0, errQuit
+		case "state":
+			w.printState()
+			continue
+		}
+		i, convErr := strconv.Atoi(line)
+		if convErr == nil && i >= 1 && i <= n {
+			return i - 1, nil
+		}
+		w.printf("please enter a number from 1 to %d\n", n)
+	}
+}
+
+// prompt reads a line of free text from the player.
+func (w *Walkthrough) prompt(msg string) (string, error) {
+	w.printf("%s> ", msg)
+	line, err := w.in.ReadString('\n')
+	if line == "" && err != nil {
+		return "", errQuit
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (w *Walkthrough) printState() {
+	w.printf("  gold: %d, opinion: %d\n", w.Ctx.Gold, w.Ctx.Opinion)
+	w.printf("  memory: %v\n", sortedKeys(w.Ctx.Memory))
+	w.printf("  memory values: %v\n", w.Ctx.MemoryValues)
+	w.printf("  knowledge: %v\n", sortedKeys(w.Ctx.Knowledge))
+	w.printf("  seen topics: %v\n", sortedKeys(w.Ctx.SeenTopics))
+}
+
+// chooseResponse picks the first response whose conditions are met, like the dialog session does.
+func (w *Walkthrough) chooseResponse(responses []defs.DialogResponse, where string) (defs.DialogResponse, error) {
+	for _, resp := range responses {
+		if resp.Once && resp.ID != "" && w.Ctx.SeenResponses[resp.ID] {
+			continue
+		}
+		if dialogv2.ConditionsMet(resp.Conditions, w.Ctx) {
+			return resp, nil
+		}
+	}
+	return defs.DialogResponse{}, fmt.Errorf("%s: no response had its conditions met (the game would panic here)", where)
+}
+
+func (w *Walkthrough) speakerName(resp defs.DialogResponse) string {
+	if resp.Speaker != "" {
+		return string(resp.Speaker)
+	}
+	if w.Ctx.NPCID != "" {
+		return string(w.Ctx.NPCID)
+	}
+	return "NPC"
+}
+
+func (w *Walkthrough) applyResponse(resp defs.DialogResponse) error {
+	if resp.ID != "" {
+		w.Ctx.SeenResponses[resp.ID] = true
+	}
+	if resp.Action != nil {
+		if err := w.runAction(*resp.Action); err != nil {
+			return err
+		}
+	}
+
+	for _, topicID := range resp.NextTopics {
+		if !w.Ctx.Knowledge[topicID] {
+			w.printf("  [unlocked topic %s]\n", topicID)
+		}
+		w.Ctx.RecordTopicUnlocked(topicID)
+	}
+	for _, effect := range resp.Effects {
+		effect.Apply(w.Ctx)
+	}
+	for _, effect := range resp.WorldEffects {
+		effect.Apply(w.Ctx)
+	}
+
+	if resp.Exit {
+		w.done = true
+		return nil
+	}
+
+	if resp.Text == "" {
+		// response grouper
+		if resp.NextResponse != nil {
+			return w.applyResponse(*resp.NextResponse)
+		}
+		if len(resp.NextResponseOptions) > 0 {
+			next, err := w.chooseResponse(resp.NextResponseOptions, "next response options")
+			if err != nil {
+				return err
+			}
+			return w.applyResponse(next)
+		}
+		return nil
+	}
+
+	w.printf("%s: %s\n", w.speakerName(resp), resp.Text)
+	w.links = nil
+	for i := range linkRegex.FindAllStringSubmatch(resp.Text, -1) {
+		if i < len(resp.NextTopics) {
+			w.links = append(w.links, resp.NextTopics[i])
+		}
+	}
+
+	if resp.NextResponse != nil {
+		return w.applyResponse(*resp.NextResponse)
+	}
+	if len(resp.NextResponseOptions) > 0 {
+		next, err := w.chooseResponse(resp.NextResponseOptions, "next response options")
+		if err != nil {
+			return err
+		}
+		return w.applyResponse(next)
+	}
+
+	if resp.Goodbye {
+		w.printf("  1) %s\n", dialogv2.Goodbye)
+		if _, err := w.choose(1); err != nil {
+			return err
+		}
+		w.done = true
+		return nil
+	}
+	if len(resp.Replies) > 0 {
+		return w.chooseReply(resp.Replies)
+	}
+	return nil
+}
+
+func (w *Walkthrough) chooseReply(replies []defs.DialogReply) error {
+	valid := []defs.DialogReply{}
+	for _, reply := range replies {
+		if dialogv2.ConditionsMet(reply.Conditions, w.Ctx) {
+			valid = append(valid, reply)
+		}
+	}
+	if len(valid) == 0 {
+		return errors.New("no replies had their conditions met (the game would panic here)")
+	}
+	for i, reply := range valid {
+		info := ""
+		if reply.InfoText != nil {
+			info = fmt.Sprintf(" (%s)", reply.InfoText.GetInfoText(w.Ctx))
+		} else if reply.SkillCheck != nil {
+			info = fmt.Sprintf(" (%s)", reply.SkillCheck.GetInfoText(w.Ctx))
+		}
+		w.printf("  %d) %s%s\n", i+1, reply.Text, info)
+	}
+	choice, err := w.choose(len(valid))
+	if err != nil {
+		return err
+	}
+	return w.applyReply(valid[choice])
+}
+
+func (w *Walkthrough) applyReply(reply defs.DialogReply) error {
+	w.printf("> %s\n", reply.Text)
+	for _, effect := range reply.Effects {
+		effect.Apply(w.Ctx)
+	}
+	for _, effect := range reply.WorldEffects {
+		effect.Apply(w.Ctx)
+	}
+	if reply.Goodbye {
+		w.done = true
+		return nil
+	}
+
+	if reply.SkillCheck != nil {
+		passed, err := w.rollSkillCheck(*reply.SkillCheck)
+		if err != nil {
+			return err
+		}
+		if passed {
+			return w.applyResponse(*reply.SkillCheck.Success)
+		}
+		return w.applyResponse(*reply.SkillCheck.Failure)
+	}
+
+	if reply.NextResponse != nil {
+		return w.applyResponse(*reply.NextResponse)
+	}
+	if len(reply.NextResponseOptions) > 0 {
+		next, err := w.chooseResponse(reply.NextResponseOptions, "reply next response options")
+		if err != nil {
+			return err
+		}
+		return w.applyResponse(next)
+	}
+	return nil
+}
+
+// rollSkillCheck lets the writer decide how a skill check goes, instead of rolling for it.
+func (w *Walkthrough) rollSkillCheck(check defs.DialogSkillCheck) (bool, error) {
+	if passed, attempted := check.Result(w.Ctx); attempted {
+		return passed, nil
+	}
+	w.printf("  [skill check %s: %d%% chance]\n", check.ID, check.SuccessChance(w.Ctx))
+	w.printf("  1) pass\n  2) fail\n")
+	choice, err := w.choose(2)
+	if err != nil {
+		return false, err
+	}
+	passed := choice == 0
+	w.Ctx.RecordMiscDialogMemory(defs.SkillCheckMemoryKey(check.ID, passed))
+	w.Ctx.AddSkillXP(check.SkillID, check.GetXP())
+	return passed, nil
+}
+
+// runAction stands in for dialog actions; anything that needs input from the player is asked for in the terminal.
+func (w *Walkthrough) runAction(action defs.DialogAction) error {
+	switch action.Type {
+	case dialogv2.ActionTypeShowScreen:
+		params, _ := action.Params.(dialogv2.ShowScreenActionParams)
+		w.printf("  [action: show screen %s]\n", params.ScreenID)
+	case dialogv2.ActionTypeTextInput:
+		params, ok := action.Params.(dialogv2.TextInputActionParams)
+		if !ok {
+			return errors.New("text input action has the wrong params type")
+		}
+		text, err := w.prompt(fmt.Sprintf("  [action: text input] %s", params.Title))
+		if err != nil {
+			return err
+		}
+		w.Ctx.SetMemoryValue(params.ResultKey, text)
+	case dialogv2.ActionTypeSelectItem:
+		params, ok := action.Params.(dialogv2.SelectItemActionParams)
+		if !ok {
+			return errors.New("select item action has the wrong params type")
+		}
+		itemID, err := w.prompt(fmt.Sprintf("  [action: select item; enter an item ID, or nothing to cancel] %s", params.Title))
+		if err != nil {
+			return err
+		}
+		w.Ctx.SetMemoryValue(params.ResultKey, itemID)
+	case dialogv2.ActionTypeGiveItem:
+		params, ok := action.Params.(dialogv2.GiveItemActionParams)
+		if !ok {
+			return errors.New("give item action has the wrong params type")
+		}
+		itemID := params.ItemID
+		if params.ItemFromKey != "" {
+			val, _ := w.Ctx.GetMemoryValue(params.ItemFromKey)
+			itemID = defs.ItemID(val)
+		}
+		given := itemID != "" && w.Ctx.Items[itemID]
+		if given {
+			delete(w.Ctx.Items, itemID)
+			w.printf("  [action: gave %s to the NPC]\n", itemID)
+		} else {
+			w.printf("  [action: player doesn't have %q to give]\n", itemID)
+		}
+		if params.ResultKey != "" {
+			if given {
+				w.Ctx.SetMemoryValue(params.ResultKey, string(itemID))
+			} else {
+				w.Ctx.SetMemoryValue(params.ResultKey, "")
+			}
+		}
+	default:
+		w.printf("  [action: %s (skipped)]\n", action.Type)
+	}
+	return nil
+}
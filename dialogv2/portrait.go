@@ -0,0 +1,119 @@
+package dialogv2
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	"github.com/webbben/2d-game-engine/imgutil/rendering"
+	"github.com/webbben/2d-game-engine/tiled"
+	"github.com/webbben/2d-game-engine/utils"
+)
+
+// speakerPortrait is the portrait of whoever is currently speaking, along with the box it's drawn in.
+type speakerPortrait struct {
+	img    *ebiten.Image
+	boxImg *ebiten.Image
+}
+
+// getSpeakerLook gets the portrait and voice of a speaker. The character def's own portrait and voice come first, then the ones in their
+// dialog profile; either can be nil if neither sets it.
+func (ds *DialogSession) getSpeakerLook(speakerID id.CharacterStateID) (*defs.PortraitDef, *defs.VoiceDef) {
+	charState := ds.dataman.GetCharacterState(speakerID)
+	charDef := ds.dataman.GetCharacterDef(charState.DefID)
+	portrait, voice := charDef.Portrait, charDef.Voice
+	if portrait != nil && voice != nil {
+		return portrait, voice
+	}
+
+	var profile *defs.DialogProfileDef
+	if speakerID == id.CharacterStateID(ds.Ctx.NPCID) {
+		// the NPC the player is talking to might be using a different profile than their def's (e.g. generated characters)
+		profile = ds.ProfileDef
+	} else {
+		profileID := charState.OverrideDialogProfileID
+		if profileID == "" {
+			profileID = charDef.DialogProfileID
+		}
+		if profileID != "" {
+			profile = ds.dataman.GetDialogProfile(profileID)
+		}
+	}
+	if profile != nil {
+		if portrait == nil {
+			portrait = profile.Portrait
+		}
+		if voice == nil {
+			voice = profile.Voice
+		}
+	}
+	return portrait, voice
+}
+
+// setSpeakerLook updates the portrait and text blip voice for the current speaker, using the given expression.
+func (ds *DialogSession) setSpeakerLook(expr defs.PortraitExpression) {
+	portraitDef, voiceDef := ds.getSpeakerLook(ds.currentSpeaker)
+
+	blipSFX := config.DefaultTextBlipSfx
+	tickInterval := defs.DefaultVoiceTickInterval
+	pitch := 1.0
+	if voiceDef != nil {
+		if voiceDef.BlipSFX != "" {
+			blipSFX = voiceDef.BlipSFX
+		}
+		tickInterval = voiceDef.GetTickInterval()
+		pitch = voiceDef.GetPitch()
+	}
+	ds.LineWriter.SetTextBlip(blipSFX, tickInterval, pitch)
+
+	if !config.DialogShowPortraits || portraitDef == nil {
+		ds.portrait = speakerPortrait{}
+		return
+	}
+	if expr == "" {
+		expr = defs.ExpressionNeutral
+	}
+
+	var img *ebiten.Image
+	if portraitDef.UsesAvatar() {
+		// avatars only have the one expression, so they can be reused for the whole conversation
+		img = ds.portraitAvatars[ds.currentSpeaker]
+		if img == nil {
+			img = ds.ctxForScreen.GetEntityAvatar(ds.currentSpeaker, 'D')
+			ds.portraitAvatars[ds.currentSpeaker] = img
+		}
+	} else {
+		img = tiled.GetTileImage(portraitDef.TilesetSrc, portraitDef.TileIndex(expr), true)
+	}
+
+	if ds.portrait.img != nil && ds.portrait.img.Bounds().Size() == img.Bounds().Size() {
+		// same size as the last portrait, so the box can be reused
+		ds.portrait.img = img
+		return
+	}
+	tileSize := int(config.GetScaledTilesize())
+	boxDx := utils.RoundUpToTile(int(float64(img.Bounds().Dx())*config.UIScale), tileSize) + tileSize
+	boxDy := utils.RoundUpToTile(int(float64(img.Bounds().Dy())*config.UIScale), tileSize) + tileSize
+	ds.portrait = speakerPortrait{
+		img:    img,
+		boxImg: ds.boxSrc.BuildBoxImage(boxDx, boxDy, config.UIScale),
+	}
+}
+
+// drawPortrait draws the speaker's portrait in a box just above the dialog text box (and its name title).
+func (ds *DialogSession) drawPortrait(screen *ebiten.Image, textBoxX, textBoxY int) {
+	if ds.portrait.img == nil {
+		return
+	}
+	tileSize := config.GetScaledTilesize()
+	boxBounds := ds.portrait.boxImg.Bounds()
+	boxX := float64(textBoxX)
+	boxY := float64(textBoxY) - tileSize - float64(boxBounds.Dy())
+	rendering.DrawImage(screen, ds.portrait.boxImg, boxX, boxY, 0)
+
+	imgDx := float64(ds.portrait.img.Bounds().Dx()) * config.UIScale
+	imgDy := float64(ds.portrait.img.Bounds().Dy()) * config.UIScale
+	imgX := boxX + (float64(boxBounds.Dx())-imgDx)/2
+	imgY := boxY + (float64(boxBounds.Dy())-imgDy)/2
+	rendering.DrawImage(screen, ds.portrait.img, imgX, imgY, config.UIScale)
+}
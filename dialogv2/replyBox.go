@@ -41,8 +41,9 @@ func (ds *DialogSession) setupReplyBox(maxReplyWidth int, replies []defs.DialogR
 
 	totalHeight := 0
 
+	ctx := ds.npcCtx()
 	for _, reply := range replies {
-		replyComponent := NewReplyComponent(reply, maxWidth, ds.audioman, ds.Ctx)
+		replyComponent := NewReplyComponent(reply, maxWidth, ds.audioman, ctx)
 		ds.replyBox.replyButtons = append(ds.replyBox.replyButtons, &replyComponent)
 		_, dy := replyComponent.Dimensions()
 		totalHeight += dy
@@ -112,6 +113,8 @@ func NewReplyComponent(reply defs.DialogReply, maxWidth int, audioman *audio.Aud
 
 	if reply.InfoText != nil {
 		rc.InfoText = reply.InfoText.GetInfoText(ctx)
+	} else if reply.SkillCheck != nil {
+		rc.InfoText = reply.SkillCheck.GetInfoText(ctx)
 	}
 
 	padding := 20 // padding on each side of inner content
@@ -0,0 +1,37 @@
+package dialogv2
+
+import (
+	"math/rand"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/logz"
+	"github.com/webbben/2d-game-engine/pubsub"
+)
+
+// RollSkillCheck decides if the player passes a skill check. The first time a check is attempted, it's rolled using the check's success chance,
+// the skill gets some XP, and the outcome is recorded in dialog memory. After that, the recorded outcome is always returned.
+func (ctx *DialogContext) RollSkillCheck(check defs.DialogSkillCheck) bool {
+	if passed, attempted := check.Result(ctx); attempted {
+		return passed
+	}
+
+	chance := check.SuccessChance(ctx)
+	passed := rand.Intn(100) < chance
+	logz.Println("RollSkillCheck", check.ID, "chance:", chance, "passed:", passed)
+
+	ctx.RecordMiscDialogMemory(defs.SkillCheckMemoryKey(check.ID, passed))
+	ctx.AddSkillXP(check.SkillID, check.GetXP())
+
+	if ctx.eventBus != nil {
+		ctx.eventBus.Publish(defs.Event{
+			Type: pubsub.EventDialogSkillCheck,
+			Data: map[string]any{
+				"checkID": check.ID,
+				"skillID": check.SkillID,
+				"passed":  passed,
+			},
+		})
+	}
+
+	return passed
+}
@@ -5,6 +5,7 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/webbben/2d-game-engine/config"
 	"github.com/webbben/2d-game-engine/display"
 	"github.com/webbben/2d-game-engine/imgutil/rendering"
@@ -47,6 +48,12 @@ func (ds *DialogSession) updateDialogResponse() {
 				ds.continueApplyResponse()
 			}
 			return
+		case ActionTypeTextInput:
+			ds.updateTextInput()
+			return
+		case ActionTypeSelectItem:
+			ds.updateSelectItem()
+			return
 		default:
 			logz.Panicln("updateDialogResponse", "action type not recognized:", ds.currentResponse.Action.Type)
 		}
@@ -96,7 +103,8 @@ func (ds *DialogSession) updateDialogResponse() {
 			// find the correct next response to move on to
 			if ds.flashUntilContinue() {
 				for _, nr := range ds.currentResponse.NextResponseOptions {
-					if ConditionsMet(nr.Conditions, ds.Ctx) {
+					// like in chooseResponse, conditions are evaluated against whoever would say the response, not whoever spoke last
+					if ConditionsMet(nr.Conditions, ds.Ctx.withSpeaker(nr.Speaker)) {
 						ds.ApplyResponse(nr)
 						return
 					}
@@ -159,6 +167,17 @@ func (ds *DialogSession) updateDialogResponse() {
 }
 
 func (ds *DialogSession) Update() {
+	// don't toggle while something else is taking input; the key might just be part of what the player is typing
+	takingInput := ds.screenViewer != nil || ds.textInputModal != nil || ds.itemSelect != nil
+	if !takingInput && inpututil.IsKeyJustPressed(config.DialogTranscriptKey) {
+		ds.toggleTranscript()
+	}
+	if ds.transcriptView != nil {
+		// dialog is paused while the transcript is open
+		ds.transcriptView.Update()
+		return
+	}
+
 	// handle text display
 	ds.LineWriter.Update()
 
@@ -249,6 +268,7 @@ func (ds *DialogSession) Draw(screen *ebiten.Image) {
 	textBoxX := int(startX)
 	rendering.DrawImage(screen, ds.TextBoxImg, float64(textBoxX), float64(textBoxY), 0)
 	ds.nameTitle.Draw(screen, float64(textBoxX), float64(textBoxY)-tileSize)
+	ds.drawPortrait(screen, textBoxX, textBoxY)
 
 	lwX := textBoxX + int(tileSize/2)
 	lwY := textBoxY + int(tileSize*2/3) // moved a little further down, since the box title hands down a bit
@@ -346,4 +366,17 @@ func (ds *DialogSession) Draw(screen *ebiten.Image) {
 			b.Draw(screen, optionBoxX+int(tileSize/2), optionBoxY+(i*buttonHeight)+(int(tileSize/2)))
 		}
 	}
+
+	if ds.textInputModal != nil {
+		dx, dy := ds.textInputModal.Dimensions()
+		ds.textInputModal.Draw(screen, float64(display.SCREEN_WIDTH-dx)/2, float64(display.SCREEN_HEIGHT-dy)/2)
+	}
+	if ds.itemSelect != nil {
+		ds.itemSelect.draw(screen)
+	}
+
+	if ds.transcriptView != nil {
+		dx, dy := ds.transcriptView.Dimensions()
+		ds.transcriptView.Draw(screen, float64(display.SCREEN_WIDTH-dx)/2, float64(display.SCREEN_HEIGHT-dy)/2)
+	}
 }
@@ -3,29 +3,53 @@ package dialogv2
 import (
 	"strings"
 
-	"github.com/webbben/2d-game-engine/data/datamanager"
 	"github.com/webbben/2d-game-engine/data/defs"
 	"github.com/webbben/2d-game-engine/logz"
 )
 
-func InsertDialogVariables(sourceText string, playerInfo defs.PlayerInfo, dataman *datamanager.DataManager) string {
-	if strings.Contains(sourceText, "{") {
-		for _, v := range AllDialogVariables {
-			insertString := ""
+// InsertDialogVariables fills in all the variables and conditional fragments in some text (see RegisterDialogVariable and RegisterDialogFlag).
+func InsertDialogVariables(sourceText string, ctx DialogTextContext) string {
+	if !strings.Contains(sourceText, "{") {
+		return sourceText
+	}
 
-			switch v {
-			case VarPlayerName:
-				insertString = playerInfo.PlayerName
-			case VarPlayerCulture:
-				cultureDef := dataman.GetCultureDef(playerInfo.PlayerCulture)
-				insertString = cultureDef.DisplayName
-			default:
-				logz.Panicln("InsertDialogVariables", "variable name not recognized:", v)
-			}
+	tokens, err := defs.ParseDialogText(sourceText)
+	if err != nil {
+		logz.Panicln("InsertDialogVariables", err, "text:", sourceText)
+	}
 
-			sourceText = strings.ReplaceAll(sourceText, v, insertString)
+	// the output is built in one pass, so inserted values (like text the player typed) are never scanned for variables themselves
+	var out strings.Builder
+	last := 0
+	for _, tok := range tokens {
+		insertString := ""
+		if tok.IsFlag {
+			flag, exists := dialogFlags[tok.Name]
+			if !exists {
+				logz.Panicln("InsertDialogVariables", "flag name not recognized:", tok.Name)
+			}
+			ctx.checkNeeds(tok.Name, flag.needs)
+			if flag.fn(ctx, tok.Arg) {
+				insertString = tok.Options[0]
+			} else if len(tok.Options) > 1 {
+				insertString = tok.Options[1]
+			}
+			// the chosen option may have its own variables in it
+			insertString = InsertDialogVariables(insertString, ctx)
+		} else {
+			v, exists := dialogVariables[tok.Name]
+			if !exists {
+				logz.Panicln("InsertDialogVariables", "variable name not recognized:", tok.Name)
+			}
+			ctx.checkNeeds(tok.Name, v.needs)
+			insertString = v.fn(ctx, tok.Arg)
 		}
+
+		out.WriteString(sourceText[last:tok.Pos])
+		out.WriteString(insertString)
+		last = tok.Pos + len(tok.Raw)
 	}
+	out.WriteString(sourceText[last:])
 
-	return sourceText
+	return out.String()
 }
@@ -0,0 +1,48 @@
+package dialogv2
+
+import (
+	"testing"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+func init() {
+	RegisterDialogVariable("test_greeting", false, NeedsNothing, func(ctx DialogTextContext, arg string) string {
+		return "hello"
+	})
+	RegisterDialogFlag("test_true", false, NeedsNothing, func(ctx DialogTextContext, arg string) bool {
+		return true
+	})
+}
+
+func TestInsertDialogVariables(t *testing.T) {
+	ctx := DialogTextContext{PlayerInfo: defs.PlayerInfo{PlayerName: "Anna"}}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"no variables", "Good day.", "Good day."},
+		{"variable", "Good day, {player_name}.", "Good day, Anna."},
+		{"repeated variable", "{player_name}? {player_name}!", "Anna? Anna!"},
+		{"flag with nested variable", "{?test_true|{test_greeting}, {player_name}|bye}.", "hello, Anna."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InsertDialogVariables(tt.text, ctx); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestInsertDialogVariablesDoesntRescanValues(t *testing.T) {
+	// a value that looks like a variable (e.g. a name the player typed) should be inserted as-is
+	ctx := DialogTextContext{PlayerInfo: defs.PlayerInfo{PlayerName: "{test_greeting}"}}
+	got := InsertDialogVariables("I'm {player_name}. {test_greeting}!", ctx)
+	expected := "I'm {test_greeting}. hello!"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
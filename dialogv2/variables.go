@@ -0,0 +1,196 @@
+package dialogv2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	"github.com/webbben/2d-game-engine/item"
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// DialogTextContext is what dialog variables have access to when they are filled in.
+// Dialog text is also used outside of dialogs (e.g. books), so World and Dialog may not always be set.
+type DialogTextContext struct {
+	PlayerInfo defs.PlayerInfo
+	Dataman    *datamanager.DataManager
+	World      defs.WorldInfoContext // OPT: not set for things like books
+	Dialog     *DialogContext        // OPT: only set when in a dialog
+}
+
+// DialogTextNeeds is what a dialog variable needs from its DialogTextContext, besides the player info and data manager.
+// It's checked when text is loaded, so text can't use variables that won't be available where it's shown (e.g. books).
+type DialogTextNeeds int
+
+const (
+	NeedsNothing DialogTextNeeds = iota // works anywhere, including books
+	NeedsWorld                          // needs world info (ctx.World)
+	NeedsDialog                         // only works in dialogs (ctx.Dialog)
+)
+
+// checkNeeds makes sure the context has what a variable needs. Text is validated against this at load time, so this is just a safety net.
+func (ctx DialogTextContext) checkNeeds(varName string, needs DialogTextNeeds) {
+	switch needs {
+	case NeedsWorld:
+		if ctx.World == nil {
+			logz.Panicln("DialogTextContext", "dialog variable needs world info, but none is available here:", varName)
+		}
+	case NeedsDialog:
+		if ctx.Dialog == nil {
+			logz.Panicln("DialogTextContext", "dialog variable can only be used in dialogs:", varName)
+		}
+	}
+}
+
+type (
+	// DialogVariableFunc gets the text to insert for a dialog variable. arg is only set for variables that take an arg.
+	DialogVariableFunc func(ctx DialogTextContext, arg string) string
+	// DialogFlagFunc decides which option of a conditional fragment to use.
+	DialogFlagFunc func(ctx DialogTextContext, arg string) bool
+)
+
+type dialogVariable struct {
+	fn    DialogVariableFunc
+	needs DialogTextNeeds
+}
+
+type dialogFlag struct {
+	fn    DialogFlagFunc
+	needs DialogTextNeeds
+}
+
+var (
+	dialogVariables = make(map[string]dialogVariable)
+	dialogFlags     = make(map[string]dialogFlag)
+)
+
+func varSpec(isFlag, takesArg bool, needs DialogTextNeeds) defs.DialogTextVarSpec {
+	return defs.DialogTextVarSpec{
+		IsFlag:      isFlag,
+		TakesArg:    takesArg,
+		NeedsWorld:  needs == NeedsWorld,
+		NeedsDialog: needs == NeedsDialog,
+	}
+}
+
+// RegisterDialogVariable adds a variable that can be used in dialog text as "{name}" (or "{name:arg}", if takesArg is set).
+// Like other registries, this should be done before any dialog is loaded (e.g. in an init function), since dialog text is validated at load time.
+func RegisterDialogVariable(name string, takesArg bool, needs DialogTextNeeds, fn DialogVariableFunc) {
+	if fn == nil {
+		logz.Panicln("RegisterDialogVariable", "func was nil:", name)
+	}
+	defs.RegisterDialogTextVarSpec(name, varSpec(false, takesArg, needs))
+	dialogVariables[name] = dialogVariable{fn: fn, needs: needs}
+}
+
+// RegisterDialogFlag adds a flag that can be used in dialog text for conditional fragments, as "{?name|if true|if false}"
+// (or "{?name:arg|if true|if false}", if takesArg is set). The "if false" option can be left out, in which case nothing is inserted.
+func RegisterDialogFlag(name string, takesArg bool, needs DialogTextNeeds, fn DialogFlagFunc) {
+	if fn == nil {
+		logz.Panicln("RegisterDialogFlag", "func was nil:", name)
+	}
+	defs.RegisterDialogTextVarSpec(name, varSpec(true, takesArg, needs))
+	dialogFlags[name] = dialogFlag{fn: fn, needs: needs}
+}
+
+func argInt(name, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		logz.Panicln("DialogTextContext", "dialog variable arg should be an int:", name, arg)
+	}
+	return n
+}
+
+func init() {
+	RegisterDialogVariable("player_name", false, NeedsNothing, func(ctx DialogTextContext, arg string) string {
+		return ctx.PlayerInfo.PlayerName
+	})
+	RegisterDialogVariable("player_culture", false, NeedsNothing, func(ctx DialogTextContext, arg string) string {
+		return ctx.Dataman.GetCultureDef(ctx.PlayerInfo.PlayerCulture).DisplayName
+	})
+	RegisterDialogVariable("player_gold", false, NeedsNothing, func(ctx DialogTextContext, arg string) string {
+		playerState := ctx.Dataman.GetCharacterState(id.CharacterStateID(defs.PlayerID))
+		return strconv.Itoa(item.CountMoney(playerState.StandardInventory, ctx.Dataman))
+	})
+	RegisterDialogVariable("item_name", true, NeedsNothing, func(ctx DialogTextContext, arg string) string {
+		return ctx.Dataman.GetItemDef(defs.ItemID(arg)).Name
+	})
+	RegisterDialogVariable("npc_name", false, NeedsDialog, func(ctx DialogTextContext, arg string) string {
+		return ctx.Dataman.GetCharacterState(ctx.Dialog.GetNPCCharStateID()).DisplayName
+	})
+	RegisterDialogVariable("map_name", false, NeedsWorld, func(ctx DialogTextContext, arg string) string {
+		return ctx.World.GetActiveMapDef().DisplayName
+	})
+	RegisterDialogVariable("time_of_day", false, NeedsWorld, func(ctx DialogTextContext, arg string) string {
+		return string(ctx.World.GetCurrentGameTime().TimeOfDay())
+	})
+	RegisterDialogVariable("day_of_week", false, NeedsWorld, func(ctx DialogTextContext, arg string) string {
+		return string(ctx.World.GetDayOfWeek())
+	})
+	// "{memory_value:<key>}": a value recorded in dialog memory, e.g. text the player entered in a text input action
+	RegisterDialogVariable("memory_value", true, NeedsDialog, func(ctx DialogTextContext, arg string) string {
+		val, _ := ctx.Dialog.GetMemoryValue(arg)
+		return val
+	})
+	// "{quest_value:<questID>/<placeholder>}": the value a placeholder was given in a quest instance (see quest templates).
+	// If a template ID is given, the most recent active instance of that template is used.
+	RegisterDialogVariable("quest_value", true, NeedsDialog, func(ctx DialogTextContext, arg string) string {
+		questID, key, found := strings.Cut(arg, "/")
+		if !found {
+			logz.Panicln("quest_value", "arg should be <questID>/<placeholder>:", arg)
+		}
+		return ctx.Dialog.getQuestValue(defs.QuestID(questID), key)
+	})
+
+	RegisterDialogFlag("player_female", false, NeedsNothing, func(ctx DialogTextContext, arg string) bool {
+		return ctx.Dataman.GetCharacterDef(defs.PlayerID).Female
+	})
+	RegisterDialogFlag("npc_female", false, NeedsDialog, func(ctx DialogTextContext, arg string) bool {
+		npcState := ctx.Dataman.GetCharacterState(ctx.Dialog.GetNPCCharStateID())
+		return ctx.Dataman.GetCharacterDef(npcState.DefID).Female
+	})
+	// "{?player_rank:3|...}": the player's social rank is at least the given rank
+	RegisterDialogFlag("player_rank", true, NeedsNothing, func(ctx DialogTextContext, arg string) bool {
+		playerState := ctx.Dataman.GetCharacterState(id.CharacterStateID(defs.PlayerID))
+		return playerState.SocialRank >= defs.SocialRank(argInt("player_rank", arg))
+	})
+	// "{?npc_rank:3|...}": the NPC's social rank is at least the given rank
+	RegisterDialogFlag("npc_rank", true, NeedsDialog, func(ctx DialogTextContext, arg string) bool {
+		npcState := ctx.Dataman.GetCharacterState(ctx.Dialog.GetNPCCharStateID())
+		return npcState.SocialRank >= defs.SocialRank(argInt("npc_rank", arg))
+	})
+	RegisterDialogFlag("has_gold", true, NeedsNothing, func(ctx DialogTextContext, arg string) bool {
+		playerState := ctx.Dataman.GetCharacterState(id.CharacterStateID(defs.PlayerID))
+		return item.CountMoney(playerState.StandardInventory, ctx.Dataman) >= argInt("has_gold", arg)
+	})
+	RegisterDialogFlag("memory", true, NeedsDialog, func(ctx DialogTextContext, arg string) bool {
+		return ctx.Dialog.HasMemory(arg)
+	})
+}
+
+// getQuestValue gets the value of a placeholder in a quest instance.
+func (ctx DialogContext) getQuestValue(questID defs.QuestID, key string) string {
+	if ctx.questman == nil {
+		panic("questman was nil")
+	}
+	questState := ctx.questman.GetQuestState(questID)
+	if questState == nil {
+		active, _, _ := ctx.questman.GetAllQuestStates()
+		for i := range active {
+			if active[i].TemplateID == questID && (questState == nil || active[i].Instance > questState.Instance) {
+				questState = &active[i]
+			}
+		}
+	}
+	if questState == nil {
+		logz.Panicln("quest_value", "no quest (or active instance of a quest template) found:", questID)
+	}
+	val, exists := questState.Placeholders[key]
+	if !exists {
+		logz.Panicln("quest_value", fmt.Sprintf("quest %s has no value for placeholder %s", questState.DefID, key))
+	}
+	return val
+}
@@ -411,6 +411,36 @@ func AddKnowledge(topicID defs.TopicID, dataman *datamanager.DataManager, eventB
 	})
 }
 
+// SkillXPPerLevel is how much skill XP it takes to raise a skill's base level by one.
+// TODO: this should probably scale with the current level (and maybe skill category), but a flat amount works for now.
+const SkillXPPerLevel = 100
+
+// AddSkillXP adds XP to one of a character's skills. Every SkillXPPerLevel XP raises the skill's base level by one.
+func AddSkillXP(charState *state.CharacterState, skillID defs.SkillID, xp int) {
+	if charState == nil {
+		logz.Panic("charState was nil")
+	}
+	if skillID == "" {
+		logz.Panic("skillID was empty")
+	}
+	if xp <= 0 {
+		logz.Panicln("AddSkillXP", "xp must be positive:", skillID, xp)
+	}
+	if charState.SkillXP == nil {
+		charState.SkillXP = make(map[defs.SkillID]int)
+	}
+	if charState.BaseSkills == nil {
+		charState.BaseSkills = make(map[defs.SkillID]int)
+	}
+
+	charState.SkillXP[skillID] += xp
+	for charState.SkillXP[skillID] >= SkillXPPerLevel {
+		charState.SkillXP[skillID] -= SkillXPPerLevel
+		charState.BaseSkills[skillID]++
+		logz.Println("AddSkillXP", "skill level increased:", charState.ID, skillID, charState.BaseSkills[skillID])
+	}
+}
+
 func ActivateItem(itemState *state.ItemState, dataman *datamanager.DataManager, eventBus *pubsub.EventBus) {
 	if itemState == nil {
 		logz.Panic("itemState was nil")
@@ -99,6 +99,7 @@ type Entity struct {
 	stunTicks int
 
 	speechBubble *SpeechBubble
+	gesture      *gesture // if set, the entity is acting out a gesture (nodding, shaking its head, etc)
 
 	Light                      *lights.Light
 	LightOffsetX, LightOffsetY float32
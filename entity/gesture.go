@@ -0,0 +1,152 @@
+package entity
+
+import (
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/logz"
+	"github.com/webbben/2d-game-engine/model"
+)
+
+// GestureStep is one pose in a gesture, held for a number of ticks.
+type GestureStep struct {
+	// which way to face, relative to the way the entity was facing when the gesture started.
+	// 0 is the original direction, 1 is a quarter turn clockwise, -1 a quarter turn counter-clockwise, and 2 is facing the opposite way.
+	Turn  int
+	DipPx float64 // how far down (in unscaled pixels) to lower the body, for things like nodding and bowing.
+	Ticks int
+}
+
+// gestures are short sequences of facing changes and body dips; since bodies only have 4 directions and a handful of animations,
+// this is how things like nodding or shaking your head are acted out.
+var gestures = make(map[string][]GestureStep)
+
+func init() {
+	RegisterGesture(defs.GestureNod, []GestureStep{
+		{DipPx: 1, Ticks: 8}, {Ticks: 8}, {DipPx: 1, Ticks: 8}, {Ticks: 4},
+	})
+	RegisterGesture(defs.GestureShakeHead, []GestureStep{
+		{Turn: -1, Ticks: 10}, {Turn: 1, Ticks: 10}, {Turn: -1, Ticks: 10}, {Turn: 1, Ticks: 10}, {Ticks: 4},
+	})
+	RegisterGesture(defs.GestureBow, []GestureStep{
+		{DipPx: 1, Ticks: 6}, {DipPx: 3, Ticks: 40}, {DipPx: 1, Ticks: 6}, {Ticks: 4},
+	})
+	RegisterGesture(defs.GestureLookAround, []GestureStep{
+		{Turn: -1, Ticks: 30}, {Turn: 1, Ticks: 30}, {Turn: 2, Ticks: 20}, {Ticks: 4},
+	})
+}
+
+// RegisterGesture adds a gesture that entities can play (see PlayGesture). Should be done in an init function.
+func RegisterGesture(name string, steps []GestureStep) {
+	if name == "" {
+		panic("gesture name was empty")
+	}
+	if _, exists := gestures[name]; exists {
+		logz.Panicln("RegisterGesture", "gesture already registered:", name)
+	}
+	if len(steps) == 0 {
+		logz.Panicln("RegisterGesture", "gesture has no steps:", name)
+	}
+	for _, step := range steps {
+		if step.Ticks <= 0 {
+			logz.Panicln("RegisterGesture", "gesture steps must last at least one tick:", name)
+		}
+	}
+	gestures[name] = steps
+}
+
+// GestureExists checks if a gesture has been registered.
+func GestureExists(name string) bool {
+	_, exists := gestures[name]
+	return exists
+}
+
+type gesture struct {
+	steps     []GestureStep
+	step      int
+	ticks     int
+	direction byte // the direction the entity was facing when the gesture started
+}
+
+// PlayGesture starts playing a gesture, replacing any gesture that's already playing.
+func (e *Entity) PlayGesture(name string) {
+	steps, exists := gestures[name]
+	if !exists {
+		logz.Panicln("PlayGesture", "gesture not found:", name)
+	}
+	e.StopGesture()
+	e.gesture = &gesture{
+		steps:     steps,
+		direction: e.Movement.Direction,
+	}
+	e.applyGestureStep()
+}
+
+// StopGesture ends the current gesture (if any), putting the entity back the way it was facing before it started.
+func (e *Entity) StopGesture() {
+	if e.gesture == nil {
+		return
+	}
+	e.SetDirection(e.gesture.direction)
+	e.gesture = nil
+}
+
+func (e *Entity) IsGesturing() bool {
+	return e.gesture != nil
+}
+
+func (e *Entity) applyGestureStep() {
+	step := e.gesture.steps[e.gesture.step]
+	e.SetDirection(turnDirection(e.gesture.direction, step.Turn))
+}
+
+func (e *Entity) updateGesture() {
+	if e.gesture == nil {
+		return
+	}
+	if e.Movement.IsMoving {
+		// moving takes over the entity's facing, so just drop the gesture
+		e.gesture = nil
+		return
+	}
+	e.gesture.ticks++
+	if e.gesture.ticks < e.gesture.steps[e.gesture.step].Ticks {
+		return
+	}
+	e.gesture.ticks = 0
+	e.gesture.step++
+	if e.gesture.step >= len(e.gesture.steps) {
+		e.StopGesture()
+		return
+	}
+	e.applyGestureStep()
+}
+
+// gestureDip gets how far down the body is currently lowered by a gesture.
+func (e Entity) gestureDip() float64 {
+	if e.gesture == nil {
+		return 0
+	}
+	return e.gesture.steps[e.gesture.step].DipPx
+}
+
+// UpdateAppearance updates only the parts of the entity that are just for show (body animation, gestures, float text),
+// for times when the entity should look alive but not actually do anything, like while the player is in a dialog with it.
+func (e *Entity) UpdateAppearance() {
+	if !e.Loaded {
+		return
+	}
+	e.FloatMGMT.Update()
+	e.updateGesture()
+	e.Body.Update()
+}
+
+// turnDirection turns a direction by the given number of quarter turns (positive is clockwise).
+func turnDirection(dir byte, quarterTurns int) byte {
+	order := []byte{model.Directions.Up, model.Directions.Right, model.Directions.Down, model.Directions.Left}
+	for i, d := range order {
+		if d == dir {
+			return order[((i+quarterTurns)%4+4)%4]
+		}
+	}
+	logz.Panicln("turnDirection", "invalid direction:", dir)
+	return dir
+}
@@ -39,6 +39,7 @@ func (e Entity) drawPos(offsetX, offsetY float64) (drawX, drawY float64) {
 	rect := model.NewRect(0, 0, float64(dx), float64(dy))
 	drawX, drawY = rendering.GetRectDrawPos(rect, e.X, e.Y, offsetX, offsetY)
 	drawY -= 6 // move up a little, since we want the entity to look like its standing in the middle of the tile
+	drawY += e.gestureDip()
 	drawX *= config.GameScale
 	drawY *= config.GameScale
 	return drawX, drawY
@@ -63,6 +64,7 @@ func (e *Entity) Update() {
 	}
 
 	e.FloatMGMT.Update()
+	e.updateGesture()
 
 	e.SyncBodyToState()
 
@@ -0,0 +1,73 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/logz"
+	"github.com/webbben/2d-game-engine/pubsub"
+)
+
+// DefaultAutosaveEvents are the events that trigger an autosave, if config.AutosaveEvents isn't set.
+var DefaultAutosaveEvents = []defs.EventType{
+	pubsub.EventVisitMap,
+	pubsub.EventPlayerSleep,
+	pubsub.EventQuestCompleted,
+}
+
+func (g *Game) subscribeAutosaveEvents() {
+	if g.autosaveSubscribed {
+		return
+	}
+	g.autosaveSubscribed = true
+
+	events := config.AutosaveEvents
+	if events == nil {
+		events = DefaultAutosaveEvents
+	}
+	for _, eventType := range events {
+		g.EventBus.Subscribe(fmt.Sprintf("GAME_autosave_%s", eventType), eventType, func(e defs.Event) {
+			g.RequestAutosave(string(e.Type))
+		})
+	}
+}
+
+// RequestAutosave queues up an autosave, which is made as soon as it's safe to save (e.g. once a dialog or loading screen is over).
+// Requests that come too soon after the last autosave are ignored (see config.AutosaveMinInterval).
+func (g *Game) RequestAutosave(reason string) {
+	if !g.lastAutosave.IsZero() && time.Since(g.lastAutosave) < config.AutosaveMinInterval {
+		logz.Println("Autosave", "skipping autosave, since the last one was too recent:", reason)
+		return
+	}
+	if g.pendingAutosave != "" {
+		return
+	}
+	g.pendingAutosave = reason
+}
+
+func (g *Game) updateAutosave() {
+	if g.pendingAutosave == "" || !g.canSave() {
+		return
+	}
+	reason := g.pendingAutosave
+	g.pendingAutosave = ""
+	g.lastAutosave = time.Now()
+	g.saveGame(defs.SaveSlotAuto, reason)
+}
+
+// canSave tells if the game is in a state that can be saved; i.e. the player is in the game world, and not in the middle of something
+// like a dialog, scenario or map transition.
+func (g *Game) canSave() bool {
+	if g.gameStage != InGameWorld || g.World == nil || g.World.ActiveMap == nil {
+		return false
+	}
+	if g.TransitionManager.TransitionInProgress || g.World.BlockPlayerChanges {
+		return false
+	}
+	if g.World.ActiveMap.InScenario || g.World.ActiveMap.IsDialogActive() {
+		return false
+	}
+	return true
+}
@@ -125,6 +125,10 @@ func (g *Game) GetItemDef(itemID defs.ItemID) defs.ItemDef {
 	return g.Dataman.GetItemDef(itemID)
 }
 
+func (g *Game) GetSkillDef(skillID defs.SkillID) defs.SkillDef {
+	return g.Dataman.GetSkillDef(skillID)
+}
+
 func (g *Game) GetEntityAvatar(charStateID id.CharacterStateID, direction byte) *ebiten.Image {
 	g.requireWorld()
 
@@ -165,3 +169,8 @@ func (g *Game) GetDialogNPC() id.CharacterStateID {
 	g.requireWorld()
 	return g.World.GetDialogNPC()
 }
+
+func (g *Game) PlayEmote(charID id.CharacterStateID, emote defs.DialogEmote) {
+	g.requireWorld()
+	g.World.PlayEmote(charID, emote)
+}
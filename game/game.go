@@ -13,6 +13,7 @@ import (
 	"github.com/webbben/2d-game-engine/display"
 	"github.com/webbben/2d-game-engine/internal/debug"
 	"github.com/webbben/2d-game-engine/internal/lights"
+	"github.com/webbben/2d-game-engine/locale"
 	"github.com/webbben/2d-game-engine/logz"
 	"github.com/webbben/2d-game-engine/pubsub"
 	"github.com/webbben/2d-game-engine/quest"
@@ -62,6 +63,13 @@ type Game struct {
 	AudioManager  *audio.AudioManager
 	QuestManager  *quest.QuestManager
 	ScreenManager *screen.ScreenManager
+
+	autosaveSubscribed bool
+	pendingAutosave    string // if set, an autosave will be made (for this reason) as soon as it's safe to save
+	lastAutosave       time.Time
+
+	prevPlayTime     time.Duration // play time from previous sessions (i.e. from the loaded save)
+	playSessionStart time.Time
 }
 
 func ShowFullDebugReport() {
@@ -101,10 +109,57 @@ func InitialStartUp() error {
 	if err != nil {
 		return err
 	}
+	err = loadLocales()
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// loadLocales loads the locale string tables (if there are any) and switches to the locale saved in the settings.
+func loadLocales() error {
+	err := locale.SetDefaultLocale(config.DefaultLocale)
+	if err != nil {
+		return err
+	}
+	if config.FileExists(config.GameLocalesPath()) {
+		err = locale.LoadDir(config.GameLocalesPath())
+		if err != nil {
+			return err
+		}
+	}
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if settings.Locale == "" {
+		settings.Locale = config.DefaultLocale
+	}
+	err = locale.SetLocale(settings.Locale)
+	if err != nil {
+		// a missing locale shouldn't stop the game from starting; just use the default
+		logz.Warnln("SYSTEM", "failed to set locale from settings:", err)
+		return locale.SetLocale(config.DefaultLocale)
+	}
+	return nil
+}
+
+// SetLocale switches the game's text to the given locale, and saves it in the settings so it's used next time too.
+// Anything already on screen keeps its text until it's shown again.
+func (g *Game) SetLocale(tag string) error {
+	err := locale.SetLocale(tag)
+	if err != nil {
+		return err
+	}
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	settings.Locale = locale.CurrentLocale()
+	return config.SaveSettings(settings)
+}
+
 func (g *Game) RunGame() error {
 	err := ebiten.RunGame(g)
 	debug.ShowAllReports()
@@ -174,6 +229,9 @@ func (g *Game) InitializeGameWorld(initTime clock.GameTime) {
 		g,
 		playerMenuScreen,
 	)
+	g.subscribeAutosaveEvents()
+	g.prevPlayTime = 0
+	g.playSessionStart = time.Now()
 	debug.StopTimer("InitializeGameWorld")
 	debug.ShowAllReports()
 }
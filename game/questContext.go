@@ -0,0 +1,44 @@
+package game
+
+import (
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+)
+
+// All of these just pass through to the QuestConditionContext implementations in World.
+
+func (g *Game) GetPlayerItemCount(itemID defs.ItemID) int {
+	g.requireWorld()
+	return g.World.GetPlayerItemCount(itemID)
+}
+
+func (g *Game) GetPlayerGold() int {
+	g.requireWorld()
+	return g.World.GetPlayerGold()
+}
+
+func (g *Game) PlayerHasRole(roleID defs.RoleID) bool {
+	g.requireWorld()
+	return g.World.PlayerHasRole(roleID)
+}
+
+func (g *Game) GetNPCOpinionOfPlayer(npcID id.CharacterStateID) int {
+	g.requireWorld()
+	return g.World.GetNPCOpinionOfPlayer(npcID)
+}
+
+// GetTrackedQuestMarker gets a marker for where the player needs to go for the tracked quest's current stage.
+// Returns false if there's no tracked quest, its stage has no target, or the target can't be located right now.
+func (g *Game) GetTrackedQuestMarker() (defs.QuestMarker, bool) {
+	g.requireWorld()
+	questID, target, ok := g.QuestManager.GetTrackedQuestTarget()
+	if !ok {
+		return defs.QuestMarker{}, false
+	}
+	marker, found := g.World.LocateQuestTarget(target)
+	if !found {
+		return defs.QuestMarker{}, false
+	}
+	marker.QuestID = questID
+	return marker, true
+}
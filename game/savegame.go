@@ -1,14 +1,28 @@
 package game
 
 import (
+	"image"
+	"time"
+
 	"github.com/webbben/2d-game-engine/config"
 	"github.com/webbben/2d-game-engine/data/defs"
 	"github.com/webbben/2d-game-engine/data/savegame"
 	"github.com/webbben/2d-game-engine/logz"
 	"github.com/webbben/2d-game-engine/model"
+	"github.com/webbben/2d-game-engine/quest"
 )
 
+// SaveGame makes a manual save.
 func (g Game) SaveGame() (saveFilePath string) {
+	return g.saveGame(defs.SaveSlotManual, "")
+}
+
+// QuickSave saves to the quicksave slot, replacing the previous quicksave.
+func (g Game) QuickSave() (saveFilePath string) {
+	return g.saveGame(defs.SaveSlotQuick, "")
+}
+
+func (g Game) saveGame(slot defs.SaveSlotType, label string) (saveFilePath string) {
 	mapCoords := g.World.Player.Entity.TilePos()
 	if g.World.Player.Entity.IsSleeping {
 		// if the player is in a bed, get the position they'd be standing in if they left the bed
@@ -20,6 +34,11 @@ func (g Game) SaveGame() (saveFilePath string) {
 		logz.Panicln("SAVE", "map coords that were going to be saved are apparently a collision in the map!", mapCoords)
 	}
 
+	var thumbnail image.Image
+	if config.SaveThumbnailWidth > 0 {
+		thumbnail = g.World.ActiveMap.CaptureThumbnail(config.SaveThumbnailWidth)
+	}
+
 	return savegame.SaveGame(
 		g.Dataman,
 		g.QuestManager,
@@ -27,18 +46,37 @@ func (g Game) SaveGame() (saveFilePath string) {
 		g.World.Clock.GetCurrentGameTime(),
 		g.World.ActiveMap.MapID,
 		mapCoords,
+		savegame.SaveOptions{
+			Slot:      slot,
+			Label:     label,
+			PlayTime:  g.GetPlayTime(),
+			Thumbnail: thumbnail,
+		},
 	)
 }
 
+// GetPlayTime gets the total time spent playing the current character, across all sessions.
+func (g Game) GetPlayTime() time.Duration {
+	if g.playSessionStart.IsZero() {
+		return g.prevPlayTime
+	}
+	return g.prevPlayTime + time.Since(g.playSessionStart)
+}
+
 // LoadGame loads a save file, sets up the game world/map, and places the player into that map.
-// After calling this, the game should start to run in the game world.
+// After calling this, the game should start to run in the game world. If the game world is already running, it's replaced.
 func (g *Game) LoadGame(saveFilePath string) {
+	if g.World != nil {
+		g.unloadGameWorld()
+	}
+
 	worldInfo, err := savegame.LoadSave(saveFilePath, g.Dataman, g.QuestManager, g.EventBus)
 	if err != nil {
 		logz.Panicln("LoadGame", "failed to load save. err:", err)
 	}
 
 	g.InitializeGameWorld(worldInfo.CurrentTime)
+	g.prevPlayTime = worldInfo.PlayTime
 
 	x := worldInfo.CurrentMapCoords.X * config.TileSize
 	y := worldInfo.CurrentMapCoords.Y * config.TileSize
@@ -47,6 +85,49 @@ func (g *Game) LoadGame(saveFilePath string) {
 	g.gameStage = InGameWorld
 }
 
+// unloadGameWorld shuts down the running game world, and clears out all the states that were loaded or created for it,
+// so that a save can be loaded in its place. Defs stay loaded.
+func (g *Game) unloadGameWorld() {
+	logz.Println("GAME", "Unloading game world...")
+	g.World.Close()
+	g.World = nil
+
+	g.EventBus.Reset(quest.SubscriberID)
+	g.autosaveSubscribed = false
+	g.pendingAutosave = ""
+
+	g.Dataman.ResetStates()
+	g.QuestManager.ResetStates()
+}
+
 func (g Game) GetAllExistingCharacters() []defs.ExistingCharacterInfo {
 	return savegame.GetAllExistingCharacters()
 }
+
+// QuickLoad loads the latest quicksave. Returns false if there's no quicksave to load.
+//
+// In the game world, this is the current character's latest quicksave, and the world is rebuilt from it.
+// Otherwise (e.g. from the main menu), it's the latest quicksave of the most recently played character.
+func (g *Game) QuickLoad() bool {
+	var uniqueID defs.UniquePlayerID
+	if g.World != nil {
+		uniqueID = g.Dataman.GetCharacterDef(defs.PlayerID).UniquePlayerID
+	} else {
+		var latest defs.ExistingCharacterInfo
+		for _, char := range g.GetAllExistingCharacters() {
+			if char.RecentSave.LastPlay.After(latest.RecentSave.LastPlay) {
+				latest = char
+			}
+		}
+		uniqueID = latest.UniquePlayerID
+	}
+	if uniqueID == "" {
+		return false
+	}
+	saveFilePath, found := savegame.GetLatestSave(uniqueID, defs.SaveSlotQuick)
+	if !found {
+		return false
+	}
+	g.LoadGame(saveFilePath)
+	return true
+}
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/webbben/2d-game-engine/config"
 	"github.com/webbben/2d-game-engine/internal/debug"
 	"github.com/webbben/2d-game-engine/logz"
@@ -64,6 +65,25 @@ func (g *Game) Update() error {
 		if !g.TransitionManager.ShowingLoadingScreen {
 			g.hud.Update(g)
 		}
+
+		if config.QuickSaveEnabled && inpututil.IsKeyJustPressed(config.QuickSaveKey) {
+			if g.canSave() {
+				g.QuickSave()
+			} else {
+				logz.Println("QuickSave", "can't save right now")
+			}
+		}
+		if config.QuickLoadEnabled && inpututil.IsKeyJustPressed(config.QuickLoadKey) {
+			// loading has to wait for the same things as saving, so the world isn't torn down in the middle of a dialog or transition
+			if g.canSave() {
+				if !g.QuickLoad() {
+					logz.Println("QuickLoad", "no quicksave to load")
+				}
+			} else {
+				logz.Println("QuickLoad", "can't load right now")
+			}
+		}
+		g.updateAutosave()
 	default:
 		logz.Panicln("UPDATE", "Game stage was invalid! Game stage value:", g.gameStage)
 	}
@@ -35,6 +35,16 @@ func (g *Game) AddItem(itemID defs.ItemID, quantity int) {
 	g.World.AddItem(itemID, quantity)
 }
 
+func (g *Game) AddSkillXP(skillID defs.SkillID, xp int) {
+	g.requireWorld()
+	g.World.AddSkillXP(skillID, xp)
+}
+
+func (g *Game) AddKnowledge(topicID defs.TopicID) {
+	g.requireWorld()
+	g.World.AddKnowledge(topicID)
+}
+
 func (g *Game) AssignTaskToNPC(id defs.CharacterDefID, taskDef defs.TaskDef, requireListener bool) {
 	g.requireWorld()
 	g.World.AssignTaskToNPC(id, taskDef, requireListener)
@@ -67,6 +77,11 @@ func (g Game) GetCurrentGameTime() clock.GameTime {
 	return g.World.GetCurrentGameTime()
 }
 
+func (g Game) GetDayOfWeek() clock.DayOfWeek {
+	g.requireWorld()
+	return g.World.GetDayOfWeek()
+}
+
 func (g *Game) AddRole(roleID defs.RoleID) {
 	g.requireWorld()
 	g.World.AddRole(roleID)
@@ -0,0 +1,280 @@
+// Package locale handles translating player-visible text.
+//
+// Strings are looked up by stable keys in per-locale tables (see LoadDir for the file format). If the active locale doesn't
+// have a string, its parent locale is tried (e.g. "pt-BR" falls back to "pt"), then the default locale, and finally the source
+// text given by the caller - which is usually the English text written in the data defs. So untranslated strings always
+// show *something*.
+//
+// Most keys are derived from def IDs, so nothing special needs to be done in the data:
+//
+//	item.<item id>.name, item.<item id>.description
+//	book.<book id>.title, book.<book id>.text
+//	quest.<quest id>.name, quest.<quest id>.description
+//	quest.<quest id>.<stage id>.title (and .objective, .description)
+//	topic.<topic id>.prompt
+//
+// Text that has no ID of its own (like dialog responses and replies) can reference a key directly by being written as
+// "@<key>", e.g. Text: "@blacksmith.greeting.rude". UI strings use keys starting with "ui.".
+package locale
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Key identifies a localized string. Keys should be stable; don't change them once strings have been translated.
+type Key string
+
+// RefPrefix marks text that is a reference to a localization key instead of literal text.
+const RefPrefix = "@"
+
+var (
+	mu         sync.RWMutex
+	tables     = make(map[language.Tag]table)
+	defaultTag = language.English
+	currentTag = language.English
+	printer    = message.NewPrinter(language.English)
+)
+
+// SetDefaultLocale sets the locale that is used when the active locale doesn't have a string.
+func SetDefaultLocale(tag string) error {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return fmt.Errorf("invalid locale %q: %w", tag, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	defaultTag = t
+	return nil
+}
+
+// SetLocale switches the active locale. This can be done at any time; text that is already on screen (like an open book)
+// won't change, but anything shown after this will use the new locale.
+func SetLocale(tag string) error {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return fmt.Errorf("invalid locale %q: %w", tag, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !hasTable(t) && t != defaultTag {
+		return fmt.Errorf("no strings have been loaded for locale %q", tag)
+	}
+	currentTag = t
+	printer = message.NewPrinter(t)
+	return nil
+}
+
+// CurrentLocale gets the active locale.
+func CurrentLocale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return currentTag.String()
+}
+
+// DefaultLocale gets the default (fallback) locale.
+func DefaultLocale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultTag.String()
+}
+
+// Locales gets all the locales that have strings loaded, sorted.
+func Locales() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := []string{}
+	for t := range tables {
+		out = append(out, t.String())
+	}
+	slices.Sort(out)
+	return out
+}
+
+// hasTable checks if a locale, or any of its parents, has strings loaded (e.g. "pt-BR" can use a "pt" table).
+// Must hold the read lock.
+func hasTable(t language.Tag) bool {
+	for ; ; t = t.Parent() {
+		if _, exists := tables[t]; exists {
+			return true
+		}
+		if t == language.Und {
+			return false
+		}
+	}
+}
+
+// lookup finds the entry for a key, going from the active locale to its parents, then the default locale and its parents.
+// Also gives the locale the entry was found in, since that's the one whose plural rules apply to it.
+// Must hold the read lock.
+func lookup(key Key) (entry, language.Tag, bool) {
+	for _, start := range []language.Tag{currentTag, defaultTag} {
+		for t := start; ; t = t.Parent() {
+			if e, exists := tables[t][key]; exists {
+				return e, t, true
+			}
+			if t == language.Und {
+				break
+			}
+		}
+	}
+	return entry{}, language.Und, false
+}
+
+// Lookup gets the string for a key, if any locale has it.
+func Lookup(key Key) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, _, found := lookup(key)
+	if !found {
+		return "", false
+	}
+	return e.text(plural.Other), true
+}
+
+// Text gets the localized string for a key, or the given source text if there isn't one.
+func Text(key Key, source string) string {
+	if s, found := Lookup(key); found {
+		return s
+	}
+	return source
+}
+
+// T gets the localized string for a key (or source if there isn't one), formatted with the given args.
+// Formatting uses the active locale's number formatting, so %d of 1000 shows "1,000" in English.
+// Without args, the text isn't formatted at all, so a plain "%" in it shows as-is.
+func T(key Key, source string, args ...any) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	s := source
+	if e, _, found := lookup(key); found {
+		s = e.text(plural.Other)
+	}
+	if len(args) == 0 {
+		return s
+	}
+	return printer.Sprintf(s, args...)
+}
+
+// Plural gets the right plural form of a localized string for the count n, formatted with n as the first arg
+// (followed by any other args). one and other are the source text used when no locale has the key, e.g.
+//
+//	locale.Plural("ui.coins", n, "%d coin", "%d coins")
+//
+// The plural form is picked by the rules of the locale the string came from, so a fallback to an English string
+// still gets English plurals.
+func Plural(key Key, n int, one, other string, args ...any) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var s string
+	if e, tag, found := lookup(key); found {
+		s = e.text(pluralForm(tag, n))
+	} else if n == 1 {
+		s = one
+	} else {
+		s = other
+	}
+	return printer.Sprintf(s, append([]any{n}, args...)...)
+}
+
+func pluralForm(t language.Tag, n int) plural.Form {
+	if n < 0 {
+		n = -n
+	}
+	return plural.Cardinal.MatchPlural(t, n, 0, 0, 0, 0)
+}
+
+// RefKey gets the key that a piece of text refers to, if it's a reference (e.g. "@some.key").
+func RefKey(s string) (Key, bool) {
+	if !strings.HasPrefix(s, RefPrefix) || len(s) == len(RefPrefix) || strings.ContainsAny(s, " \n") {
+		return "", false
+	}
+	return Key(strings.TrimPrefix(s, RefPrefix)), true
+}
+
+// Resolve localizes text that might be a reference to a key ("@some.key"). Anything else is returned as is.
+// References to keys that no locale has are returned as is too, so the missing key is easy to spot in game.
+func Resolve(s string) string {
+	key, isRef := RefKey(s)
+	if !isRef {
+		return s
+	}
+	return Text(key, s)
+}
+
+// Keys gets all the keys that a locale has its own strings for (not counting fallbacks), sorted.
+func Keys(tag string) ([]Key, error) {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locale %q: %w", tag, err)
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	keys := []Key{}
+	for key := range tables[t] {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// MissingKeys gets the given keys that a locale doesn't have its own strings for, sorted.
+func MissingKeys(tag string, keys []Key) ([]Key, error) {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locale %q: %w", tag, err)
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	missing := []Key{}
+	for _, key := range keys {
+		if _, exists := tables[t][key]; !exists {
+			missing = append(missing, key)
+		}
+	}
+	slices.Sort(missing)
+	return slices.Compact(missing), nil
+}
+
+// Untranslated gets the strings that a locale is missing, along with the text they have in the default locale, ready to be translated.
+// The strings checked are everything in the default locale's tables, plus the given source keys (e.g. from DataManager.LocalizationKeys),
+// whose source text is used if the default locale doesn't have them. Plural strings only give their "other" form.
+func Untranslated(tag string, sources map[Key]string) (map[Key]string, error) {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locale %q: %w", tag, err)
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make(map[Key]string)
+	for key, source := range sources {
+		if _, exists := tables[t][key]; !exists {
+			out[key] = source
+		}
+	}
+	if t == defaultTag {
+		return out, nil
+	}
+	for key, e := range tables[defaultTag] {
+		if _, exists := tables[t][key]; !exists {
+			out[key] = e.text(plural.Other)
+		}
+	}
+	return out, nil
+}
+
+// Reset unloads all strings and goes back to English as the default and active locale. Mainly for tests.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	tables = make(map[language.Tag]table)
+	defaultTag = language.English
+	currentTag = language.English
+	printer = message.NewPrinter(language.English)
+}
@@ -0,0 +1,136 @@
+package locale
+
+import "testing"
+
+func TestFallbackAndPlurals(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if err := LoadTable("en", []byte(`{"ui.close": "Close", "ui.coins": {"one": "%d coin", "other": "%d coins"}, "ui.apples": {"one": "%d apple", "other": "%d apples"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadTable("fr", []byte(`{"ui.coins": {"one": "%d pièce", "other": "%d pièces"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadTable("pt", []byte(`{"ui.close": "Fechar"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetLocale("fr"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Text("ui.close", "source"); got != "Close" {
+		t.Errorf("expected fallback to default locale, got %q", got)
+	}
+	if got := Text("ui.missing", "source"); got != "source" {
+		t.Errorf("expected source text for missing key, got %q", got)
+	}
+	// french uses the "one" form for 0 as well
+	for n, want := range map[int]string{0: "0 pièce", 1: "1 pièce", 5: "5 pièces", 1000: "1 000 pièces"} {
+		if got := Plural("ui.coins", n, "%d coin", "%d coins"); got != want {
+			t.Errorf("Plural(%d) = %q, want %q", n, got, want)
+		}
+	}
+
+	// strings that fall back to the default locale use its plural rules, not french ones
+	for n, want := range map[int]string{0: "0 apples", 1: "1 apple"} {
+		if got := Plural("ui.apples", n, "%d apple", "%d apples"); got != want {
+			t.Errorf("Plural(%d) = %q, want %q", n, got, want)
+		}
+	}
+
+	// regional locales fall back to their parent, even if they have no table of their own
+	if err := SetLocale("pt-BR"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Resolve("@ui.close"); got != "Fechar" {
+		t.Errorf("expected parent locale string, got %q", got)
+	}
+	if got := Resolve("not a @reference"); got != "not a @reference" {
+		t.Errorf("expected plain text to be unchanged, got %q", got)
+	}
+
+	if err := SetLocale("de"); err == nil {
+		t.Error("expected error switching to a locale with no strings")
+	}
+
+	missing, err := MissingKeys("fr", []Key{"ui.close", "ui.coins"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "ui.close" {
+		t.Errorf("unexpected missing keys: %v", missing)
+	}
+
+	untranslated, err := Untranslated("fr", map[Key]string{"item.sword.name": "Sword", "ui.coins": "%d coins"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(untranslated) != 3 || untranslated["ui.close"] != "Close" || untranslated["item.sword.name"] != "Sword" {
+		t.Errorf("unexpected untranslated strings: %v", untranslated)
+	}
+}
+
+func TestLoadTableErrors(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if err := LoadTable("en", []byte(`{"ui.coins": {"one": "%d coin"}}`)); err == nil {
+		t.Error("expected error for plural without other form")
+	}
+	if err := LoadTable("en", []byte(`{"ui.coins": {"single": "%d coin", "other": "x"}}`)); err == nil {
+		t.Error("expected error for unknown plural form")
+	}
+	if err := LoadTable("en", []byte(`{"ui.close": "Close"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadTable("en", []byte(`{"ui.close": "Close"}`)); err == nil {
+		t.Error("expected error for duplicate key")
+	}
+}
+
+func TestLoadTableIsAllOrNothing(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if err := LoadTable("en", []byte(`{"ui.close": "Close"}`)); err != nil {
+		t.Fatal(err)
+	}
+	// "ui.close" is a duplicate; sorted first or not, none of this table should get loaded
+	if err := LoadTable("en", []byte(`{"a.first": "A", "ui.close": "Close again", "z.last": "Z"}`)); err == nil {
+		t.Fatal("expected error for duplicate key")
+	}
+	for _, key := range []Key{"a.first", "z.last"} {
+		if _, found := Lookup(key); found {
+			t.Errorf("%s was loaded from a table that had an error", key)
+		}
+	}
+	if got := Text("ui.close", "source"); got != "Close" {
+		t.Errorf("existing string was changed by a table that had an error: %q", got)
+	}
+}
+
+func TestTWithoutArgs(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if err := LoadTable("en", []byte(`{"ui.discount": "50% off", "ui.gold": "%d gold"}`)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key    Key
+		source string
+		args   []any
+		want   string
+	}{
+		{"ui.discount", "", nil, "50% off"},
+		{"ui.missing", "100% sure", nil, "100% sure"},
+		{"ui.gold", "", []any{1000}, "1,000 gold"},
+		{"ui.missing", "%d%% done", []any{50}, "50% done"},
+	}
+	for _, tt := range tests {
+		if got := T(tt.key, tt.source, tt.args...); got != tt.want {
+			t.Errorf("T(%q, %q, %v) = %q, want %q", tt.key, tt.source, tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package localeextract finds the strings that a locale hasn't translated yet (see cmd/locale_extract).
+package localeextract
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/locale"
+	"github.com/webbben/2d-game-engine/quest"
+)
+
+// Main runs the locale_extract command (see cmd/locale_extract) and returns the exit code.
+// args are the command line arguments, without the program name.
+//
+// Besides the default locale's tables, the strings of every item, book, dialog and quest def that's loaded into dataman and questMgr
+// are included, so games that define those in Go can make their own extract command by loading their defs first:
+//
+//	func main() {
+//		dataman := datamanager.NewDataManager()
+//		questMgr := quest.NewQuestManager(eventBus, world)
+//		mygame.LoadDefs(dataman, questMgr)
+//		os.Exit(localeextract.Main(dataman, questMgr, os.Args[1:]))
+//	}
+//
+// questMgr can be nil if there are no quests. If a -dialog directory is given, the dialog scripts in it are loaded into dataman too.
+func Main(dataman *datamanager.DataManager, questMgr *quest.QuestManager, args []string) int {
+	if dataman == nil {
+		panic("dataman was nil")
+	}
+	cmd := flag.NewFlagSet("locale_extract", flag.ContinueOnError)
+	localesDir := cmd.String("locales", "", "directory of locale tables")
+	tag := cmd.String("locale", "", "locale to find untranslated strings for (e.g. fr)")
+	defaultTag := cmd.String("default", "en", "default locale, that the source text is written in")
+	dialogDir := cmd.String("dialog", "", "OPT: directory of dialog scripts to include strings from")
+	outFile := cmd.String("out", "", "OPT: file to write to. if not set, writes to stdout")
+	if err := cmd.Parse(args); err != nil {
+		return 2
+	}
+
+	if *localesDir == "" || *tag == "" {
+		fmt.Println("Error: locales and locale arguments are required")
+		cmd.Usage()
+		return 1
+	}
+
+	if err := locale.SetDefaultLocale(*defaultTag); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if err := locale.LoadDir(*localesDir); err != nil {
+		fmt.Println("Error: failed to load locale tables:", err)
+		return 1
+	}
+
+	if *dialogDir != "" {
+		if err := dataman.LoadDialogScripts(*dialogDir); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+	sources := dataman.LocalizationKeys()
+	if questMgr != nil {
+		maps.Copy(sources, questMgr.LocalizationKeys())
+	}
+
+	untranslated, err := locale.Untranslated(*tag, sources)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+
+	// keys that are only referenced in dialog, but don't exist in any table yet, have no text to translate from
+	noSource := 0
+	for _, s := range untranslated {
+		if s == "" {
+			noSource++
+		}
+	}
+
+	data, err := json.MarshalIndent(untranslated, "", "  ")
+	if err != nil {
+		fmt.Println("Error: failed to marshal JSON:", err)
+		return 1
+	}
+	if *outFile == "" {
+		fmt.Println(string(data))
+	} else if err := os.WriteFile(*outFile, data, 0o644); err != nil {
+		fmt.Println("Error: failed to write output file:", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "%d untranslated strings for %s", len(untranslated), *tag)
+	if noSource > 0 {
+		fmt.Fprintf(os.Stderr, " (%d are referenced in dialog, but not in the default locale either)", noSource)
+	}
+	fmt.Fprintln(os.Stderr)
+	return 0
+}
@@ -0,0 +1,56 @@
+package localeextract
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/locale"
+)
+
+func TestMainIncludesLoadedDefs(t *testing.T) {
+	locale.Reset()
+	defer locale.Reset()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "en.json"), []byte(`{"ui.close": "Close"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fr.json"), []byte(`{"item.sword.name": "Épée"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// items defined in Go, that are only in the data manager and not in any table
+	dataman := datamanager.NewDataManager()
+	dataman.ItemDefs["sword"] = defs.ItemDef{ID: "sword", Name: "Sword", Description: "A sword."}
+	dataman.BookDefs["diary"] = defs.BookDef{ID: "diary", Title: "Diary"}
+
+	out := filepath.Join(dir, "fr.todo.json")
+	if code := Main(dataman, nil, []string{"-locales", dir, "-locale", "fr", "-out", out}); code != 0 {
+		t.Fatalf("expected exit code 0, got %v", code)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var untranslated map[string]string
+	if err := json.Unmarshal(data, &untranslated); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"ui.close":               "Close",
+		"item.sword.description": "A sword.",
+		"book.diary.title":       "Diary",
+	}
+	if len(untranslated) != len(want) {
+		t.Errorf("expected %v untranslated strings, got: %v", len(want), untranslated)
+	}
+	for key, text := range want {
+		if untranslated[key] != text {
+			t.Errorf("%s: expected %q, got %q", key, text, untranslated[key])
+		}
+	}
+}
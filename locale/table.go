@@ -0,0 +1,128 @@
+package locale
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// TableExt is the file extension of locale tables.
+const TableExt = ".json"
+
+// entry is a single localized string. Plural strings have a form for each plural category the language uses.
+type entry struct {
+	forms map[plural.Form]string
+}
+
+func (e entry) text(form plural.Form) string {
+	if s, exists := e.forms[form]; exists {
+		return s
+	}
+	return e.forms[plural.Other]
+}
+
+type table map[Key]entry
+
+var pluralForms = map[string]plural.Form{
+	"zero":  plural.Zero,
+	"one":   plural.One,
+	"two":   plural.Two,
+	"few":   plural.Few,
+	"many":  plural.Many,
+	"other": plural.Other,
+}
+
+// LoadDir loads every locale table in a directory. Each file is a JSON object of keys to strings, and is named after its locale.
+// A locale can be split over several files by adding a suffix after a dot, e.g. "fr.json", "fr.items.json" and "fr.dialog.json".
+// Plural strings are objects with a string for each plural form the language uses (zero, one, two, few, many, other); "other" is required:
+//
+//	{
+//	  "item.iron_sword.name": "Épée en fer",
+//	  "ui.coins": { "one": "%d pièce", "other": "%d pièces" }
+//	}
+func LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read locale directory: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != TableExt {
+			continue
+		}
+		tag, _, _ := strings.Cut(strings.TrimSuffix(e.Name(), TableExt), ".")
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read locale table %s: %w", e.Name(), err)
+		}
+		if err := LoadTable(tag, data); err != nil {
+			return fmt.Errorf("%s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// LoadTable loads a locale table from JSON (see LoadDir for the format). Strings are added to any that are already loaded for the locale;
+// a key that is loaded twice is an error. If there's an error, nothing from the table is loaded.
+func LoadTable(tag string, data []byte) error {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return fmt.Errorf("invalid locale %q: %w", tag, err)
+	}
+	raw := make(map[Key]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse locale table: %w", err)
+	}
+
+	loaded := make(table)
+	for _, key := range slices.Sorted(maps.Keys(raw)) {
+		e, err := parseEntry(raw[key])
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+		loaded[key] = e
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// check the whole table before adding any of it, so a bad table doesn't leave the locale half-loaded
+	for _, key := range slices.Sorted(maps.Keys(loaded)) {
+		if _, exists := tables[t][key]; exists {
+			return fmt.Errorf("key %q was already loaded for locale %s", key, t)
+		}
+	}
+	if tables[t] == nil {
+		tables[t] = make(table)
+	}
+	maps.Copy(tables[t], loaded)
+	return nil
+}
+
+func parseEntry(raw json.RawMessage) (entry, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return entry{forms: map[plural.Form]string{plural.Other: s}}, nil
+	}
+	var forms map[string]string
+	if err := json.Unmarshal(raw, &forms); err != nil {
+		return entry{}, fmt.Errorf("value should be a string, or an object of plural forms")
+	}
+	e := entry{forms: make(map[plural.Form]string)}
+	for name, s := range forms {
+		form, valid := pluralForms[name]
+		if !valid {
+			return entry{}, fmt.Errorf("unknown plural form %q", name)
+		}
+		e.forms[form] = s
+	}
+	if _, exists := e.forms[plural.Other]; !exists {
+		return entry{}, fmt.Errorf("plural strings need an \"other\" form")
+	}
+	return e, nil
+}
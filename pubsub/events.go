@@ -23,7 +23,12 @@ const (
 
 	// Quests
 
-	EventQuestStarted defs.EventType = "quest_started" // a quest is started by the player
+	// Quest event data keys are defined in the quest package (e.g. quest.QuestIDKey).
+
+	EventQuestStarted      defs.EventType = "quest_started"       // a quest is started by the player. data: "QUEST_ID"
+	EventQuestStageChanged defs.EventType = "quest_stage_changed" // a quest moved to a new stage. data: "QUEST_ID", "OLD_STAGE" (empty if the quest just started), "NEW_STAGE", "TERMINAL_STATUS"
+	EventQuestCompleted    defs.EventType = "quest_completed"     // data: "QUEST_ID", "OLD_STAGE" (the stage it was completed at), "TERMINAL_STATUS"
+	EventQuestFailed       defs.EventType = "quest_failed"        // data: "QUEST_ID", "OLD_STAGE" (the stage it was failed at), "TERMINAL_STATUS"
 
 	// Dialog & Topics
 
@@ -31,6 +36,7 @@ const (
 	EventDialogEnded   defs.EventType = "dialog_ended"
 
 	EventNewKnowledgeTopic defs.EventType = "new_knowledge_topic" // data: "topicID" (string), "topicDisplayName" (string)
+	EventDialogSkillCheck  defs.EventType = "dialog_skill_check"  // data: "checkID" (string), "skillID" (defs.SkillID), "passed" (bool)
 
 	// Objects
 
@@ -45,6 +51,7 @@ const (
 
 	EventRoleAdded   defs.EventType = "role_added"   // data: "roleID" (string)
 	EventRoleRemoved defs.EventType = "role_removed" // data: "roleID" (string)
+	EventPlayerSleep defs.EventType = "player_sleep" // the player got into a bed
 
 	// Entity Interactions
 
@@ -63,6 +70,10 @@ const (
 // e.g. event.Data[DataKey]
 const DataKey string = "struct"
 
+func init() {
+	defs.RegisterPayloadType("pubsub.EventObjectActivatedData", EventObjectActivatedData{})
+}
+
 type EventObjectActivatedData struct {
 	ObjectType  string // the type of object that was activated
 	ActivatorID string // who activated the object
@@ -3,6 +3,7 @@ package pubsub
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/webbben/2d-game-engine/clock"
 	"github.com/webbben/2d-game-engine/data/defs"
@@ -183,6 +184,10 @@ func (eb *EventBus) ScheduleFutureEvent(e defs.Event, futureTime clock.GameTime)
 	if e.Type == EventScheduleFutureEvent {
 		logz.Panicln("QueueFutureEvent", "cannot queue a future event that is also a 'queue future event' type.", e)
 	}
+	// scheduled events are saved with the game, so make sure they can be
+	if err := e.CheckPayloads(); err != nil {
+		logz.Panicln("QueueFutureEvent", "scheduled event can't be saved:", err)
+	}
 	if _, exists := eb.futureEventSchedule[futureTime]; !exists {
 		eb.futureEventSchedule[futureTime] = []defs.Event{}
 	}
@@ -202,6 +207,27 @@ func (eb *EventBus) FireScheduledEvents(currentTime clock.GameTime) {
 	}
 }
 
+// Reset removes all subscriptions (except for the given subscriber IDs), scheduled future events, and queued events.
+// Used when the game world is thrown away, e.g. to load a save while already in the game world.
+func (eb *EventBus) Reset(keepSubscribers ...string) {
+	logz.Println("EVENT BUS", "resetting event bus. keeping subscribers:", keepSubscribers)
+
+	for subID := range eb.alreadySubscribed {
+		if !slices.Contains(keepSubscribers, subID) {
+			eb.Unsubscribe(subID)
+		}
+	}
+	eb.futureEventSchedule = make(map[clock.GameTime][]defs.Event)
+
+	for {
+		select {
+		case <-eb.queue:
+		default:
+			return
+		}
+	}
+}
+
 // Unsubscribe removes an event subscription. Panics if the subscriber ID isn't registered, so only use this if you are sure
 // the subscription exists.
 func (eb *EventBus) Unsubscribe(subID string) {
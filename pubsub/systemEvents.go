@@ -12,8 +12,8 @@ const (
 	SysEventChangeMapOccupancy defs.EventType = "SYS_CHANGE_MAP_OCCUPANCY"
 
 	// data:
-	// 	- "type" (string) the name of the WorldEffect
-	// 	- "effect" (any) the actual struct data for the WorldEffect
+	// 	- "type" (string) OPT: the name of the WorldEffect, for logging
+	// 	- "effect" (defs.WorldEffect) the effect to apply. to survive saving, its type must be registered with defs.RegisterPayloadType.
 	SysScheduledWorldEffect defs.EventType = "SYS_SCHED_WORLD_EFFECT"
 
 	// fires when a time lapse has occurred. should never be fired by anything other than the game engine; game projects can listen for this event
@@ -40,6 +40,10 @@ func (eb *EventBus) SubscribeToWorldEvents(subscriberID string, fn func(defs.Eve
 	}
 }
 
+func init() {
+	defs.RegisterPayloadType("pubsub.SysEventChangeMapOccupancyParams", SysEventChangeMapOccupancyParams{})
+}
+
 type SysEventChangeMapOccupancyParams struct {
 	CharacterStateID id.CharacterStateID
 	From, To         defs.MapID
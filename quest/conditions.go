@@ -0,0 +1,221 @@
+package quest
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// Built-in quest condition types.
+// The params each of them takes are listed next to them; params marked OPT can be left out.
+const (
+	ConditionEventDataEquals   defs.QuestConditionType = "event_data_equals"   // "key", "value": event data at key (printed as a string) equals value
+	ConditionEventDataContains defs.QuestConditionType = "event_data_contains" // "key", "value": event data at key contains value (substring, or element if it's a slice)
+	ConditionPlayerHasItem     defs.QuestConditionType = "player_has_item"     // "item_id", "quantity" (OPT, default 1)
+	ConditionPlayerHasGold     defs.QuestConditionType = "player_has_gold"     // "amount": player has at least this much gold
+	ConditionPlayerHasRole     defs.QuestConditionType = "player_has_role"     // "role_id"
+	ConditionQuestStatus       defs.QuestConditionType = "quest_status"        // "quest_id", "status" (ACTIVE, COMPLETED, FAILED, NOT_STARTED)
+	ConditionQuestStage        defs.QuestConditionType = "quest_stage"         // "quest_id", "stage_id": quest is currently at this stage
+	ConditionTimeWindow        defs.QuestConditionType = "time_window"         // "start_hour", "end_hour": start inclusive, end exclusive. can wrap past midnight.
+	ConditionCurrentMap        defs.QuestConditionType = "current_map"         // "map_id": player is currently in this map
+	ConditionCurrentRegion     defs.QuestConditionType = "current_region"      // "region_id": player is currently in this region
+	ConditionNPCOpinion        defs.QuestConditionType = "npc_opinion"         // "npc_id", "min" (OPT), "max" (OPT): NPC's opinion of the player is within [min, max]
+)
+
+// Quest condition param keys
+const (
+	ParamKey       string = "key"
+	ParamValue     string = "value"
+	ParamItemID    string = "item_id"
+	ParamQuantity  string = "quantity"
+	ParamAmount    string = "amount"
+	ParamRoleID    string = "role_id"
+	ParamQuestID   string = "quest_id"
+	ParamStatus    string = "status"
+	ParamStageID   string = "stage_id"
+	ParamStartHour string = "start_hour"
+	ParamEndHour   string = "end_hour"
+	ParamMapID     string = "map_id"
+	ParamRegionID  string = "region_id"
+	ParamNPCID     string = "npc_id"
+	ParamMin       string = "min"
+	ParamMax       string = "max"
+)
+
+// ConditionContext is what a ConditionEvaluator gets to work with when checking a condition.
+type ConditionContext struct {
+	QuestID defs.QuestID // the quest whose start trigger or stage reaction is being checked
+	Event   defs.Event   // the event that caused the check
+	World   defs.GameQuestContext
+
+	qm *QuestManager
+}
+
+// GetQuestStage gets the current stage and status of any quest (not just the one being checked).
+func (ctx ConditionContext) GetQuestStage(qid defs.QuestID) (defs.QuestStageDef, defs.QuestStatus) {
+	return ctx.qm.GetQuestStage(qid)
+}
+
+// ConditionEvaluator decides if a quest condition passes. Params have already been validated against the
+// condition type's spec by the time this is called, so required params can be assumed to exist.
+type ConditionEvaluator func(ctx ConditionContext, params map[string]string) bool
+
+var conditionEvaluators = make(map[defs.QuestConditionType]ConditionEvaluator)
+
+// RegisterConditionType registers a quest condition type, along with the function that evaluates it.
+// Game projects can use this to add their own condition types; it should be done before any quest defs are loaded,
+// since quest def validation will reject condition types it doesn't know about.
+func RegisterConditionType(condType defs.QuestConditionType, spec defs.QuestConditionSpec, eval ConditionEvaluator) {
+	if eval == nil {
+		logz.Panicln("RegisterConditionType", "evaluator was nil:", condType)
+	}
+	defs.RegisterQuestConditionSpec(condType, spec)
+	conditionEvaluators[condType] = eval
+}
+
+func init() {
+	RegisterConditionType(ConditionEventDataEquals, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamKey, ParamValue},
+	}, evalEventDataEquals)
+	RegisterConditionType(ConditionEventDataContains, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamKey, ParamValue},
+	}, evalEventDataContains)
+	RegisterConditionType(ConditionPlayerHasItem, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamItemID},
+		IntParams:      []string{ParamQuantity},
+	}, evalPlayerHasItem)
+	RegisterConditionType(ConditionPlayerHasGold, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamAmount},
+		IntParams:      []string{ParamAmount},
+	}, evalPlayerHasGold)
+	RegisterConditionType(ConditionPlayerHasRole, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamRoleID},
+	}, evalPlayerHasRole)
+	RegisterConditionType(ConditionQuestStatus, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamQuestID, ParamStatus},
+	}, evalQuestStatus)
+	RegisterConditionType(ConditionQuestStage, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamQuestID, ParamStageID},
+	}, evalQuestStage)
+	RegisterConditionType(ConditionTimeWindow, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamStartHour, ParamEndHour},
+		IntParams:      []string{ParamStartHour, ParamEndHour},
+	}, evalTimeWindow)
+	RegisterConditionType(ConditionCurrentMap, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamMapID},
+	}, evalCurrentMap)
+	RegisterConditionType(ConditionCurrentRegion, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamRegionID},
+	}, evalCurrentRegion)
+	RegisterConditionType(ConditionNPCOpinion, defs.QuestConditionSpec{
+		RequiredParams: []string{ParamNPCID},
+		IntParams:      []string{ParamMin, ParamMax},
+	}, evalNPCOpinion)
+}
+
+// paramInt gets an int param, or the default value if the param isn't set.
+func paramInt(params map[string]string, key string, defaultVal int) int {
+	val, exists := params[key]
+	if !exists {
+		return defaultVal
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		logz.Panicln("QuestCondition", "param couldn't be parsed as int:", key, val)
+	}
+	return n
+}
+
+func evalEventDataEquals(ctx ConditionContext, params map[string]string) bool {
+	val, exists := ctx.Event.Data[params[ParamKey]]
+	if !exists {
+		return false
+	}
+	return fmt.Sprint(val) == params[ParamValue]
+}
+
+func evalEventDataContains(ctx ConditionContext, params map[string]string) bool {
+	val, exists := ctx.Event.Data[params[ParamKey]]
+	if !exists || val == nil {
+		return false
+	}
+	want := params[ParamValue]
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := range rv.Len() {
+			if fmt.Sprint(rv.Index(i).Interface()) == want {
+				return true
+			}
+		}
+		return false
+	}
+
+	return strings.Contains(fmt.Sprint(val), want)
+}
+
+func evalPlayerHasItem(ctx ConditionContext, params map[string]string) bool {
+	quantity := paramInt(params, ParamQuantity, 1)
+	return ctx.World.GetPlayerItemCount(defs.ItemID(params[ParamItemID])) >= quantity
+}
+
+func evalPlayerHasGold(ctx ConditionContext, params map[string]string) bool {
+	return ctx.World.GetPlayerGold() >= paramInt(params, ParamAmount, 0)
+}
+
+func evalPlayerHasRole(ctx ConditionContext, params map[string]string) bool {
+	return ctx.World.PlayerHasRole(defs.RoleID(params[ParamRoleID]))
+}
+
+func evalQuestStatus(ctx ConditionContext, params map[string]string) bool {
+	_, status := ctx.GetQuestStage(defs.QuestID(params[ParamQuestID]))
+	return status == defs.QuestStatus(params[ParamStatus])
+}
+
+func evalQuestStage(ctx ConditionContext, params map[string]string) bool {
+	stage, status := ctx.GetQuestStage(defs.QuestID(params[ParamQuestID]))
+	if status == NotStarted {
+		return false
+	}
+	return stage.ID == defs.QuestStageID(params[ParamStageID])
+}
+
+func evalTimeWindow(ctx ConditionContext, params map[string]string) bool {
+	start := paramInt(params, ParamStartHour, 0)
+	end := paramInt(params, ParamEndHour, 0)
+	hour := ctx.World.GetCurrentGameTime().Hour
+
+	if start <= end {
+		return hour >= start && hour < end
+	}
+	// window wraps past midnight (e.g. 22 -> 4)
+	return hour >= start || hour < end
+}
+
+func evalCurrentMap(ctx ConditionContext, params map[string]string) bool {
+	return ctx.World.GetMapID() == defs.MapID(params[ParamMapID])
+}
+
+func evalCurrentRegion(ctx ConditionContext, params map[string]string) bool {
+	if ctx.World.GetMapID() == "" {
+		// not in a map right now
+		return false
+	}
+	return ctx.World.GetActiveMapDef().Region == defs.RegionID(params[ParamRegionID])
+}
+
+func evalNPCOpinion(ctx ConditionContext, params map[string]string) bool {
+	opinion := ctx.World.GetNPCOpinionOfPlayer(id.CharacterStateID(params[ParamNPCID]))
+	if _, exists := params[ParamMin]; exists && opinion < paramInt(params, ParamMin, 0) {
+		return false
+	}
+	if _, exists := params[ParamMax]; exists && opinion > paramInt(params, ParamMax, 0) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package quest
+
+import (
+	"testing"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+func TestEventDataConditions(t *testing.T) {
+	event := defs.Event{
+		Type: "test_event",
+		Data: map[string]any{
+			"itemID":   defs.ItemID("wolf_pelt"),
+			"quantity": 3,
+			"tags":     []string{"forest", "night"},
+		},
+	}
+	ctx := ConditionContext{Event: event}
+
+	tests := []struct {
+		name     string
+		eval     ConditionEvaluator
+		params   map[string]string
+		expected bool
+	}{
+		{"equals string type", evalEventDataEquals, map[string]string{ParamKey: "itemID", ParamValue: "wolf_pelt"}, true},
+		{"equals int", evalEventDataEquals, map[string]string{ParamKey: "quantity", ParamValue: "3"}, true},
+		{"equals wrong value", evalEventDataEquals, map[string]string{ParamKey: "itemID", ParamValue: "bear_pelt"}, false},
+		{"equals missing key", evalEventDataEquals, map[string]string{ParamKey: "mapID", ParamValue: "wolf_pelt"}, false},
+		{"contains substring", evalEventDataContains, map[string]string{ParamKey: "itemID", ParamValue: "pelt"}, true},
+		{"contains slice element", evalEventDataContains, map[string]string{ParamKey: "tags", ParamValue: "night"}, true},
+		{"contains slice partial element", evalEventDataContains, map[string]string{ParamKey: "tags", ParamValue: "nig"}, false},
+		{"contains missing key", evalEventDataContains, map[string]string{ParamKey: "mapID", ParamValue: "x"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.eval(ctx, tt.params); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConditionValidate(t *testing.T) {
+	expectPanic := func(name string, cond defs.QuestConditionDef) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected validation to panic", name)
+			}
+		}()
+		cond.Validate()
+	}
+
+	expectPanic("unknown type", defs.QuestConditionDef{Type: "not_a_real_condition"})
+	expectPanic("missing param", defs.QuestConditionDef{Type: ConditionPlayerHasItem})
+	expectPanic("bad int param", defs.QuestConditionDef{
+		Type:   ConditionPlayerHasGold,
+		Params: map[string]string{ParamAmount: "fifty"},
+	})
+
+	// valid condition with an optional param left out
+	defs.QuestConditionDef{
+		Type:   ConditionPlayerHasItem,
+		Params: map[string]string{ParamItemID: "wolf_pelt"},
+	}.Validate()
+}
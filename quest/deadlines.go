@@ -0,0 +1,43 @@
+package quest
+
+import (
+	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// handleTimePass checks all active quests for stage deadlines that have expired.
+// Time lapses also publish a time pass event (with the new time), so any deadlines that were skipped past will fire here too.
+func (qm *QuestManager) handleTimePass(event defs.Event) {
+	gameTime, ok := event.Data["gameTime"].(clock.GameTime)
+	if !ok {
+		logz.Panicln("QuestManager", "time pass event didn't have game time:", event.Data)
+	}
+	qm.fireExpiredDeadlines(gameTime)
+}
+
+// fireExpiredDeadlines moves every active quest whose stage deadline is at or before the given time to its fallback stage.
+func (qm *QuestManager) fireExpiredDeadlines(now clock.GameTime) {
+	// collect first, since changing stages can move quests out of the active bucket
+	expired := []defs.QuestID{}
+	for questID, questState := range qm.active {
+		if questState.Deadline == nil {
+			continue
+		}
+		if now.IsAfter(*questState.Deadline) || now.IsEqual(*questState.Deadline) {
+			expired = append(expired, questID)
+		}
+	}
+
+	for _, questID := range expired {
+		stage := qm.GetActiveQuestStage(questID)
+		if stage.Deadline == nil {
+			// the quest def must have changed since this state was saved
+			logz.Warnln("QuestManager", "quest state has a deadline, but its stage doesn't; clearing it:", questID, stage.ID)
+			qm.GetActiveQuestState(questID).Deadline = nil
+			continue
+		}
+		logz.Println("QuestManager", "quest stage deadline expired:", questID, stage.ID, "->", stage.Deadline.FallbackStage)
+		qm.SetQuestStage(questID, stage.Deadline.FallbackStage)
+	}
+}
@@ -0,0 +1,216 @@
+package quest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+type QuestGraphErrorKind int
+
+const (
+	GraphErrDanglingRef QuestGraphErrorKind = iota // a stage transition points to a stage that doesn't exist
+	GraphErrUnreachable                            // a stage can never be reached from the start stage
+	GraphErrDeadEnd                                // a stage can be reached, but no path from it ever reaches a terminal stage (e.g. a cycle with no way out)
+)
+
+func (k QuestGraphErrorKind) String() string {
+	switch k {
+	case GraphErrDanglingRef:
+		return "dangling reference"
+	case GraphErrUnreachable:
+		return "unreachable stage"
+	case GraphErrDeadEnd:
+		return "dead end"
+	default:
+		return fmt.Sprintf("QuestGraphErrorKind(%d)", int(k))
+	}
+}
+
+// QuestGraphError is a problem found in the stage graph of a quest.
+type QuestGraphError struct {
+	QuestID defs.QuestID
+	StageID defs.QuestStageID
+	Kind    QuestGraphErrorKind
+	Msg     string
+}
+
+func (e QuestGraphError) Error() string {
+	return fmt.Sprintf("quest %s, stage %s: %s: %s", e.QuestID, e.StageID, e.Kind, e.Msg)
+}
+
+// questGraphEdge is a possible transition from one quest stage to another.
+type questGraphEdge struct {
+	from  defs.QuestStageID
+	to    defs.QuestStageID
+	label string // what causes the transition; used for the DOT export
+}
+
+// stageEdges gets all the transitions out of a stage: reactions, finishing its objectives, and its deadline expiring.
+func stageEdges(stage defs.QuestStageDef) []questGraphEdge {
+	edges := []questGraphEdge{}
+	for _, reaction := range stage.Reactions {
+		edges = append(edges, questGraphEdge{from: stage.ID, to: reaction.NextStage, label: string(reaction.SubscribeEvent)})
+	}
+	if len(stage.Objectives) > 0 {
+		edges = append(edges, questGraphEdge{from: stage.ID, to: stage.ObjectivesNextStage, label: "objectives"})
+	}
+	if stage.Deadline != nil {
+		edges = append(edges, questGraphEdge{from: stage.ID, to: stage.Deadline.FallbackStage, label: "deadline"})
+	}
+	return edges
+}
+
+// sortedStageIDs gets the stage IDs of a quest in a stable order, so that errors and DOT output come out the same every time.
+func sortedStageIDs(questDef defs.QuestDef) []defs.QuestStageID {
+	ids := make([]defs.QuestStageID, 0, len(questDef.Stages))
+	for stageID := range questDef.Stages {
+		ids = append(ids, stageID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// ValidateQuestGraph checks the stage graph of a quest for dangling stage references, stages that can't be reached from the start stage,
+// and stages that can never lead to a terminal stage. Unlike QuestDef.Validate, it doesn't panic; it returns all the problems it finds,
+// so they can be reviewed all at once.
+func ValidateQuestGraph(questDef defs.QuestDef) []QuestGraphError {
+	errs := []QuestGraphError{}
+	stageIDs := sortedStageIDs(questDef)
+
+	if _, exists := questDef.Stages[questDef.StartStage]; !exists {
+		errs = append(errs, QuestGraphError{
+			QuestID: questDef.ID,
+			StageID: questDef.StartStage,
+			Kind:    GraphErrDanglingRef,
+			Msg:     "start stage doesn't exist",
+		})
+		// nothing is reachable, so the other checks wouldn't tell us anything useful
+		return errs
+	}
+
+	// build the graph (forward and reverse), skipping edges that point nowhere
+	next := make(map[defs.QuestStageID][]defs.QuestStageID)
+	prev := make(map[defs.QuestStageID][]defs.QuestStageID)
+	for _, stageID := range stageIDs {
+		for _, edge := range stageEdges(questDef.Stages[stageID]) {
+			if _, exists := questDef.Stages[edge.to]; !exists {
+				errs = append(errs, QuestGraphError{
+					QuestID: questDef.ID,
+					StageID: stageID,
+					Kind:    GraphErrDanglingRef,
+					Msg:     fmt.Sprintf("%s transition points to stage that doesn't exist: %q", edge.label, edge.to),
+				})
+				continue
+			}
+			next[stageID] = append(next[stageID], edge.to)
+			prev[edge.to] = append(prev[edge.to], stageID)
+		}
+	}
+
+	reachable := walkStages([]defs.QuestStageID{questDef.StartStage}, next)
+
+	terminals := []defs.QuestStageID{}
+	for _, stageID := range stageIDs {
+		if questDef.Stages[stageID].TerminalStatus != TerminalStatusNone {
+			terminals = append(terminals, stageID)
+		}
+	}
+	canFinish := walkStages(terminals, prev)
+
+	for _, stageID := range stageIDs {
+		if !reachable[stageID] {
+			errs = append(errs, QuestGraphError{
+				QuestID: questDef.ID,
+				StageID: stageID,
+				Kind:    GraphErrUnreachable,
+				Msg:     "no path from the start stage leads here",
+			})
+			continue
+		}
+		if !canFinish[stageID] {
+			errs = append(errs, QuestGraphError{
+				QuestID: questDef.ID,
+				StageID: stageID,
+				Kind:    GraphErrDeadEnd,
+				Msg:     "no path from here leads to a terminal stage; the quest could get stuck",
+			})
+		}
+	}
+
+	return errs
+}
+
+// walkStages does a breadth first walk over the given graph, and returns every stage visited (including the starting ones).
+func walkStages(start []defs.QuestStageID, graph map[defs.QuestStageID][]defs.QuestStageID) map[defs.QuestStageID]bool {
+	visited := make(map[defs.QuestStageID]bool)
+	queue := []defs.QuestStageID{}
+	for _, stageID := range start {
+		if !visited[stageID] {
+			visited[stageID] = true
+			queue = append(queue, stageID)
+		}
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, n := range graph[cur] {
+			if !visited[n] {
+				visited[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return visited
+}
+
+// QuestGraphDOT exports the stage graph of a quest in Graphviz DOT format, e.g. for design reviews.
+// Render it with something like: dot -Tpng quest.dot -o quest.png
+//
+// The start stage is drawn bold, completing stages green and failing stages red.
+// Transitions to stages that don't exist are drawn as dashed edges to a red "missing" node.
+func QuestGraphDOT(questDef defs.QuestDef) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", string(questDef.ID))
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box];\n")
+
+	for _, stageID := range sortedStageIDs(questDef) {
+		stage := questDef.Stages[stageID]
+		label := string(stageID)
+		if stage.Title != "" {
+			label += "\\n" + stage.Title
+		}
+		attrs := []string{fmt.Sprintf("label=\"%s\"", escapeDOT(label))}
+		if stageID == questDef.StartStage {
+			attrs = append(attrs, "style=bold")
+		}
+		switch stage.TerminalStatus {
+		case TerminalStatusComplete:
+			attrs = append(attrs, "shape=doubleoctagon", "color=darkgreen")
+		case TerminalStatusFail:
+			attrs = append(attrs, "shape=doubleoctagon", "color=red")
+		}
+		fmt.Fprintf(&b, "\t%q [%s];\n", string(stageID), strings.Join(attrs, ", "))
+	}
+
+	for _, stageID := range sortedStageIDs(questDef) {
+		for _, edge := range stageEdges(questDef.Stages[stageID]) {
+			if _, exists := questDef.Stages[edge.to]; !exists {
+				fmt.Fprintf(&b, "\t%q [label=\"missing: %s\", color=red, style=dashed];\n", "missing:"+string(edge.to), escapeDOT(string(edge.to)))
+				fmt.Fprintf(&b, "\t%q -> %q [label=\"%s\", style=dashed, color=red];\n", string(edge.from), "missing:"+string(edge.to), escapeDOT(edge.label))
+				continue
+			}
+			fmt.Fprintf(&b, "\t%q -> %q [label=\"%s\"];\n", string(edge.from), string(edge.to), escapeDOT(edge.label))
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func escapeDOT(s string) string {
+	return strings.ReplaceAll(s, "\"", "\\\"")
+}
@@ -0,0 +1,115 @@
+package quest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+func testStage(id defs.QuestStageID, terminal defs.QuestTerminalStatus, next ...defs.QuestStageID) defs.QuestStageDef {
+	stage := defs.QuestStageDef{ID: id, TerminalStatus: terminal}
+	for _, n := range next {
+		stage.Reactions = append(stage.Reactions, defs.QuestReactionDef{SubscribeEvent: "test_event", NextStage: n})
+	}
+	return stage
+}
+
+func testQuest(stages ...defs.QuestStageDef) defs.QuestDef {
+	d := defs.QuestDef{ID: "test_quest", StartStage: "start", Stages: make(map[defs.QuestStageID]defs.QuestStageDef)}
+	for _, s := range stages {
+		d.Stages[s.ID] = s
+	}
+	return d
+}
+
+func TestValidateQuestGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		quest    defs.QuestDef
+		expected map[defs.QuestStageID]QuestGraphErrorKind
+	}{
+		{
+			"valid branching quest",
+			testQuest(
+				testStage("start", TerminalStatusNone, "middle", "fail"),
+				testStage("middle", TerminalStatusNone, "done"),
+				testStage("done", TerminalStatusComplete),
+				testStage("fail", TerminalStatusFail),
+			),
+			map[defs.QuestStageID]QuestGraphErrorKind{},
+		},
+		{
+			"dangling next stage",
+			testQuest(
+				testStage("start", TerminalStatusNone, "done", "nowhere"),
+				testStage("done", TerminalStatusComplete),
+			),
+			map[defs.QuestStageID]QuestGraphErrorKind{"start": GraphErrDanglingRef},
+		},
+		{
+			"unreachable stage",
+			testQuest(
+				testStage("start", TerminalStatusNone, "done"),
+				testStage("orphan", TerminalStatusNone, "done"),
+				testStage("done", TerminalStatusComplete),
+			),
+			map[defs.QuestStageID]QuestGraphErrorKind{"orphan": GraphErrUnreachable},
+		},
+		{
+			"cycle with no way out",
+			testQuest(
+				testStage("start", TerminalStatusNone, "loop_a", "done"),
+				testStage("loop_a", TerminalStatusNone, "loop_b"),
+				testStage("loop_b", TerminalStatusNone, "loop_a"),
+				testStage("done", TerminalStatusComplete),
+			),
+			map[defs.QuestStageID]QuestGraphErrorKind{"loop_a": GraphErrDeadEnd, "loop_b": GraphErrDeadEnd},
+		},
+		{
+			"deadline fallback is a way out of a cycle",
+			func() defs.QuestDef {
+				loop := testStage("loop", TerminalStatusNone, "start")
+				loop.Deadline = &defs.QuestDeadlineDef{Hours: 24, FallbackStage: "fail"}
+				return testQuest(
+					testStage("start", TerminalStatusNone, "loop"),
+					loop,
+					testStage("fail", TerminalStatusFail),
+				)
+			}(),
+			map[defs.QuestStageID]QuestGraphErrorKind{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateQuestGraph(tt.quest)
+			if len(errs) != len(tt.expected) {
+				t.Fatalf("expected %v errors, got %v: %v", len(tt.expected), len(errs), errs)
+			}
+			for _, err := range errs {
+				kind, exists := tt.expected[err.StageID]
+				if !exists || kind != err.Kind {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestQuestGraphDOT(t *testing.T) {
+	dot := QuestGraphDOT(testQuest(
+		testStage("start", TerminalStatusNone, "done", "nowhere"),
+		testStage("done", TerminalStatusComplete),
+	))
+
+	for _, want := range []string{
+		`digraph "test_quest" {`,
+		`"start" -> "done" [label="test_event"];`,
+		`"start" -> "missing:nowhere"`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected DOT output to contain %q, got:\n%s", want, dot)
+		}
+	}
+}
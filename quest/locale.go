@@ -0,0 +1,77 @@
+package quest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/locale"
+)
+
+// localizeQuestDef gets a copy of a quest def with its player-visible text in the active locale.
+// keyID is the quest ID used in the localization keys; for template instances this is the template's ID, and the replacer fills in
+// the instance's placeholders afterwards (since translations of a template have the same placeholders as the template itself).
+func localizeQuestDef(d defs.QuestDef, keyID defs.QuestID, r *strings.Replacer) defs.QuestDef {
+	text := func(key string, source string) string {
+		s, found := locale.Lookup(locale.Key(key))
+		if !found {
+			return source
+		}
+		if r != nil {
+			s = r.Replace(s)
+		}
+		return s
+	}
+
+	d.Name = text(fmt.Sprintf("quest.%s.name", keyID), d.Name)
+	d.Description = text(fmt.Sprintf("quest.%s.description", keyID), d.Description)
+
+	stages := make(map[defs.QuestStageID]defs.QuestStageDef)
+	for stageID, stage := range d.Stages {
+		prefix := fmt.Sprintf("quest.%s.%s.", keyID, stageID)
+		stage.Title = text(prefix+"title", stage.Title)
+		stage.Objective = text(prefix+"objective", stage.Objective)
+		stage.Description = text(prefix+"description", stage.Description)
+
+		if len(stage.Objectives) > 0 {
+			objectives := []defs.QuestObjectiveDef{}
+			for _, obj := range stage.Objectives {
+				obj.Text = text(fmt.Sprintf("%sobjectives.%s", prefix, obj.ID), obj.Text)
+				objectives = append(objectives, obj)
+			}
+			stage.Objectives = objectives
+		}
+		stages[stageID] = stage
+	}
+	d.Stages = stages
+
+	return d
+}
+
+// LocalizationKeys gets the localization keys of all the loaded quest defs, along with their source (untranslated) text.
+// Template instances are skipped, since they share their template's keys.
+func (qm QuestManager) LocalizationKeys() map[locale.Key]string {
+	keys := make(map[locale.Key]string)
+	add := func(key string, source string) {
+		if source != "" {
+			keys[locale.Key(key)] = source
+		}
+	}
+	for _, d := range qm.questDefs {
+		if strings.Contains(string(d.ID), defs.QuestInstanceSeparator) {
+			continue
+		}
+		add(fmt.Sprintf("quest.%s.name", d.ID), d.Name)
+		add(fmt.Sprintf("quest.%s.description", d.ID), d.Description)
+		for stageID, stage := range d.Stages {
+			prefix := fmt.Sprintf("quest.%s.%s.", d.ID, stageID)
+			add(prefix+"title", stage.Title)
+			add(prefix+"objective", stage.Objective)
+			add(prefix+"description", stage.Description)
+			for _, obj := range stage.Objectives {
+				add(fmt.Sprintf("%sobjectives.%s", prefix, obj.ID), obj.Text)
+			}
+		}
+	}
+	return keys
+}
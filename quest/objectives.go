@@ -0,0 +1,147 @@
+package quest
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/state"
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// ObjectiveProgress is an objective's text along with how far along the player is; meant for showing in a journal UI.
+// The progress itself has Complete(), and String() for showing it in a "3/5" format.
+type ObjectiveProgress struct {
+	ID   defs.QuestObjectiveID
+	Text string
+	state.QuestObjectiveProgress
+}
+
+// newObjectivesProgress creates fresh (zeroed) progress for all of a stage's objectives.
+func newObjectivesProgress(stageDef defs.QuestStageDef) map[defs.QuestObjectiveID]state.QuestObjectiveProgress {
+	if len(stageDef.Objectives) == 0 {
+		return nil
+	}
+	progress := make(map[defs.QuestObjectiveID]state.QuestObjectiveProgress)
+	for _, obj := range stageDef.Objectives {
+		progress[obj.ID] = state.QuestObjectiveProgress{
+			Required: obj.RequiredCount(),
+		}
+	}
+	return progress
+}
+
+// GetObjectiveProgress gets the progress of each objective in an active quest's current stage, in the order they are defined.
+// Returns nil if the quest isn't active, or its current stage has no objectives.
+func (qm QuestManager) GetObjectiveProgress(questID defs.QuestID) []ObjectiveProgress {
+	if qm.GetQuestStatus(questID) != Active {
+		return nil
+	}
+	stage := qm.GetActiveQuestStage(questID)
+	questState := qm.GetActiveQuestState(questID)
+
+	var out []ObjectiveProgress
+	for _, obj := range stage.Objectives {
+		progress, exists := questState.Objectives[obj.ID]
+		if !exists {
+			progress.Required = obj.RequiredCount()
+		}
+		out = append(out, ObjectiveProgress{
+			ID:                     obj.ID,
+			Text:                   obj.Text,
+			QuestObjectiveProgress: progress,
+		})
+	}
+	return out
+}
+
+// updateObjectives counts the event towards any matching objectives of the quest's current stage,
+// and moves the quest to the next stage if that finishes the objectives.
+func (qm *QuestManager) updateObjectives(questID defs.QuestID, stage defs.QuestStageDef, event defs.Event) {
+	if len(stage.Objectives) == 0 {
+		return
+	}
+	questState := qm.GetActiveQuestState(questID)
+	if questState.Objectives == nil {
+		// e.g. a quest state from a save made before this stage had objectives
+		questState.Objectives = newObjectivesProgress(stage)
+	}
+
+	changed := false
+	for _, obj := range stage.Objectives {
+		if obj.CountEvent != event.Type {
+			continue
+		}
+		progress, exists := questState.Objectives[obj.ID]
+		if !exists {
+			progress.Required = obj.RequiredCount()
+		}
+		if progress.Complete() {
+			continue
+		}
+		if !qm.conditionsMet(obj.Conditions, questID, event) {
+			continue
+		}
+
+		if obj.DistinctKey != "" {
+			val, exists := event.Data[obj.DistinctKey]
+			if !exists {
+				logz.Warnln("QuestManager", "objective distinct key not found in event data:", obj.DistinctKey, "quest:", questID, "objective:", obj.ID)
+				continue
+			}
+			s := fmt.Sprint(val)
+			if slices.Contains(progress.Counted, s) {
+				continue
+			}
+			progress.Counted = append(progress.Counted, s)
+		}
+
+		progress.Count += objectiveCountAmount(obj, event)
+		questState.Objectives[obj.ID] = progress
+		changed = true
+		logz.Println("QuestManager", "objective progress:", questID, obj.ID, progress.String())
+	}
+
+	if !changed {
+		return
+	}
+	if objectivesDone(stage, questState.Objectives) {
+		qm.SetQuestStage(questID, stage.ObjectivesNextStage)
+	}
+}
+
+// objectiveCountAmount gets how much an event counts towards an objective.
+func objectiveCountAmount(obj defs.QuestObjectiveDef, event defs.Event) int {
+	if obj.CountKey == "" {
+		return 1
+	}
+	val, exists := event.Data[obj.CountKey]
+	if !exists {
+		logz.Panicln("QuestManager", "objective count key not found in event data:", obj.CountKey, "objective:", obj.ID, "event:", event.Type)
+	}
+	// numbers can come back as float64 if the event went through JSON (e.g. scheduled events in a save file)
+	switch n := val.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	default:
+		logz.Panicln("QuestManager", "objective count key value is not a number:", obj.CountKey, val)
+	}
+	return 0
+}
+
+func objectivesDone(stage defs.QuestStageDef, progress map[defs.QuestObjectiveID]state.QuestObjectiveProgress) bool {
+	for _, obj := range stage.Objectives {
+		done := progress[obj.ID].Complete() && progress[obj.ID].Required > 0
+		if stage.AnyObjective && done {
+			return true
+		}
+		if !stage.AnyObjective && !done {
+			return false
+		}
+	}
+	return !stage.AnyObjective
+}
@@ -2,6 +2,9 @@
 package quest
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/webbben/2d-game-engine/data/defs"
 	"github.com/webbben/2d-game-engine/data/state"
 	"github.com/webbben/2d-game-engine/logz"
@@ -21,9 +24,15 @@ const (
 	NotStarted defs.QuestStatus = "NOT_STARTED"
 )
 
+// SubscriberID is the ID the quest manager subscribes to the event bus with.
+const SubscriberID = "QuestManager"
+
 // Quest event data keys
 const (
-	QuestIDKey string = "QUEST_ID"
+	QuestIDKey        string = "QUEST_ID"
+	OldStageKey       string = "OLD_STAGE"
+	NewStageKey       string = "NEW_STAGE"
+	TerminalStatusKey string = "TERMINAL_STATUS" // (defs.QuestTerminalStatus)
 )
 
 type QuestManager struct {
@@ -33,13 +42,17 @@ type QuestManager struct {
 	questDefs map[defs.QuestID]defs.QuestDef
 
 	startTriggersByEvent  map[defs.EventType][]defs.QuestID // maps event types to all quests that have start triggers that listen to that event
-	stageReactionsByEvent map[defs.EventType][]defs.QuestID // maps event types to all quests that have stage reactions that listen to that event
+	stageReactionsByEvent map[defs.EventType][]defs.QuestID // maps event types to all quests that have stage reactions or objectives that listen to that event
 
 	notStarted map[defs.QuestID]bool // tracks which quests have not started yet, and therefore should have their start triggers checked
 	active     map[defs.QuestID]*state.QuestState
 	completed  map[defs.QuestID]*state.QuestState
 	failed     map[defs.QuestID]*state.QuestState
 
+	templates map[defs.QuestID]*state.QuestTemplateState // for quest templates; how many instances have started, and when the last one ended
+
+	trackedQuest defs.QuestID // the quest the player has chosen to track in the HUD
+
 	world defs.GameQuestContext
 
 	eventBus *pubsub.EventBus
@@ -79,9 +92,11 @@ func NewQuestManager(eventBus *pubsub.EventBus, worldRef defs.GameQuestContext)
 		active:     make(map[defs.QuestID]*state.QuestState),
 		completed:  make(map[defs.QuestID]*state.QuestState),
 		failed:     make(map[defs.QuestID]*state.QuestState),
+
+		templates: make(map[defs.QuestID]*state.QuestTemplateState),
 	}
 
-	qm.eventBus.SubscribeAll("QuestManager", qm.OnEvent)
+	qm.eventBus.SubscribeAll(SubscriberID, qm.OnEvent)
 
 	return &qm
 }
@@ -96,6 +111,10 @@ func (qm *QuestManager) OnEvent(event defs.Event) {
 
 	logz.Println("QuestManager:OnEvent", "event incoming:", event.Type)
 
+	if event.Type == pubsub.EventTimePass {
+		qm.handleTimePass(event)
+	}
+
 	// check for quest start triggers
 	notStartedQuests, exists := qm.startTriggersByEvent[event.Type]
 	if exists {
@@ -110,6 +129,11 @@ func (qm *QuestManager) OnEvent(event defs.Event) {
 				panic("the start trigger event type doesn't match... did we mess up the indexing somehow?")
 			}
 			if qm.conditionsMet(questDef.StartTrigger.Conditions, questID, event) {
+				if questDef.Template != nil {
+					// templates never leave the not started bucket; each time they trigger, a new instance is started instead
+					qm.startInstanceFromTrigger(questDef)
+					continue
+				}
 				qm.StartQuest(questID)
 				// NOTE: I considered if we should expect a single event to cause more than one quest to start.
 				// I think the answer is "yes": perhaps an event occurs where the player is now at a crossroads and needs to decide
@@ -127,12 +151,14 @@ func (qm *QuestManager) OnEvent(event defs.Event) {
 				continue
 			}
 			stage := qm.GetActiveQuestStage(questID)
+			reactionRan := false
 			for _, reaction := range stage.Reactions {
 				if reaction.SubscribeEvent != event.Type {
 					continue
 				}
 				if qm.conditionsMet(reaction.Conditions, questID, event) {
 					qm.RunReaction(questID, reaction, event)
+					reactionRan = true
 					// only one reaction can run per stage; first one that executes is the one we use
 					break
 				}
@@ -140,6 +166,11 @@ func (qm *QuestManager) OnEvent(event defs.Event) {
 			// NOTE: it's possible that no reactions will have a matching event type.
 			// The reason is, we only index to questID, but we don't say which stage. So, the reaction for this event might just
 			// be on a different stage.
+
+			if !reactionRan {
+				// a reaction moves the quest to a different stage, so only count objectives if we're still on the same stage
+				qm.updateObjectives(questID, stage, event)
+			}
 		}
 	}
 }
@@ -169,7 +200,25 @@ func (qm *QuestManager) SetQuestStage(questID defs.QuestID, nextStage defs.Quest
 
 	// set current stage ID in quest state
 	questState := qm.GetActiveQuestState(questID)
+	oldStage := questState.CurrentStage
 	questState.CurrentStage = nextStage
+	questState.Objectives = newObjectivesProgress(stageDef)
+	questState.Deadline = nil
+	if stageDef.Deadline != nil {
+		deadline := stageDef.Deadline.GetDeadlineTime(qm.world.GetCurrentGameTime())
+		questState.Deadline = &deadline
+	}
+	qm.addHistoryEntry(questState, stageDef, Active)
+
+	qm.eventBus.Publish(defs.Event{
+		Type: pubsub.EventQuestStageChanged,
+		Data: map[string]any{
+			QuestIDKey:        questID,
+			OldStageKey:       oldStage,
+			NewStageKey:       nextStage,
+			TerminalStatusKey: stageDef.TerminalStatus,
+		},
+	})
 
 	// check if this is a terminal stage
 	switch stageDef.TerminalStatus {
@@ -225,12 +274,50 @@ func (qm QuestManager) GetQuestStage(qid defs.QuestID) (stage defs.QuestStageDef
 
 func (qm *QuestManager) LoadQuestDef(d defs.QuestDef) {
 	d.Validate()
+	if d.Template != nil && d.Template.Generator != "" {
+		if _, exists := questGenerators[d.Template.Generator]; !exists {
+			logz.Panicln("QuestManager", "quest template uses a generator that isn't registered:", d.ID, d.Template.Generator)
+		}
+	}
+	for _, err := range ValidateQuestGraph(d) {
+		if err.Kind == GraphErrDanglingRef {
+			// the quest would break as soon as it tried to move to this stage
+			logz.Panicln("QuestManager", err.Error())
+		}
+		logz.Warnln("QuestManager", err.Error())
+	}
 	qm.questDefs[d.ID] = d
 
 	// add it here, and then as quest states are loaded in later, we can delete them from this map.
 	qm.notStarted[d.ID] = true
 }
 
+// ResetStates removes all quest states, so that a different save can be loaded. Quest defs stay loaded, apart from the defs of
+// template instances, since those are recreated from the quest states that are loaded.
+func (qm *QuestManager) ResetStates() {
+	for _, bucket := range []map[defs.QuestID]*state.QuestState{qm.active, qm.completed, qm.failed} {
+		for questID, questState := range bucket {
+			if questState.TemplateID != "" {
+				delete(qm.questDefs, questID)
+			}
+		}
+	}
+
+	qm.notStarted = make(map[defs.QuestID]bool)
+	for questID := range qm.questDefs {
+		qm.notStarted[questID] = true
+	}
+	qm.active = make(map[defs.QuestID]*state.QuestState)
+	qm.completed = make(map[defs.QuestID]*state.QuestState)
+	qm.failed = make(map[defs.QuestID]*state.QuestState)
+	qm.templates = make(map[defs.QuestID]*state.QuestTemplateState)
+	qm.trackedQuest = ""
+
+	// these are recreated once the new quest states are loaded
+	qm.startTriggersByEvent = nil
+	qm.stageReactionsByEvent = nil
+}
+
 // CreateEventTypeIndices creates all the event indices that we use for knowing which quests are listening to which events.
 // This should only be done once all quest defs and quest states have been loaded.
 // It should probably be done once at the beginning of every play session, and also whenever a new quest is started.
@@ -245,9 +332,6 @@ func (qm *QuestManager) CreateEventTypeIndices() {
 	// setup start triggers index for quests that haven't been started yet
 	for questID := range qm.notStarted {
 		d := qm.GetQuestDef(questID)
-		if _, exists := qm.startTriggersByEvent[d.StartTrigger.EventType]; !exists {
-			qm.startTriggersByEvent[d.StartTrigger.EventType] = []defs.QuestID{}
-		}
 		qm.startTriggersByEvent[d.StartTrigger.EventType] = append(qm.startTriggersByEvent[d.StartTrigger.EventType], d.ID)
 	}
 
@@ -256,15 +340,15 @@ func (qm *QuestManager) CreateEventTypeIndices() {
 		d := qm.GetQuestDef(questID)
 		for _, stage := range d.Stages {
 			for _, reaction := range stage.Reactions {
-				if _, exists := qm.stageReactionsByEvent[reaction.SubscribeEvent]; !exists {
-					qm.stageReactionsByEvent[reaction.SubscribeEvent] = []defs.QuestID{}
-				}
 				// we only index by quest ID, even though these reactions are more specifically associated to a specific stage.
 				// it should work fine; what it means is, when an event happens and it matches to a questID in this map, we have to look up the
 				// quest state's current stage, and then check if the reactions in that stage ID are valid.
 				// I don't think this will lead to too much inefficiency since the event types will usually be pretty unique for quests.
 				// But, if we notice a problem, maybe we can consider mapping to a struct that has both quest ID and stage ID or something more precise.
-				qm.stageReactionsByEvent[reaction.SubscribeEvent] = append(qm.stageReactionsByEvent[reaction.SubscribeEvent], d.ID)
+				qm.indexStageEvent(reaction.SubscribeEvent, d.ID)
+			}
+			for _, obj := range stage.Objectives {
+				qm.indexStageEvent(obj.CountEvent, d.ID)
 			}
 		}
 	}
@@ -277,8 +361,21 @@ func (qm *QuestManager) CreateEventTypeIndices() {
 	logz.Println("QuestManager", "stage reaction triggers index:", qm.stageReactionsByEvent)
 }
 
+// indexStageEvent adds a quest to the stage event index. Each quest is only added once per event type, since
+// multiple stages (or a reaction and an objective) listening to the same event would otherwise get the quest processed more than once.
+func (qm *QuestManager) indexStageEvent(eventType defs.EventType, questID defs.QuestID) {
+	if slices.Contains(qm.stageReactionsByEvent[eventType], questID) {
+		return
+	}
+	qm.stageReactionsByEvent[eventType] = append(qm.stageReactionsByEvent[eventType], questID)
+}
+
 func (qm *QuestManager) LoadQuestState(questState state.QuestState) {
 	// TODO: validate quest states
+	if questState.TemplateID != "" {
+		qm.loadQuestInstanceState(questState)
+		return
+	}
 	switch questState.Status {
 	case Active:
 		qm.active[questState.DefID] = &questState
@@ -302,7 +399,54 @@ func (qm *QuestManager) LoadQuestState(questState state.QuestState) {
 	delete(qm.notStarted, questState.DefID)
 }
 
+// loadQuestInstanceState loads the state of a quest template instance, recreating the instance's quest def from its template.
+// Unlike regular quests, the template stays in the not started bucket, since more instances of it can still be started.
+func (qm *QuestManager) loadQuestInstanceState(questState state.QuestState) {
+	if _, exists := qm.questDefs[questState.TemplateID]; !exists {
+		logz.Panicln("QuestManager", "loaded quest instance state, but its template def wasn't found. ensure all quest defs are loaded before quest states:", questState.DefID, questState.TemplateID)
+	}
+	instanceDef := qm.registerInstanceDef(qm.GetQuestDef(questState.TemplateID), questState.Instance, questState.Placeholders)
+	if instanceDef.ID != questState.DefID {
+		logz.Panicln("QuestManager", "loaded quest instance state ID doesn't match its template and instance number:", questState.DefID, instanceDef.ID)
+	}
+	// older saves don't have template states, so make sure they're at least as far along as the instances that were saved
+	templateState := qm.templateState(questState.TemplateID)
+	templateState.InstanceCount = max(templateState.InstanceCount, questState.Instance)
+	if questState.Status != Active && len(questState.History) > 0 {
+		recordInstanceEnd(templateState, questState.History[len(questState.History)-1].Time)
+	}
+
+	switch questState.Status {
+	case Active:
+		qm.active[questState.DefID] = &questState
+	case Completed:
+		qm.completed[questState.DefID] = &questState
+	case Failed:
+		qm.failed[questState.DefID] = &questState
+	default:
+		logz.Panicln("QuestManager", "loaded quest instance state has invalid status:", questState.DefID, questState.Status)
+	}
+}
+
+// GetQuestDef gets a quest def, with its text in the active locale.
 func (qm *QuestManager) GetQuestDef(id defs.QuestID) defs.QuestDef {
+	questDef := qm.getQuestDef(id)
+
+	keyID := id
+	var r *strings.Replacer
+	if questState := qm.GetQuestState(id); questState != nil && questState.TemplateID != "" {
+		keyID = questState.TemplateID
+		pairs := []string{}
+		for p, val := range questState.Placeholders {
+			pairs = append(pairs, "{"+p+"}", val)
+		}
+		r = strings.NewReplacer(pairs...)
+	}
+	return localizeQuestDef(questDef, keyID, r)
+}
+
+// getQuestDef gets a quest def as it was loaded (not localized).
+func (qm *QuestManager) getQuestDef(id defs.QuestID) defs.QuestDef {
 	if id == "" {
 		panic("id was empty")
 	}
@@ -358,13 +502,19 @@ func (qm *QuestManager) StartQuest(id defs.QuestID) {
 	}
 
 	questDef := qm.GetQuestDef(id)
+	if questDef.Template != nil {
+		logz.Panicln("QuestManager", "tried to start a quest template directly; use StartQuestInstance instead:", id)
+	}
 
-	// instantiate quest state
-	questState := state.QuestState{
+	qm.beginQuest(questDef, state.QuestState{
 		DefID:  questDef.ID,
 		Status: Active,
-	}
+	})
+}
 
+// beginQuest adds a new quest state to the active quests, and starts its first stage.
+func (qm *QuestManager) beginQuest(questDef defs.QuestDef, questState state.QuestState) {
+	id := questDef.ID
 	qm.active[id] = &questState
 
 	// recreate indices since we need this quests' events included
@@ -399,8 +549,27 @@ func (qm *QuestManager) CompleteQuest(id defs.QuestID) {
 	// move quest from active to completed
 	questState := *qm.active[id]
 	questState.Status = Completed
+	stageDef := qm.GetQuestDef(id).Stages[questState.CurrentStage]
+	qm.addHistoryEntry(&questState, stageDef, Completed)
 	delete(qm.active, id)
 	qm.completed[id] = &questState
+	if qm.trackedQuest == id {
+		qm.trackedQuest = ""
+	}
+	if questState.TemplateID != "" {
+		qm.instanceEnded(questState)
+	}
+
+	qm.applyRewards(qm.GetQuestDef(id))
+
+	qm.eventBus.Publish(defs.Event{
+		Type: pubsub.EventQuestCompleted,
+		Data: map[string]any{
+			QuestIDKey:        id,
+			OldStageKey:       questState.CurrentStage,
+			TerminalStatusKey: stageDef.TerminalStatus,
+		},
+	})
 }
 
 func (qm *QuestManager) FailQuest(id defs.QuestID) {
@@ -421,15 +590,54 @@ func (qm *QuestManager) FailQuest(id defs.QuestID) {
 	// move quest from active to failed
 	questState := *qm.active[id]
 	questState.Status = Failed
+	stageDef := qm.GetQuestDef(id).Stages[questState.CurrentStage]
+	qm.addHistoryEntry(&questState, stageDef, Failed)
 	delete(qm.active, id)
 	qm.failed[id] = &questState
+	if qm.trackedQuest == id {
+		qm.trackedQuest = ""
+	}
+	if questState.TemplateID != "" {
+		qm.instanceEnded(questState)
+	}
+
+	qm.eventBus.Publish(defs.Event{
+		Type: pubsub.EventQuestFailed,
+		Data: map[string]any{
+			QuestIDKey:        id,
+			OldStageKey:       questState.CurrentStage,
+			TerminalStatusKey: stageDef.TerminalStatus,
+		},
+	})
 }
 
+// addHistoryEntry records a quest event (stage change, completion or failure) in the quest's history, stamped with the current game time.
+func (qm *QuestManager) addHistoryEntry(questState *state.QuestState, stageDef defs.QuestStageDef, status defs.QuestStatus) {
+	questState.History = append(questState.History, state.QuestHistoryEntry{
+		Time:        qm.world.GetCurrentGameTime(),
+		StageID:     stageDef.ID,
+		Title:       stageDef.Title,
+		Description: stageDef.Description,
+		Status:      status,
+	})
+}
+
+// conditionsMet checks if all the given conditions pass (AND logic). An empty list of conditions always passes.
 func (qm *QuestManager) conditionsMet(conditions []defs.QuestConditionDef, questID defs.QuestID, event defs.Event) bool {
-	// questState := qm.GetActiveQuestState(questID)
+	ctx := ConditionContext{
+		QuestID: questID,
+		Event:   event,
+		World:   qm.world,
+		qm:      qm,
+	}
 	for _, cond := range conditions {
-		switch cond.Type {
-		// TODO: need to figure out what type of conditions we have, and how they are checked
+		eval, exists := conditionEvaluators[cond.Type]
+		if !exists {
+			// validation should have caught this when the quest def was loaded
+			logz.Panicln("QuestManager", "no evaluator registered for quest condition type:", cond.Type, "quest:", questID)
+		}
+		if !eval(ctx, cond.Params) {
+			return false
 		}
 	}
 
@@ -0,0 +1,88 @@
+package quest
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	"github.com/webbben/2d-game-engine/locale"
+)
+
+// applyRewards gives the player a quest's rewards. Called when the quest is completed.
+func (qm *QuestManager) applyRewards(questDef defs.QuestDef) {
+	rewards := questDef.Rewards
+	if rewards.IsEmpty() {
+		return
+	}
+
+	if rewards.Gold > 0 {
+		qm.world.AddGold(rewards.Gold)
+	}
+	for _, it := range rewards.Items {
+		qm.world.AddItem(it.ItemID, max(it.Quantity, 1))
+	}
+	for _, skillID := range sortedSkillIDs(rewards.SkillXP) {
+		qm.world.AddSkillXP(skillID, rewards.SkillXP[skillID])
+	}
+	for _, op := range rewards.Opinion {
+		reason := op.Reason
+		if reason == "" {
+			reason = questDef.Name
+		}
+		qm.world.AddOpinionModifier(op.NPC, id.CharacterStateID(defs.PlayerID), defs.OpinionModifier{
+			Mod:    op.Mod,
+			Reason: reason,
+		})
+	}
+	for _, roleID := range rewards.Roles {
+		qm.world.AddRole(roleID)
+	}
+	for _, topicID := range rewards.Knowledge {
+		qm.world.AddKnowledge(topicID)
+	}
+}
+
+// GetRewardPreview gets a short, player facing summary of a quest's rewards, e.g. "Reward: 50 gold, Iron Sword".
+// Returns an empty string if the quest has no rewards that are shown to the player.
+func (qm QuestManager) GetRewardPreview(questID defs.QuestID) string {
+	return RewardPreview(qm.GetQuestDef(questID).Rewards, qm.world)
+}
+
+// RewardPreview summarizes rewards for showing in the journal or a dialog's InfoText.
+// Only the tangible rewards are listed (gold, items, skills, roles); opinion changes and knowledge are left out,
+// since those are more of a "story" consequence than something the player should see up front.
+func RewardPreview(rewards defs.QuestRewardsDef, dataCtx defs.DataContext) string {
+	parts := []string{}
+	if rewards.Gold > 0 {
+		parts = append(parts, locale.Plural("ui.quest.reward_gold", rewards.Gold, "%d gold", "%d gold"))
+	}
+	for _, it := range rewards.Items {
+		name := dataCtx.GetItemDef(it.ItemID).Name
+		if it.Quantity > 1 {
+			name = locale.T("ui.quest.reward_item_quantity", "%vx %s", it.Quantity, name)
+		}
+		parts = append(parts, name)
+	}
+	for _, skillID := range sortedSkillIDs(rewards.SkillXP) {
+		parts = append(parts, locale.T("ui.quest.reward_skill_xp", "%v %s XP", rewards.SkillXP[skillID], dataCtx.GetSkillDef(skillID).DisplayName))
+	}
+	for _, roleID := range rewards.Roles {
+		parts = append(parts, locale.T("ui.quest.reward_role", "Role: %s", roleID))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return locale.T("ui.quest.reward", "Reward: %s", strings.Join(parts, ", "))
+}
+
+// sortedSkillIDs gets the skill IDs of a map in a stable order, so rewards are applied and shown the same every time.
+func sortedSkillIDs(m map[defs.SkillID]int) []defs.SkillID {
+	ids := make([]defs.SkillID, 0, len(m))
+	for skillID := range m {
+		ids = append(ids, skillID)
+	}
+	slices.Sort(ids)
+	return ids
+}
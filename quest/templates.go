@@ -0,0 +1,279 @@
+package quest
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	"github.com/webbben/2d-game-engine/data/state"
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// QuestGenerator fills in the placeholders of a quest template when a new instance of it starts, e.g. picking a random target NPC for a bounty.
+// Return ok = false if no instance can be made right now (e.g. there are no valid targets); the quest just won't start.
+type QuestGenerator func(ctx defs.GameQuestContext, template defs.QuestDef) (values map[string]string, ok bool)
+
+var questGenerators = make(map[string]QuestGenerator)
+
+// RegisterQuestGenerator registers a generator that quest templates can refer to by ID.
+// Like condition types, this should be done before any quest defs are loaded.
+func RegisterQuestGenerator(generatorID string, gen QuestGenerator) {
+	if generatorID == "" {
+		panic("generator ID was empty")
+	}
+	if gen == nil {
+		logz.Panicln("RegisterQuestGenerator", "generator was nil:", generatorID)
+	}
+	if _, exists := questGenerators[generatorID]; exists {
+		logz.Panicln("RegisterQuestGenerator", "generator already registered:", generatorID)
+	}
+	questGenerators[generatorID] = gen
+}
+
+// InstanceID gets the quest ID of a specific instance of a quest template.
+func InstanceID(templateID defs.QuestID, instance int) defs.QuestID {
+	return defs.QuestID(fmt.Sprintf("%s%s%d", templateID, defs.QuestInstanceSeparator, instance))
+}
+
+// CanStartQuestInstance checks if a new instance of a quest template can start right now,
+// i.e. there's room for another active instance and the cooldown since the last one ended is over.
+func (qm QuestManager) CanStartQuestInstance(templateID defs.QuestID) bool {
+	templateDef := qm.getQuestDef(templateID)
+	if templateDef.Template == nil {
+		logz.Panicln("QuestManager", "quest is not a template:", templateID)
+	}
+
+	activeCount := 0
+	for _, questState := range qm.active {
+		if questState.TemplateID == templateID {
+			activeCount++
+		}
+	}
+	if activeCount >= templateDef.Template.GetMaxActive() {
+		return false
+	}
+
+	if templateDef.Template.CooldownHours > 0 {
+		if templateState, exists := qm.templates[templateID]; exists && templateState.LastEnded != nil {
+			cooldownEnd := *templateState.LastEnded
+			cooldownEnd.AddTime(templateDef.Template.CooldownHours)
+			if cooldownEnd.IsAfter(qm.world.GetCurrentGameTime()) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// templateState gets the state of a quest template, creating it if no instances have started yet.
+func (qm *QuestManager) templateState(templateID defs.QuestID) *state.QuestTemplateState {
+	templateState, exists := qm.templates[templateID]
+	if !exists {
+		templateState = &state.QuestTemplateState{TemplateID: templateID}
+		qm.templates[templateID] = templateState
+	}
+	return templateState
+}
+
+func recordInstanceEnd(templateState *state.QuestTemplateState, endTime clock.GameTime) {
+	if templateState.LastEnded == nil || endTime.IsAfter(*templateState.LastEnded) {
+		templateState.LastEnded = &endTime
+	}
+}
+
+// GetAllTemplateStates gets the states of all the quest templates that have had an instance started, for saving.
+func (qm QuestManager) GetAllTemplateStates() []state.QuestTemplateState {
+	out := []state.QuestTemplateState{}
+	for _, templateState := range qm.templates {
+		out = append(out, *templateState)
+	}
+	return out
+}
+
+// LoadQuestTemplateState loads the state of a quest template from a save. Quest defs should be loaded first.
+func (qm *QuestManager) LoadQuestTemplateState(templateState state.QuestTemplateState) {
+	if qm.getQuestDef(templateState.TemplateID).Template == nil {
+		logz.Panicln("QuestManager", "loaded quest template state, but the quest isn't a template:", templateState.TemplateID)
+	}
+	// instance states may have been loaded already, so don't go backwards from what they've shown
+	existing := qm.templateState(templateState.TemplateID)
+	existing.InstanceCount = max(existing.InstanceCount, templateState.InstanceCount)
+	if templateState.LastEnded != nil {
+		recordInstanceEnd(existing, *templateState.LastEnded)
+	}
+}
+
+// instanceEnded records when an instance of a template was completed or failed, and removes the oldest finished instances
+// of the template, so that they don't pile up in the quest states (and save files) forever.
+func (qm *QuestManager) instanceEnded(questState state.QuestState) {
+	if len(questState.History) > 0 {
+		recordInstanceEnd(qm.templateState(questState.TemplateID), questState.History[len(questState.History)-1].Time)
+	}
+	qm.pruneFinishedInstances(questState.TemplateID)
+}
+
+// pruneFinishedInstances removes the states (and defs) of all but the most recent finished instances of a template.
+func (qm *QuestManager) pruneFinishedInstances(templateID defs.QuestID) {
+	finished := []*state.QuestState{}
+	for _, bucket := range []map[defs.QuestID]*state.QuestState{qm.completed, qm.failed} {
+		for _, questState := range bucket {
+			if questState.TemplateID == templateID {
+				finished = append(finished, questState)
+			}
+		}
+	}
+	keep := qm.getQuestDef(templateID).Template.GetKeepFinished()
+	if len(finished) <= keep {
+		return
+	}
+
+	// newest instances first
+	slices.SortFunc(finished, func(a, b *state.QuestState) int {
+		return cmp.Compare(b.Instance, a.Instance)
+	})
+	for _, questState := range finished[keep:] {
+		logz.Println("QuestManager", "removing old finished quest instance:", questState.DefID)
+		delete(qm.completed, questState.DefID)
+		delete(qm.failed, questState.DefID)
+		delete(qm.questDefs, questState.DefID)
+	}
+}
+
+// startInstanceFromTrigger starts a new instance of a template when its start trigger fires, using its generator to fill in placeholders.
+func (qm *QuestManager) startInstanceFromTrigger(templateDef defs.QuestDef) {
+	if !qm.CanStartQuestInstance(templateDef.ID) {
+		logz.Println("QuestManager", "quest template triggered, but can't start a new instance right now:", templateDef.ID)
+		return
+	}
+
+	var values map[string]string
+	if templateDef.Template.Generator != "" {
+		gen := questGenerators[templateDef.Template.Generator]
+		var ok bool
+		values, ok = gen(qm.world, templateDef)
+		if !ok {
+			logz.Println("QuestManager", "quest generator couldn't make an instance:", templateDef.ID, templateDef.Template.Generator)
+			return
+		}
+	}
+
+	qm.StartQuestInstance(templateDef.ID, values)
+}
+
+// StartQuestInstance starts a new instance of a quest template, with the given values for its placeholders.
+// Use this for things like bounty boards, where the instance is chosen by the player rather than a start trigger.
+// Returns the new instance's quest ID, or an empty string if an instance can't start right now (see CanStartQuestInstance).
+func (qm *QuestManager) StartQuestInstance(templateID defs.QuestID, values map[string]string) defs.QuestID {
+	if !qm.CanStartQuestInstance(templateID) {
+		return ""
+	}
+	templateDef := qm.getQuestDef(templateID)
+
+	templateState := qm.templateState(templateID)
+	templateState.InstanceCount++
+	instance := templateState.InstanceCount
+	instanceDef := qm.registerInstanceDef(templateDef, instance, values)
+
+	qm.beginQuest(instanceDef, state.QuestState{
+		DefID:        instanceDef.ID,
+		Status:       Active,
+		TemplateID:   templateID,
+		Instance:     instance,
+		Placeholders: values,
+	})
+
+	return instanceDef.ID
+}
+
+// registerInstanceDef creates the quest def for an instance of a template, and adds it to the quest defs.
+func (qm *QuestManager) registerInstanceDef(templateDef defs.QuestDef, instance int, values map[string]string) defs.QuestDef {
+	instanceDef := instantiateQuestDef(templateDef, InstanceID(templateDef.ID, instance), values)
+	instanceDef.Validate()
+	qm.questDefs[instanceDef.ID] = instanceDef
+	return instanceDef
+}
+
+// instantiateQuestDef makes a copy of a quest template, with all of its placeholders filled in.
+func instantiateQuestDef(templateDef defs.QuestDef, instanceID defs.QuestID, values map[string]string) defs.QuestDef {
+	if templateDef.Template == nil {
+		logz.Panicln("QuestManager", "quest is not a template:", templateDef.ID)
+	}
+	pairs := []string{}
+	for _, p := range templateDef.Template.Placeholders {
+		val := values[p]
+		if val == "" {
+			logz.Panicln("QuestManager", "no value given for quest template placeholder:", templateDef.ID, p)
+		}
+		pairs = append(pairs, "{"+p+"}", val)
+	}
+	r := strings.NewReplacer(pairs...)
+
+	d := templateDef
+	d.ID = instanceID
+	d.Template = nil
+	d.Name = r.Replace(d.Name)
+	d.Description = r.Replace(d.Description)
+	d.StartTrigger.Conditions = replaceConditions(r, d.StartTrigger.Conditions)
+
+	d.Stages = make(map[defs.QuestStageID]defs.QuestStageDef)
+	for stageID, stage := range templateDef.Stages {
+		stage.Title = r.Replace(stage.Title)
+		stage.Objective = r.Replace(stage.Objective)
+		stage.Description = r.Replace(stage.Description)
+
+		reactions := []defs.QuestReactionDef{}
+		for _, reaction := range stage.Reactions {
+			reaction.Conditions = replaceConditions(r, reaction.Conditions)
+			reactions = append(reactions, reaction)
+		}
+		stage.Reactions = reactions
+
+		objectives := []defs.QuestObjectiveDef{}
+		for _, obj := range stage.Objectives {
+			obj.Text = r.Replace(obj.Text)
+			obj.Conditions = replaceConditions(r, obj.Conditions)
+			objectives = append(objectives, obj)
+		}
+		stage.Objectives = objectives
+
+		if stage.Target != nil {
+			target := *stage.Target
+			target.NPC = defs.CharacterDefID(r.Replace(string(target.NPC)))
+			target.MapID = defs.MapID(r.Replace(string(target.MapID)))
+			stage.Target = &target
+		}
+
+		d.Stages[stageID] = stage
+	}
+
+	d.Rewards.Items = []defs.QuestItemReward{}
+	for _, it := range templateDef.Rewards.Items {
+		it.ItemID = defs.ItemID(r.Replace(string(it.ItemID)))
+		d.Rewards.Items = append(d.Rewards.Items, it)
+	}
+	d.Rewards.Opinion = []defs.QuestOpinionReward{}
+	for _, op := range templateDef.Rewards.Opinion {
+		op.NPC = id.CharacterStateID(r.Replace(string(op.NPC)))
+		d.Rewards.Opinion = append(d.Rewards.Opinion, op)
+	}
+
+	return d
+}
+
+// replaceConditions copies a list of conditions, filling in placeholders in their params.
+func replaceConditions(r *strings.Replacer, conditions []defs.QuestConditionDef) []defs.QuestConditionDef {
+	out := []defs.QuestConditionDef{}
+	for _, cond := range conditions {
+		params := make(map[string]string)
+		for k, v := range cond.Params {
+			params[k] = r.Replace(v)
+		}
+		out = append(out, defs.QuestConditionDef{Type: cond.Type, Params: params})
+	}
+	return out
+}
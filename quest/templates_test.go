@@ -0,0 +1,68 @@
+package quest
+
+import (
+	"testing"
+
+	"github.com/webbben/2d-game-engine/clock"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/state"
+)
+
+func TestFinishedInstancesArePruned(t *testing.T) {
+	qm := &QuestManager{
+		questDefs: map[defs.QuestID]defs.QuestDef{
+			"bounty": {ID: "bounty", Template: &defs.QuestTemplateDef{KeepFinished: 2}},
+		},
+		active:    make(map[defs.QuestID]*state.QuestState),
+		completed: make(map[defs.QuestID]*state.QuestState),
+		failed:    make(map[defs.QuestID]*state.QuestState),
+		templates: make(map[defs.QuestID]*state.QuestTemplateState),
+	}
+
+	// four instances finish one after another, alternating between completed and failed
+	var last state.QuestState
+	for i := 1; i <= 4; i++ {
+		instanceID := InstanceID("bounty", i)
+		qm.questDefs[instanceID] = defs.QuestDef{ID: instanceID}
+		questState := state.QuestState{
+			DefID:      instanceID,
+			TemplateID: "bounty",
+			Instance:   i,
+			Status:     Completed,
+			History:    []state.QuestHistoryEntry{{Time: clock.GameTime{Hour: i}, Status: Completed}},
+		}
+		if i%2 == 0 {
+			questState.Status = Failed
+			qm.failed[instanceID] = &questState
+		} else {
+			qm.completed[instanceID] = &questState
+		}
+		qm.templateState("bounty").InstanceCount = i
+		qm.instanceEnded(questState)
+		last = questState
+	}
+
+	for i, want := range []defs.QuestStatus{NotStarted, NotStarted, Completed, Failed} {
+		instanceID := InstanceID("bounty", i+1)
+		if got := qm.GetQuestStatus(instanceID); got != want {
+			t.Errorf("%s: expected status %s, got %s", instanceID, want, got)
+		}
+		if _, exists := qm.questDefs[instanceID]; exists != (want != NotStarted) {
+			t.Errorf("%s: instance def should only be kept along with its state", instanceID)
+		}
+	}
+
+	templateState := qm.templates["bounty"]
+	if templateState.InstanceCount != 4 {
+		t.Errorf("pruning shouldn't change the instance count, got %v", templateState.InstanceCount)
+	}
+	if templateState.LastEnded == nil || *templateState.LastEnded != last.History[0].Time {
+		t.Errorf("expected last end time %v, got %v", last.History[0].Time, templateState.LastEnded)
+	}
+
+	// loading an older template state (e.g. from a save) doesn't go backwards
+	qm.LoadQuestTemplateState(state.QuestTemplateState{TemplateID: "bounty", InstanceCount: 2, LastEnded: &clock.GameTime{Hour: 1}})
+	if templateState.InstanceCount != 4 || templateState.LastEnded.Hour != 4 {
+		t.Errorf("template state went backwards after loading: %+v", templateState)
+	}
+}
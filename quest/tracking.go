@@ -0,0 +1,38 @@
+package quest
+
+import (
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// SetTrackedQuest sets which quest the player is tracking (i.e. shown with markers and the compass in the HUD).
+// Only active quests can be tracked. Pass an empty ID to stop tracking.
+func (qm *QuestManager) SetTrackedQuest(questID defs.QuestID) {
+	if questID == "" {
+		qm.trackedQuest = ""
+		return
+	}
+	if qm.GetQuestStatus(questID) != Active {
+		logz.Warnln("QuestManager", "tried to track a quest that isn't active:", questID)
+		return
+	}
+	qm.trackedQuest = questID
+}
+
+// GetTrackedQuest gets the quest the player is tracking, or an empty string if none.
+func (qm QuestManager) GetTrackedQuest() defs.QuestID {
+	return qm.trackedQuest
+}
+
+// GetTrackedQuestTarget gets the target of the tracked quest's current stage.
+// Returns false if no quest is tracked, or the current stage has no target.
+func (qm QuestManager) GetTrackedQuestTarget() (defs.QuestID, defs.QuestTargetDef, bool) {
+	if qm.trackedQuest == "" {
+		return "", defs.QuestTargetDef{}, false
+	}
+	stage := qm.GetActiveQuestStage(qm.trackedQuest)
+	if stage.Target == nil {
+		return qm.trackedQuest, defs.QuestTargetDef{}, false
+	}
+	return qm.trackedQuest, *stage.Target, true
+}
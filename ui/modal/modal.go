@@ -8,6 +8,7 @@ import (
 	"github.com/webbben/2d-game-engine/audio"
 	"github.com/webbben/2d-game-engine/config"
 	"github.com/webbben/2d-game-engine/imgutil/rendering"
+	"github.com/webbben/2d-game-engine/locale"
 	"github.com/webbben/2d-game-engine/ui/box"
 	"github.com/webbben/2d-game-engine/ui/button"
 	"github.com/webbben/2d-game-engine/ui/text"
@@ -66,10 +67,10 @@ func NewTextInputModal(params TextInputModalParams, audioman *audio.AudioManager
 		params.BodyFont = config.DefaultFont
 	}
 	if params.TitleText == "" {
-		params.TitleText = "Input Text"
+		params.TitleText = locale.Text("ui.modal.input_text", "Input Text")
 	}
 	if params.ConfirmButtonText == "" {
-		params.ConfirmButtonText = "Confirm"
+		params.ConfirmButtonText = locale.Text("ui.modal.confirm", "Confirm")
 	}
 	if params.MaxCharLength == 0 {
 		params.MaxCharLength = 20
@@ -56,3 +56,31 @@ func (sa *ScrollArea) Draw(screen *ebiten.Image, content *ebiten.Image, x, y flo
 	rendering.DrawImage(sa.canvas, content, 0, sa.lastY, 0)
 	rendering.DrawImage(screen, sa.canvas, x, y, 0)
 }
+
+// DrawWith is like Draw, but for content that's too tall to fit in a single image (ebiten images can't be bigger than the GPU's max texture size).
+// Instead of drawing an image, drawContent is called with the scroll area's canvas and the current scroll offset, and should draw
+// whatever part of the content is visible at that offset. Set the height of the content with SetContentHeight first.
+func (sa *ScrollArea) DrawWith(screen *ebiten.Image, x, y float64, drawContent func(canvas *ebiten.Image, offsetY float64)) {
+	sa.canvas.Clear()
+
+	sa.lastY += (sa.scrollOffsetY - sa.lastY) * 0.2
+	drawContent(sa.canvas, sa.lastY)
+	rendering.DrawImage(screen, sa.canvas, x, y, 0)
+}
+
+// SetContentHeight sets how tall the content is, for content drawn with DrawWith.
+func (sa *ScrollArea) SetContentHeight(contentHeight int) {
+	sa.contentHeight = contentHeight
+}
+
+// ScrollToBottom jumps straight to the bottom of the given content (e.g. for logs, where the newest lines are at the bottom).
+func (sa *ScrollArea) ScrollToBottom(content *ebiten.Image) {
+	sa.contentHeight = content.Bounds().Dy()
+	sa.ScrollToEnd()
+}
+
+// ScrollToEnd jumps straight to the bottom of the content, using the content height that was last set or drawn.
+func (sa *ScrollArea) ScrollToEnd() {
+	sa.scrollOffsetY = float64(min(sa.height-sa.contentHeight, 0))
+	sa.lastY = sa.scrollOffsetY
+}
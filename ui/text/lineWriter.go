@@ -41,6 +41,7 @@ func isSlowdownChar(r rune) bool {
 type LineWriter struct {
 	audioman         *audio.AudioManager
 	textBlipSfx      defs.SoundID
+	blipPitch        float64
 	ticksTilBlip     int  // remaining ticks until next "blip" sfx
 	blipTickInterval int  // number of ticks between blip sound while text is writing
 	specialSymbols   bool // if true, lineWriter handles special symbols like underscores, square brackets, etc. otherwise, it just draws those symbols as is.
@@ -154,11 +155,27 @@ func NewLineWriter(audioman *audio.AudioManager, params config.LineWriterParams)
 		}
 		lw.textBlipSfx = params.TextBlipSfx
 		lw.blipTickInterval = params.TextBlipTickInterval
+		lw.blipPitch = params.TextBlipPitch
 	}
 
 	return lw
 }
 
+// SetTextBlip changes the blip sound played while text is writing, e.g. when a different character starts speaking.
+// An empty sfx turns the blip off. Pitch is 1 for the sound's normal pitch.
+func (lw *LineWriter) SetTextBlip(sfx defs.SoundID, tickInterval int, pitch float64) {
+	if sfx != "" && lw.audioman == nil {
+		logz.Panic("audioman was nil. ensure it's passed into NewLineWriter if this linewriter is supposed to support SFX.")
+	}
+	if tickInterval < 0 {
+		panic("tick interval was < 0")
+	}
+	lw.textBlipSfx = sfx
+	lw.blipTickInterval = tickInterval
+	lw.blipPitch = pitch
+	lw.ticksTilBlip = 0
+}
+
 // SetSourceText sets the text for the lineWriter to write. It's expected that you will call Clear first if there is already written text.
 // If you want to clear the lineWriter of all text, you should use that Clear function too - don't try passing an empty string in here.
 func (lw *LineWriter) SetSourceText(textToWrite string) {
@@ -398,7 +415,7 @@ func (lw *LineWriter) Update() {
 								}
 								lw.ticksTilBlip--
 								if lw.ticksTilBlip <= 0 {
-									lw.audioman.PlaySFX(lw.textBlipSfx, 0.2)
+									lw.audioman.PlaySFXPitched(lw.textBlipSfx, 0.2, lw.blipPitch)
 									lw.ticksTilBlip = lw.blipTickInterval
 								}
 							}
@@ -0,0 +1,168 @@
+// Package transcript provides a UI component that shows a scrollable log of past dialog conversations
+package transcript
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/state"
+	"github.com/webbben/2d-game-engine/imgutil/rendering"
+	"github.com/webbben/2d-game-engine/locale"
+	"github.com/webbben/2d-game-engine/ui/box"
+	"github.com/webbben/2d-game-engine/ui/scrollarea"
+	"github.com/webbben/2d-game-engine/ui/text"
+	"github.com/webbben/2d-game-engine/utils"
+	"golang.org/x/image/font"
+)
+
+var (
+	npcSpeakerColor    = color.RGBA{230, 200, 120, 255}
+	playerSpeakerColor = color.RGBA{140, 190, 240, 255}
+	timeColor          = color.RGBA{160, 160, 160, 255}
+)
+
+// KeyNothingSaid is the localization key for the note shown when a transcript has no lines yet.
+const KeyNothingSaid locale.Key = "ui.transcript.nothing_said"
+
+type TranscriptViewParams struct {
+	Width, Height  int // size of the whole view, including the box border
+	BoxTilesetSrc  string
+	BoxOriginIndex int
+	Font           font.Face // OPT: font for the lines themselves. defaults to config.DefaultFont.
+}
+
+// TranscriptView shows the lines of a dialog transcript in a box, which the player can scroll through.
+// The newest lines are at the bottom, and it starts scrolled all the way down.
+//
+// A long transcript can be much taller than the biggest image ebiten can make, so instead of drawing everything onto one image,
+// only the lines that are currently in view are drawn.
+type TranscriptView struct {
+	boxImg     *ebiten.Image
+	scrollArea scrollarea.ScrollArea
+
+	title         string
+	entries       []transcriptLine
+	innerHeight   int
+	contentHeight int
+	topPadding    int // if the content is shorter than the view, it's pushed down by this much so the lines sit at the bottom
+}
+
+// transcriptLine is a transcript entry, laid out at its position in the content.
+type transcriptLine struct {
+	entry  state.DialogTranscriptEntry
+	text   text.Multiline
+	y      int // top of the entry, relative to the top of the content
+	height int
+}
+
+func NewTranscriptView(title string, entries []state.DialogTranscriptEntry, params TranscriptViewParams) TranscriptView {
+	if params.BoxTilesetSrc == "" {
+		panic("box tileset src was empty")
+	}
+	if params.Font == nil {
+		params.Font = config.DefaultFont
+	}
+	tileSize := int(config.GetScaledTilesize())
+	width := utils.RoundDownToTile(params.Width, tileSize)
+	height := utils.RoundDownToTile(params.Height, tileSize)
+
+	b := box.NewBox(params.BoxTilesetSrc, params.BoxOriginIndex)
+	tv := TranscriptView{
+		boxImg: b.BuildBoxImage(width, height, config.UIScale),
+		title:  title,
+	}
+
+	innerWidth := width - (tileSize * 2)
+	tv.innerHeight = height - tileSize
+	tv.layoutContent(entries, innerWidth, params.Font)
+
+	tv.scrollArea = scrollarea.NewScrollArea(scrollarea.ScrollAreaParams{
+		Width:  innerWidth,
+		Height: tv.innerHeight,
+	})
+	tv.scrollArea.SetContentHeight(tv.contentHeight)
+	tv.scrollArea.ScrollToEnd()
+
+	return tv
+}
+
+// layoutContent works out where each transcript line goes, and how tall the content is in total.
+func (tv *TranscriptView) layoutContent(entries []state.DialogTranscriptEntry, width int, f font.Face) {
+	tileSize := int(config.GetScaledTilesize())
+	infoHeight, infoDesc := text.GetRealisticFontMetrics(config.DefaultInfoFont)
+	titleHeight, titleDesc := text.GetRealisticFontMetrics(config.DefaultTitleFont)
+
+	y := titleHeight + titleDesc + tileSize/2
+	if len(entries) == 0 {
+		// room for the "nothing has been said" note
+		y += infoHeight + infoDesc + tileSize/2
+	}
+	tv.entries = make([]transcriptLine, 0, len(entries))
+	for _, entry := range entries {
+		ml := text.NewMultiline(entry.Text, width, f, text.MultilineParams{UseShadow: true})
+		_, dy := ml.Dimensions()
+		entryHeight := infoHeight + infoDesc + dy + tileSize/2
+		tv.entries = append(tv.entries, transcriptLine{entry: entry, text: ml, y: y, height: entryHeight})
+		y += entryHeight
+	}
+
+	tv.contentHeight = max(y, tv.innerHeight)
+	tv.topPadding = tv.contentHeight - y
+}
+
+// drawContent draws the part of the content that's visible at the given scroll offset.
+func (tv *TranscriptView) drawContent(canvas *ebiten.Image, offsetY float64) {
+	tileSize := int(config.GetScaledTilesize())
+	infoHeight, infoDesc := text.GetRealisticFontMetrics(config.DefaultInfoFont)
+	titleHeight, titleDesc := text.GetRealisticFontMetrics(config.DefaultTitleFont)
+	top := tv.topPadding + int(offsetY)
+
+	// the title and note are at the very top, so there's no point drawing them once they're scrolled away
+	if top+titleHeight+titleDesc > 0 {
+		text.DrawShadowText(canvas, tv.title, config.DefaultTitleFont, 0, top+titleHeight, nil, nil, 0, 0)
+		if len(tv.entries) == 0 {
+			y := top + titleHeight + titleDesc + tileSize/2 + infoHeight
+			text.DrawText(canvas, locale.Text(KeyNothingSaid, "Nothing has been said yet."), config.DefaultInfoFont, 0, y, timeColor)
+		}
+	}
+
+	for i := range tv.entries {
+		line := &tv.entries[i]
+		y := top + line.y
+		if y+line.height < 0 {
+			continue
+		}
+		if y > tv.innerHeight {
+			break
+		}
+
+		y += infoHeight
+		speakerColor := npcSpeakerColor
+		if line.entry.IsPlayer {
+			speakerColor = playerSpeakerColor
+		}
+		text.DrawText(canvas, line.entry.Speaker, config.DefaultInfoFont, 0, y, speakerColor)
+		speakerDx, _, _ := text.GetStringSize(line.entry.Speaker, config.DefaultInfoFont)
+		timeString := fmt.Sprintf("(%02d:%02d, day %v)", line.entry.Time.Hour, line.entry.Time.Minute, line.entry.Time.DayOfSeason+1)
+		text.DrawText(canvas, timeString, config.DefaultInfoFont, speakerDx+tileSize/4, y, timeColor)
+		y += infoDesc
+
+		line.text.Draw(canvas, 0, float64(y))
+	}
+}
+
+func (tv TranscriptView) Dimensions() (dx, dy int) {
+	return tv.boxImg.Bounds().Dx(), tv.boxImg.Bounds().Dy()
+}
+
+func (tv *TranscriptView) Update() {
+	tv.scrollArea.Update()
+}
+
+func (tv *TranscriptView) Draw(screen *ebiten.Image, x, y float64) {
+	tileSize := config.GetScaledTilesize()
+	rendering.DrawImage(screen, tv.boxImg, x, y, 0)
+	tv.scrollArea.DrawWith(screen, x+tileSize, y+tileSize/2, tv.drawContent)
+}
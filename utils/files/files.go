@@ -32,6 +32,43 @@ func WriteToJSON(v any, outputFilePath string) error {
 	return os.WriteFile(outputFilePath, data, 0o644)
 }
 
+// WriteFileAtomic writes data to a file without ever leaving a half-written file behind: the data is written to a temp file
+// in the same directory, and then renamed over the original. If the game crashes mid-write, the original file is untouched.
+func WriteFileAtomic(outputFilePath string, data []byte) (err error) {
+	dir, name := filepath.Split(outputFilePath)
+	tmp, err := os.CreateTemp(dir, name+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	// if anything goes wrong, don't leave the temp file lying around
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	// make sure the data is actually on disk before the rename, so a crash can't leave us with an empty file
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err = os.Chmod(tmpPath, 0o644); err != nil {
+		return fmt.Errorf("failed to set temp file permissions: %w", err)
+	}
+	if err = os.Rename(tmpPath, outputFilePath); err != nil {
+		return fmt.Errorf("failed to move temp file into place: %w", err)
+	}
+	return nil
+}
+
 func GetListOfDirs(directoryPath string, getAbsPaths bool) []string {
 	if directoryPath == "" {
 		logz.Panicln("GetListOfDirs", "directoryPath was empty...")
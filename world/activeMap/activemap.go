@@ -921,6 +921,7 @@ func (mi *ActiveMap) HandleObjectUpdate(result object.ObjectUpdateResult, obj *o
 			// player is now sleeping in bed
 			// tell the entity so that it draws in the correct place
 			mi.PlayerRef.Entity.SleepInBed(obj)
+			mi.eventBus.Publish(defs.Event{Type: pubsub.EventPlayerSleep})
 		}
 	case object.TypeChair:
 		if mi.PlayerRef.Entity.IsSitting {
@@ -133,6 +133,17 @@ func (m *ActiveMap) GetAllNPCs() []*npc.NPC {
 	return append([]*npc.NPC{}, m.NPCs...)
 }
 
+// PlayEmote has an NPC in this map act out a dialog emote. If the NPC isn't in this map, nothing happens.
+func (m *ActiveMap) PlayEmote(charStateID id.CharacterStateID, emote defs.DialogEmote) {
+	for _, n := range m.GetAllNPCs() {
+		if n.CharacterStateRef.ID == charStateID {
+			n.PlayEmote(emote, m.PlayerRef.Entity)
+			return
+		}
+	}
+	logz.Warnln("PlayEmote", "NPC not found in active map:", charStateID)
+}
+
 func (m *ActiveMap) RemoveNPCFromActiveMap(charStateID id.CharacterStateID, toMap defs.MapID) {
 	if toMap == m.MapID {
 		logz.Println("RemoveNPCFromActiveMap", toMap)
@@ -1,8 +1,11 @@
 package activemap
 
 import (
+	"image"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/display"
 	"github.com/webbben/2d-game-engine/internal/camera"
 	"github.com/webbben/2d-game-engine/internal/lights"
 	"github.com/webbben/2d-game-engine/logz"
@@ -41,7 +44,8 @@ func (m *ActiveMap) drawWorldScene(screen *ebiten.Image, offsetX, offsetY float6
 	m.Map.DrawRooftopLayer(screen, offsetX, offsetY)
 }
 
-func (m *ActiveMap) Draw(screen *ebiten.Image, om *overlay.OverlayManager) {
+// drawLitWorld draws the world scene (the map, entities, etc) onto the screen, with lighting applied.
+func (m *ActiveMap) drawLitWorld(screen *ebiten.Image) {
 	m.worldScene.Clear()
 
 	offsetX, offsetY := m.Camera.GetAbsPos()
@@ -113,6 +117,33 @@ func (m *ActiveMap) Draw(screen *ebiten.Image, om *overlay.OverlayManager) {
 		offsetX,
 		offsetY,
 	)
+}
+
+// CaptureThumbnail renders the current view of the world (without any UI on top) scaled down to the given width, e.g. for save file previews.
+func (m *ActiveMap) CaptureThumbnail(width int) *image.RGBA {
+	if width <= 0 {
+		logz.Panicln("CaptureThumbnail", "width must be positive:", width)
+	}
+	full := ebiten.NewImage(display.SCREEN_WIDTH, display.SCREEN_HEIGHT)
+	defer full.Deallocate()
+	m.drawLitWorld(full)
+
+	scale := float64(width) / float64(display.SCREEN_WIDTH)
+	height := max(int(float64(display.SCREEN_HEIGHT)*scale), 1)
+	thumb := ebiten.NewImage(width, height)
+	defer thumb.Deallocate()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.Filter = ebiten.FilterLinear
+	thumb.DrawImage(full, op)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	thumb.ReadPixels(img.Pix)
+	return img
+}
+
+func (m *ActiveMap) Draw(screen *ebiten.Image, om *overlay.OverlayManager) {
+	m.drawLitWorld(screen)
 
 	if m.dialogSession != nil {
 		m.dialogSession.Draw(screen)
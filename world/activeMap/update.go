@@ -24,6 +24,14 @@ func (m *ActiveMap) Update(blockPlayerChanges bool) {
 		if m.dialogSession.Exit {
 			m.dialogSession = nil
 		}
+		// NPCs may be acting out emotes from the dialog; keep those moving, and stop them once the dialog is over
+		for _, n := range m.GetAllNPCs() {
+			if m.dialogSession == nil {
+				n.EndEmote()
+			} else {
+				n.UpdateEmote()
+			}
+		}
 		// set last player update to now, so that the time hud doesn't immediately display
 		m.PlayerRef.LastUserInput = time.Now()
 		// in dialog, so don't allow NPC updates
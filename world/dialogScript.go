@@ -0,0 +1,47 @@
+package world
+
+import (
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/dialogscript"
+	"github.com/webbben/2d-game-engine/data/id"
+)
+
+// registers the world effects defined in this package, so they can be used in dialog scripts.
+// Effects that need complex params (like assigning tasks or scheduling future events) are left out; use Go defined dialogs for those.
+func init() {
+	dialogscript.RegisterWorldEffect("add_item", func(p *dialogscript.Params) defs.WorldEffect {
+		quantity := p.OptInt("quantity", 1)
+		return AddItemEffect{ItemID: defs.ItemID(p.String("item")), Quantity: &quantity}
+	})
+	dialogscript.RegisterWorldEffect("add_role", func(p *dialogscript.Params) defs.WorldEffect {
+		return AddRoleEffect{RoleID: defs.RoleID(p.String("role"))}
+	})
+	dialogscript.RegisterWorldEffect("remove_role", func(p *dialogscript.Params) defs.WorldEffect {
+		return RemoveRoleEffect{RoleID: defs.RoleID(p.String("role"))}
+	})
+	dialogscript.RegisterWorldEffect("add_gold", func(p *dialogscript.Params) defs.WorldEffect {
+		return AddGoldEffect{Amount: p.Int("amount")}
+	})
+	dialogscript.RegisterWorldEffect("remove_gold", func(p *dialogscript.Params) defs.WorldEffect {
+		return RemoveGoldEffect{Amount: p.Int("amount")}
+	})
+	dialogscript.RegisterWorldEffect("event", func(p *dialogscript.Params) defs.WorldEffect {
+		return EventEffect{Event: defs.Event{Type: defs.EventType(p.String("type"))}}
+	})
+	dialogscript.RegisterWorldEffect("queue_scenario", func(p *dialogscript.Params) defs.WorldEffect {
+		return QueueScenarioEffect{ScenarioID: defs.ScenarioID(p.String("scenario"))}
+	})
+	dialogscript.RegisterWorldEffect("unlock", func(p *dialogscript.Params) defs.WorldEffect {
+		return UnlockEffect{MapLock: defs.MapLock{MapID: defs.MapID(p.String("map")), LockID: p.String("lock")}}
+	})
+	dialogscript.RegisterWorldEffect("travel_to_map", func(p *dialogscript.Params) defs.WorldEffect {
+		return TravelToMapEffect{MapID: defs.MapID(p.String("map")), ToSpawnIndex: p.OptInt("spawn", 0), Hours: p.OptInt("hours", 0)}
+	})
+	dialogscript.RegisterWorldEffect("add_opinion_mod", func(p *dialogscript.Params) defs.WorldEffect {
+		return AddOpinionModEffect{
+			Mod:      defs.OpinionModifier{Mod: p.Int("mod"), Reason: p.String("reason")},
+			RelHours: p.OptInt("hours", 0),
+			Holder:   id.CharacterStateID(p.OptString("holder", "")),
+		}
+	})
+}
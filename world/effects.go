@@ -1,6 +1,8 @@
 package world
 
 import (
+	"fmt"
+
 	"github.com/webbben/2d-game-engine/clock"
 	"github.com/webbben/2d-game-engine/data/defs"
 	"github.com/webbben/2d-game-engine/data/id"
@@ -11,6 +13,22 @@ import (
 // Effects are used in places like dialogs and quests. They represent an interface of core effects
 // that can be applied to the game world, and have centralized logic here.
 
+func init() {
+	// so that effects can be carried by saved events (e.g. a scheduled SysScheduledWorldEffect)
+	defs.RegisterPayloadType("world.AddItemEffect", AddItemEffect{})
+	defs.RegisterPayloadType("world.AddRoleEffect", AddRoleEffect{})
+	defs.RegisterPayloadType("world.RemoveRoleEffect", RemoveRoleEffect{})
+	defs.RegisterPayloadType("world.ScheduleFutureEventEffect", ScheduleFutureEventEffect{})
+	defs.RegisterPayloadType("world.AddGoldEffect", AddGoldEffect{})
+	defs.RegisterPayloadType("world.RemoveGoldEffect", RemoveGoldEffect{})
+	defs.RegisterPayloadType("world.EventEffect", EventEffect{})
+	defs.RegisterPayloadType("world.AssignTaskEffect", AssignTaskEffect{})
+	defs.RegisterPayloadType("world.QueueScenarioEffect", QueueScenarioEffect{})
+	defs.RegisterPayloadType("world.UnlockEffect", UnlockEffect{})
+	defs.RegisterPayloadType("world.TravelToMapEffect", TravelToMapEffect{})
+	defs.RegisterPayloadType("world.AddOpinionModEffect", AddOpinionModEffect{})
+}
+
 // AddItemEffect adds an item to the player's inventory.
 // TODO: if the player's inventory is full, then the item just appears on the ground next to the player
 type AddItemEffect struct {
@@ -45,17 +63,12 @@ func (e RemoveRoleEffect) Apply(ctx defs.WorldEffectContext) {
 	ctx.RemoveRole(e.RoleID)
 }
 
-// ScheduleFutureEventEffect is for scheduling a future event. Events can be used to schedule certain world effects too, but only very specific ones
-// where it seems likely to want to "undo" a current effect. For example, you can use the AddRole effect, then schedule an event to undo that via an event
-// that triggers the RemoveRole effect. I'll try to fill these out with more world effect support as the need arises.
+// ScheduleFutureEventEffect is for scheduling a future event. Events can be used to schedule world effects too, which is handy for
+// "undoing" a current effect later on. For example, you can use the AddRole effect, then schedule a pubsub.SysScheduledWorldEffect event
+// that carries a RemoveRole effect (see ScheduledWorldEffectEvent).
 //
-// Supported world effects so far:
-//   - RemoveRole
-//
-// Q: Why not add functionality for scheduling future effects? Then, you don't need to worry about adding event infrastructure for each effect type, right?
-//   - A: True, but the messy part is saving and loading scheduled effects. Effects are an interface type, so we would need a way to preserve knowledge of the original
-//     Data type/struct. Without that, the implementation of Apply is lost. Not that crazy hard to do, but would require a lot of infrastructure to ensure saving
-//     and loading these effects is handled right. Also, I anticipate only a handful of world effects will actually ever need to be scheduled for the future, like role stuff.
+// Scheduled events are kept in save files, so any data they carry (including world effects) must be a registered payload type
+// (see defs.RegisterPayloadType). The world effects in this package are already registered.
 type ScheduleFutureEventEffect struct {
 	Event               defs.Event
 	SpecificDate        *clock.GameTime // you can optionally define a specific, hard-coded time. otherwise, this event uses relative times.
@@ -108,6 +121,21 @@ func (e ScheduleFutureEventEffect) Apply(ctx defs.WorldEffectContext) {
 	})
 }
 
+// ScheduledWorldEffectEvent creates an event that applies the given world effect when it fires; meant for use with ScheduleFutureEventEffect.
+func ScheduledWorldEffectEvent(effect defs.WorldEffect) defs.Event {
+	if effect == nil {
+		panic("effect was nil")
+	}
+	return defs.Event{
+		Type: pubsub.SysScheduledWorldEffect,
+		Data: map[string]any{
+			"type":   fmt.Sprintf("%T", effect),
+			"effect": effect,
+		},
+		RequireSubscriber: true,
+	}
+}
+
 // StartCustomLoadScreenEffect causes a load screen (transition + execute load script) to occur in a dialog.
 // Using this effect will cause dialog to end.
 type StartCustomLoadScreenEffect struct {
@@ -22,6 +22,10 @@ func (w *World) GetCurrentGameTime() clock.GameTime {
 	return w.Clock.GetCurrentGameTime()
 }
 
+func (w *World) GetDayOfWeek() clock.DayOfWeek {
+	return w.Clock.GetDayOfWeek()
+}
+
 func (w *World) AddItem(itemID defs.ItemID, quantity int) {
 	if quantity <= 0 {
 		panic("item quantity was <= 0")
@@ -87,6 +91,17 @@ func (w *World) RemoveRole(roleID defs.RoleID) {
 	})
 }
 
+func (w *World) AddSkillXP(skillID defs.SkillID, xp int) {
+	if w.Player == nil {
+		logz.Panic("Player was nil!")
+	}
+	characterstate.AddSkillXP(w.Player.CharacterStateRef, skillID, xp)
+}
+
+func (w *World) AddKnowledge(topicID defs.TopicID) {
+	characterstate.AddKnowledge(topicID, w.Dataman, w.EventBus)
+}
+
 func (w *World) BroadcastEvent(e defs.Event) {
 	w.EventBus.Publish(e)
 }
@@ -208,3 +223,10 @@ func (w *World) GetDialogNPC() id.CharacterStateID {
 	}
 	return w.ActiveMap.GetDialogNPC()
 }
+
+func (w *World) PlayEmote(charID id.CharacterStateID, emote defs.DialogEmote) {
+	if w.ActiveMap == nil {
+		return
+	}
+	w.ActiveMap.PlayEmote(charID, emote)
+}
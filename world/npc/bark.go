@@ -0,0 +1,324 @@
+package npc
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/datamanager"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	"github.com/webbben/2d-game-engine/logz"
+	"github.com/webbben/2d-game-engine/pubsub"
+	"github.com/webbben/2d-game-engine/utils"
+)
+
+// barkState is set while an NPC is taking part in a bark (see BarkDirector).
+// While it's set, the NPC's (low priority) task is paused, so the NPC stays put and faces whoever is talking.
+type barkState struct {
+	task        Task // the task the NPC had when the bark started. if this changes, the NPC got something more important to do.
+	faceTowards *NPC
+}
+
+// CanBark tells if the NPC is free to take part in a bark. Barks never interrupt anything important; only NPCs that are
+// standing around with no task, or a scheduled task, can be chosen.
+func (n *NPC) CanBark() bool {
+	if n.bark != nil {
+		return false
+	}
+	if n.Entity.IsDead() || n.Entity.IsSleeping || n.Entity.IsMoving() {
+		return false
+	}
+	if n.CurrentTask == nil {
+		return true
+	}
+	if n.CurrentTask.IsDone() {
+		return false // about to switch tasks
+	}
+	return n.CurrentTask.GetPriority() == Schedule && !n.CurrentTask.DisableDefaultSpeechBubbles()
+}
+
+// IsBarking tells if the NPC is currently taking part in a bark.
+func (n *NPC) IsBarking() bool {
+	return n.bark != nil
+}
+
+func (n *NPC) startBark() {
+	n.bark = &barkState{task: n.CurrentTask}
+}
+
+func (n *NPC) endBark() {
+	n.bark = nil
+}
+
+// updateBark handles the NPC's side of a bark. Returns true if the NPC's task updates should be held off for now.
+func (n *NPC) updateBark() bool {
+	if n.bark == nil {
+		return false
+	}
+	if n.CurrentTask != n.bark.task {
+		// the NPC was given a different task (e.g. a quest or combat assignment); that always wins over a bark.
+		// the bark director will notice the NPC dropped out, and end the bark for the others too.
+		logz.Println(n.ID(), "leaving bark; task changed")
+		n.endBark()
+		return false
+	}
+	if n.bark.faceTowards != nil && !n.Entity.IsSitting {
+		n.Entity.FaceTowardsEntity(*n.bark.faceTowards.Entity)
+	}
+	return true
+}
+
+// BarkDirector decides when NPCs in the active map have an ambient exchange (a "bark"), and plays it out line by line.
+// Only one bark plays at a time.
+type BarkDirector struct {
+	dataman *datamanager.DataManager
+
+	lastPlayed  map[defs.BarkID]time.Time
+	lastEvents  map[defs.EventType]time.Time // when each event type was last published, for barks that react to recent events
+	nextAttempt time.Time
+
+	current *barkExchange
+}
+
+type barkExchange struct {
+	def        defs.BarkDef
+	npcs       []*NPC
+	nextLine   int
+	nextLineAt time.Time
+}
+
+func NewBarkDirector(dataman *datamanager.DataManager, eventBus *pubsub.EventBus) *BarkDirector {
+	if dataman == nil {
+		panic("dataman was nil")
+	}
+	if eventBus == nil {
+		panic("event bus was nil")
+	}
+	bd := &BarkDirector{
+		dataman:    dataman,
+		lastPlayed: make(map[defs.BarkID]time.Time),
+		lastEvents: make(map[defs.EventType]time.Time),
+	}
+	eventBus.SubscribeAll("BARK_DIRECTOR", bd.onEvent)
+	return bd
+}
+
+func (bd *BarkDirector) onEvent(e defs.Event) {
+	bd.lastEvents[e.Type] = time.Now()
+}
+
+// Update advances the current bark, or tries to start a new one once in a while. npcs should be all the NPCs in the active map.
+func (bd *BarkDirector) Update(npcs []*NPC, mapDef defs.MapDef, ctx defs.SpeechBubbleContext) {
+	if bd.current != nil {
+		bd.updateExchange()
+		return
+	}
+	if config.BarkCheckInterval <= 0 || len(bd.dataman.BarkDefs) == 0 {
+		return
+	}
+	if time.Now().Before(bd.nextAttempt) {
+		return
+	}
+	// add some jitter, so barks don't feel like clockwork
+	jitter := time.Duration(rand.Int63n(int64(config.BarkCheckInterval/2) + 1))
+	bd.nextAttempt = time.Now().Add(config.BarkCheckInterval + jitter)
+
+	bd.tryStartBark(npcs, mapDef, ctx)
+}
+
+// Stop ends the current bark, if any. Call this when leaving a map.
+func (bd *BarkDirector) Stop() {
+	if bd.current == nil {
+		return
+	}
+	for _, n := range bd.current.npcs {
+		n.endBark()
+	}
+	bd.current = nil
+}
+
+func (bd *BarkDirector) updateExchange() {
+	ex := bd.current
+	for _, n := range ex.npcs {
+		if !n.IsBarking() || n.Entity.IsDead() {
+			logz.Println("BarkDirector", "participant dropped out of bark; ending it:", ex.def.ID)
+			bd.Stop()
+			return
+		}
+	}
+	if time.Now().Before(ex.nextLineAt) {
+		return
+	}
+	if ex.nextLine >= len(ex.def.Lines) {
+		bd.Stop()
+		return
+	}
+
+	line := ex.def.Lines[ex.nextLine]
+	speaker := ex.npcs[line.Speaker]
+
+	// everyone else looks at the speaker, and the speaker looks at whoever spoke last (or just the first other participant)
+	var lastSpeaker *NPC
+	if ex.nextLine > 0 {
+		lastSpeaker = ex.npcs[ex.def.Lines[ex.nextLine-1].Speaker]
+	}
+	for _, n := range ex.npcs {
+		if n != speaker {
+			n.bark.faceTowards = speaker
+			if lastSpeaker == nil || lastSpeaker == speaker {
+				lastSpeaker = n
+			}
+		}
+	}
+	speaker.bark.faceTowards = lastSpeaker
+
+	params := speaker.defaultSpeechBubbleParams()
+	params.Duration = line.GetDuration()
+	speaker.Entity.ShowSpeechBubble(line.Text, params)
+
+	ex.nextLine++
+	// a short pause between lines, so they don't run into each other
+	ex.nextLineAt = time.Now().Add(line.GetDuration() + time.Millisecond*500)
+}
+
+func (bd *BarkDirector) tryStartBark(npcs []*NPC, mapDef defs.MapDef, ctx defs.SpeechBubbleContext) {
+	available := []*NPC{}
+	for _, n := range npcs {
+		if n.CanBark() {
+			available = append(available, n)
+		}
+	}
+	if len(available) < 2 {
+		return
+	}
+
+	// go through the barks in random order, and play the first one we can find participants for
+	barkIDs := []defs.BarkID{}
+	for barkID := range bd.dataman.BarkDefs {
+		barkIDs = append(barkIDs, barkID)
+	}
+	slices.Sort(barkIDs)
+	rand.Shuffle(len(barkIDs), func(i, j int) { barkIDs[i], barkIDs[j] = barkIDs[j], barkIDs[i] })
+
+	for _, barkID := range barkIDs {
+		def := bd.dataman.GetBarkDef(barkID)
+		if !bd.barkAllowed(def, mapDef, ctx) {
+			continue
+		}
+		participants, found := castBarkParticipants(def, available)
+		if !found {
+			continue
+		}
+		charIDs := []id.CharacterStateID{}
+		for _, n := range participants {
+			charIDs = append(charIDs, n.CharacterStateRef.ID)
+		}
+		if !barkConditionsMet(def, ctx, charIDs) {
+			continue
+		}
+		bd.startExchange(def, participants)
+		return
+	}
+}
+
+// barkAllowed checks everything about a bark that doesn't depend on who is taking part in it.
+func (bd *BarkDirector) barkAllowed(def defs.BarkDef, mapDef defs.MapDef, ctx defs.SpeechBubbleContext) bool {
+	if last, exists := bd.lastPlayed[def.ID]; exists && time.Since(last) < def.GetCooldown() {
+		return false
+	}
+	if len(def.MapTypes) > 0 && !slices.Contains(def.MapTypes, mapDef.Type) {
+		return false
+	}
+	if len(def.TimesOfDay) > 0 && !slices.Contains(def.TimesOfDay, ctx.GetCurrentGameTime().TimeOfDay()) {
+		return false
+	}
+	if len(def.RecentEvents) > 0 {
+		recent := false
+		for _, eventType := range def.RecentEvents {
+			if last, exists := bd.lastEvents[eventType]; exists && time.Since(last) < def.GetRecentWithin() {
+				recent = true
+				break
+			}
+		}
+		if !recent {
+			return false
+		}
+	}
+	return true
+}
+
+func barkConditionsMet(def defs.BarkDef, ctx defs.SpeechBubbleContext, participants []id.CharacterStateID) bool {
+	for _, cond := range def.Conditions {
+		if !cond.IsMet(ctx, participants) {
+			return false
+		}
+	}
+	return true
+}
+
+// castBarkParticipants picks an NPC for each part of a bark. The first participant is picked from anyone available, and the others
+// must be close by to them.
+func castBarkParticipants(def defs.BarkDef, available []*NPC) ([]*NPC, bool) {
+	order := rand.Perm(len(available))
+	for _, i := range order {
+		first := available[i]
+		if !first.fitsBarkParticipant(def.Participants[0]) {
+			continue
+		}
+		chosen := []*NPC{first}
+		for _, part := range def.Participants[1:] {
+			var pick *NPC
+			for _, j := range order {
+				n := available[j]
+				if slices.Contains(chosen, n) || !n.fitsBarkParticipant(part) {
+					continue
+				}
+				if utils.EuclideanDistCoords(first.Entity.TilePos(), n.Entity.TilePos()) > config.BarkMaxDistance {
+					continue
+				}
+				pick = n
+				break
+			}
+			if pick == nil {
+				break
+			}
+			chosen = append(chosen, pick)
+		}
+		if len(chosen) == len(def.Participants) {
+			return chosen, true
+		}
+	}
+	return nil, false
+}
+
+func (n *NPC) fitsBarkParticipant(part defs.BarkParticipant) bool {
+	if len(part.CharacterIDs) > 0 && !slices.Contains(part.CharacterIDs, n.CharacterStateRef.ID) {
+		return false
+	}
+	if len(part.Roles) > 0 {
+		for _, roleID := range part.Roles {
+			if n.CharacterStateRef.Roles[roleID] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (bd *BarkDirector) startExchange(def defs.BarkDef, participants []*NPC) {
+	names := []string{}
+	for _, n := range participants {
+		n.startBark()
+		names = append(names, n.ID())
+	}
+	logz.Println("BarkDirector", fmt.Sprintf("starting bark %s with: %v", def.ID, names))
+	bd.lastPlayed[def.ID] = time.Now()
+	bd.current = &barkExchange{
+		def:  def,
+		npcs: participants,
+	}
+}
@@ -0,0 +1,84 @@
+package npc
+
+import (
+	"image/color"
+	"slices"
+
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/entity"
+	"github.com/webbben/2d-game-engine/entity/body"
+	"github.com/webbben/2d-game-engine/logz"
+	"github.com/webbben/2d-game-engine/model"
+)
+
+// PlayEmote acts out a dialog emote (see defs.DialogEmote). player is who the emote's facing is relative to.
+// Since NPC updates are paused during dialog, UpdateEmote needs to be called to keep the emote moving.
+func (n *NPC) PlayEmote(emote defs.DialogEmote, player *entity.Entity) {
+	e := n.Entity
+	n.emoting = true
+
+	switch emote.Face {
+	case "":
+	case defs.FacePlayer:
+		e.FaceTowardsEntity(*player)
+	case defs.FaceAway:
+		e.FaceTowardsEntity(*player)
+		e.SetDirection(model.GetOppositeDirection(e.Movement.Direction))
+	default:
+		e.SetDirection(byte(emote.Face[0]))
+	}
+
+	if emote.Gesture != "" {
+		if entity.GestureExists(emote.Gesture) {
+			e.PlayGesture(emote.Gesture)
+		} else {
+			logz.Warnln(n.DisplayName(), "dialog emote has unknown gesture:", emote.Gesture)
+		}
+	}
+
+	if emote.Animation != "" {
+		if !slices.Contains(body.AllAnimations(), emote.Animation) {
+			logz.Warnln(n.DisplayName(), "dialog emote has unknown animation:", emote.Animation)
+		} else {
+			res := e.Body.SetAnimation(emote.Animation, body.SetAnimationOps{
+				Force:         true,
+				DoOnce:        !emote.HoldAnimation,
+				HoldLastFrame: emote.HoldAnimation,
+			})
+			if !res.Success && !res.AlreadySet {
+				logz.Warnln(n.DisplayName(), "failed to play dialog emote animation:", emote.Animation, res)
+			}
+			n.emoteHolding = emote.HoldAnimation
+		}
+	}
+
+	if emote.Icon != "" {
+		e.FloatMGMT.AddFloatText(entity.NewFloatText(emote.Icon, entity.FloatTextParams{
+			Font:     config.DefaultTitleFont,
+			Color:    color.White,
+			Duration: config.DialogEmoteIconDuration,
+		}))
+	}
+}
+
+// UpdateEmote keeps an emote's animation, gesture and icon moving while the NPC's regular updates are paused (like during dialog).
+func (n *NPC) UpdateEmote() {
+	if !n.emoting {
+		return
+	}
+	n.Entity.UpdateAppearance()
+}
+
+// EndEmote stops any emote the NPC is acting out, e.g. when the conversation is over.
+func (n *NPC) EndEmote() {
+	if !n.emoting {
+		return
+	}
+	n.emoting = false
+	n.Entity.StopGesture()
+	if n.emoteHolding {
+		n.Entity.Body.StopAnimation()
+		n.emoteHolding = false
+	}
+}
@@ -92,6 +92,11 @@ type NPC struct {
 	speechBubbleFont        font.Face
 
 	activeMapSubscriptionIDs map[string]bool // subscription IDs for events only listened to when NPC is in active map
+
+	bark *barkState // set while the NPC is taking part in a bark
+
+	emoting      bool // set while the NPC is acting out dialog emotes
+	emoteHolding bool // set if a dialog emote's animation is being held
 }
 
 // GetCurrentTaskForBgAssist returns the NPC's current task if set, for use by the
@@ -107,6 +112,7 @@ func (n *NPC) GetCurrentTaskForBgAssist() Task {
 // active map event subscriptions, etc.
 func (n *NPC) PrepareLeaveActiveMap() {
 	n.Entity.ResetActiveMapRuntimeState()
+	n.endBark()
 
 	for subID := range n.activeMapSubscriptionIDs {
 		n.eventBus.Unsubscribe(subID)
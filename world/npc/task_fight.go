@@ -83,6 +83,7 @@ func NewFightTask(targetEnt *entity.Entity, owner *NPC, p defs.TaskPriority, nex
 }
 
 func init() {
+	// FightTaskParams isn't registered as a payload type, since it points to a live entity; fight tasks can't be saved in scheduled events.
 	registerTask(TaskFight, taskMeta{
 		build: func(def defs.TaskDef, owner *NPC) Task {
 			fightParams, ok := def.Params.(FightTaskParams)
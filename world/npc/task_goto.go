@@ -33,6 +33,7 @@ func NewGotoTask(params GotoTaskParams, owner *NPC, def defs.TaskDef) *GotoTask
 }
 
 func init() {
+	defs.RegisterPayloadType("npc.GotoTaskParams", GotoTaskParams{})
 	registerTask(TaskGoto, taskMeta{
 		build: func(def defs.TaskDef, owner *NPC) Task {
 			params, ok := def.Params.(GotoTaskParams)
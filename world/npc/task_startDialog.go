@@ -43,6 +43,7 @@ func NewStartDialogTask(params StartDialogTaskParams, owner *NPC, def defs.TaskD
 }
 
 func init() {
+	defs.RegisterPayloadType("npc.StartDialogTaskParams", StartDialogTaskParams{})
 	registerTask(TaskStartDialog, taskMeta{
 		build: func(def defs.TaskDef, owner *NPC) Task {
 			params, ok := def.Params.(StartDialogTaskParams)
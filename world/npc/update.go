@@ -239,6 +239,11 @@ func (n *NPC) npcUpdates() {
 
 	n.updatePlayerSighting()
 
+	if n.updateBark() {
+		// task (and default speech bubbles) are paused while the NPC is busy chatting
+		return
+	}
+
 	// if there is no task, or if the task allows it, do default speech bubble behavior
 	if n.CurrentTask == nil || !n.CurrentTask.DisableDefaultSpeechBubbles() {
 		if n.initialPlayerSightingThisTick {
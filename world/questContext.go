@@ -0,0 +1,46 @@
+package world
+
+import (
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/data/id"
+	characterstate "github.com/webbben/2d-game-engine/entity/characterState"
+	"github.com/webbben/2d-game-engine/item"
+	"github.com/webbben/2d-game-engine/logz"
+)
+
+// This file holds the World's implementations for QuestConditionContext.
+// These are read-only checks on the game world that quest conditions use.
+
+func (w *World) GetPlayerItemCount(itemID defs.ItemID) int {
+	if w.Player == nil {
+		logz.Panic("Player was nil!")
+	}
+	count := 0
+	for _, itemState := range w.Player.CharacterStateRef.InventoryItems {
+		if itemState != nil && itemState.DefID == itemID {
+			count += itemState.Quantity
+		}
+	}
+	return count
+}
+
+func (w *World) GetPlayerGold() int {
+	if w.Player == nil {
+		logz.Panic("Player was nil!")
+	}
+	return item.CountMoney(w.Player.CharacterStateRef.StandardInventory, w.Dataman)
+}
+
+func (w *World) PlayerHasRole(roleID defs.RoleID) bool {
+	if w.Player == nil {
+		logz.Panic("Player was nil!")
+	}
+	return w.Player.CharacterStateRef.Roles[roleID]
+}
+
+func (w *World) GetNPCOpinionOfPlayer(npcID id.CharacterStateID) int {
+	npcState := w.Dataman.GetCharacterState(npcID)
+	playerState := w.Dataman.GetCharacterState(id.CharacterStateID(defs.PlayerID))
+	_, opinion := characterstate.CalculateOpinion(npcState, playerState, w.GetCurrentGameTime(), w.Dataman)
+	return opinion
+}
@@ -0,0 +1,79 @@
+package world
+
+import (
+	"github.com/webbben/2d-game-engine/config"
+	"github.com/webbben/2d-game-engine/data/defs"
+)
+
+// LocateQuestTarget resolves a quest target to a marker in the current map.
+// If the target is in the current map, the marker is at the target's exact position.
+// If it's in another map, the marker is at the next door to take to get there (found through the world graph).
+// Returns false if the target can't be located right now (e.g. the NPC doesn't exist, or there's no path to the target's map).
+// This is polled by the HUD, so it doesn't log anything when a target can't be found.
+func (w *World) LocateQuestTarget(target defs.QuestTargetDef) (defs.QuestMarker, bool) {
+	if w.ActiveMap == nil {
+		return defs.QuestMarker{}, false
+	}
+	currentMap := w.ActiveMap.MapID
+	marker := defs.QuestMarker{
+		MapID:       currentMap,
+		TargetMapID: target.MapID,
+	}
+
+	if target.NPC != "" {
+		found := false
+		for _, n := range w.NPCs {
+			if n.CharacterStateRef.DefID == target.NPC {
+				marker.TargetMapID = n.CharacterStateRef.CurrentMap
+				found = true
+				break
+			}
+		}
+		if !found {
+			return defs.QuestMarker{}, false
+		}
+		if marker.TargetMapID == currentMap {
+			for _, n := range w.ActiveMap.GetAllNPCs() {
+				if n.CharacterStateRef.DefID == target.NPC {
+					marker.X, marker.Y = n.X(), n.Y()
+					return marker, true
+				}
+			}
+			// NPC is supposed to be in this map, but hasn't been placed yet (e.g. walking in from a door)
+			return defs.QuestMarker{}, false
+		}
+	}
+
+	if marker.TargetMapID == currentMap {
+		switch {
+		case target.ObjectID != 0:
+			for _, obj := range w.ActiveMap.GetAllObjects() {
+				if obj.ID == target.ObjectID {
+					marker.X, marker.Y = obj.Pos()
+					return marker, true
+				}
+			}
+			return defs.QuestMarker{}, false
+		case target.TileX != nil:
+			marker.X = float64(*target.TileX * config.TileSize)
+			marker.Y = float64(*target.TileY * config.TileSize)
+			return marker, true
+		default:
+			// the target is just the map itself, and we're already here
+			return defs.QuestMarker{}, false
+		}
+	}
+
+	if w.WorldGraph == nil || marker.TargetMapID == "" {
+		return defs.QuestMarker{}, false
+	}
+	// the door only depends on the current map and the target's map, so this is cached by the world graph
+	door, found := w.WorldGraph.FindNextEdge(currentMap, marker.TargetMapID)
+	if !found {
+		return defs.QuestMarker{}, false
+	}
+	marker.X = float64(door.EdgeCoords.X * config.TileSize)
+	marker.Y = float64(door.EdgeCoords.Y * config.TileSize)
+	marker.IsDoor = true
+	return marker, true
+}
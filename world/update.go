@@ -61,6 +61,10 @@ func (w *World) Update(showingLoadScreen bool) {
 
 	w.Player.Update(blockPlayerChanges)
 
+	if !blockPlayerChanges && !w.ActiveMap.InScenario {
+		w.Barks.Update(w.ActiveMap.NPCs, w.ActiveMap.MapDef, w.GameCtx)
+	}
+
 	if !blockPlayerChanges && !w.ActiveMap.InScenario {
 		// don't update time while player is in dialog or something where his in-map input is paused
 		if w.Clock.Update() {
@@ -90,6 +94,7 @@ func (w *World) startNpcSimulation() {
 	w.initializeNpcWorldState()
 
 	logz.Println("SIMULATION", "Starting background NPC simulation...")
+	w.simDone = make(chan struct{})
 	go w.npcBackgroundSimulation()
 }
 
@@ -195,6 +200,11 @@ func (w *World) npcBackgroundSimulation() {
 	lastHour := w.Clock.GetCurrentGameTime().Hour
 
 	for {
+		if w.simStopped.Load() {
+			logz.Println("SIMULATION", "NPC background simulation stopped.")
+			close(w.simDone)
+			return
+		}
 		// detect when simulation should be paused.
 		if w.SimPaused.Load() {
 			// once we've "noticed" the SimPaused flag, set this "sim pause effected" flag so that outside code knows for sure
@@ -64,11 +64,17 @@ type World struct {
 	SimPaused        atomic.Bool // set this as the flag to get the simulation to pause
 	SimPauseEffected atomic.Bool // if true, then the sim has successfully paused and is no longer processing NPC simulation updates.
 
+	simStopped atomic.Bool   // set when the world is closed, to end the simulation for good
+	simDone    chan struct{} // closed once the simulation has ended
+
 	// time lapse - for things like sleeping or waiting in-game.
 
 	AwaitingTimeLapse bool            // when a time lapse action comes in, set this flag and then wait until sim pause has been effected before doing time lapse.
 	TimeLapseTo       *clock.GameTime // the time we should lapse to
 
+	// plays ambient exchanges between NPCs in the active map
+	Barks *npc.BarkDirector
+
 	// Map Information
 
 	ActiveMap *activemap.ActiveMap
@@ -139,6 +145,8 @@ func NewWorld(
 
 	w.populateNPCMap()
 
+	w.Barks = npc.NewBarkDirector(w.Dataman, w.EventBus)
+
 	w.startNpcSimulation()
 
 	w.EventBus.SubscribeToWorldEvents("WORLD", w.OnEvent)
@@ -319,6 +327,7 @@ func (w *World) loadRegularMapNPCs() {
 //   - Player, NPC or other entity activity states, like sitting, sleeping, etc. (could affect NPC tasks, for example)
 func (w *World) CloseMap() {
 	logz.Println("CloseMap", w.ActiveMap.MapID)
+	w.Barks.Stop()
 	for _, n := range w.ActiveMap.NPCs {
 		n.PrepareLeaveActiveMap()
 	}
@@ -329,6 +338,21 @@ func (w *World) CloseMap() {
 	w.ActiveMap = nil
 }
 
+// Close shuts down the world, so that it can be replaced by a new one (e.g. when loading a save while already in the game world).
+// The background NPC simulation is stopped and the active map is closed. Event subscriptions are left alone; whoever throws away
+// the world should reset the event bus too.
+func (w *World) Close() {
+	logz.Println("WORLD", "closing world")
+	w.simStopped.Store(true)
+	w.SimPaused.Store(true)
+	if w.simDone != nil {
+		<-w.simDone
+	}
+	if w.ActiveMap != nil {
+		w.CloseMap()
+	}
+}
+
 // OnHourChange handles any hourly changes that should occur; such as lighting, event publishing, etc.
 // postEvent: this exists to suppress the event when we initialize the first time. the main reason being that the quest manager
 // won't have its data set yet, and will panic if it receives events beforehand.
@@ -424,31 +448,16 @@ func (w *World) OnEvent(e defs.Event) {
 			logz.Panicln("WORLD", "SysEventChangeMapOccupancy: didn't find params in data.")
 		}
 	case pubsub.SysScheduledWorldEffect:
-		effectType, ok := e.Data["type"]
-		if !ok {
-			logz.Println("SysScheduledWorldEffect", e.Data)
-			logz.Panicln("SysScheduledWorldEffect", "event data was missing effect type.")
-		}
 		effectData, ok := e.Data["effect"]
 		if !ok {
 			logz.Println("SysScheduledWorldEffect", e.Data)
 			logz.Panicln("SysScheduledWorldEffect", "event data was missing effect.")
 		}
-
-		var worldEffect defs.WorldEffect
-
-		switch effectType {
-		case "RemoveRoleEffect":
-			removeRoleEffect, ok := effectData.(RemoveRoleEffect)
-			if !ok {
-				logz.Println("SysScheduledWorldEffect", effectType, effectData)
-				logz.Panicln("SysScheduledWorldEffect", "effect is supposed to be removeRole, but failed to type assert.")
-			}
-			worldEffect = removeRoleEffect
-		}
-
-		if worldEffect == nil {
-			logz.Panicln("SysScheduledWorldEffect", "worldEffect was nil")
+		// effects are saved with their type (see defs.RegisterPayloadType), so this works for events loaded from a save too
+		worldEffect, ok := effectData.(defs.WorldEffect)
+		if !ok {
+			logz.Println("SysScheduledWorldEffect", e.Data["type"], effectData)
+			logz.Panicln("SysScheduledWorldEffect", "effect data isn't a world effect. is its type registered as a payload type?")
 		}
 
 		worldEffect.Apply(w)
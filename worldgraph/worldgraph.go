@@ -48,6 +48,74 @@ type WorldGraph struct {
 	// a cache of all map data. mainly used for access to cost maps, so we can calculate local paths.
 	// we purposely remove data like tile image data, since we don't use image data for path finding.
 	MapDataCache map[defs.MapID]*tiled.Map
+
+	// the first edge to take from one map to get to another (see FindNextEdge). the graph is rebuilt from scratch when maps change,
+	// so this never needs to be invalidated.
+	nextEdgeCache map[[2]defs.MapID]nextEdgeResult
+}
+
+type nextEdgeResult struct {
+	edge  MapEdge
+	found bool
+}
+
+// FindNextEdge finds the first edge (door) to take from one map to get to another. Unlike FindPath, this only searches the
+// edges between maps, without calculating any in-map paths, so it's cheap enough for things like UI markers. Results are cached.
+func (wg *WorldGraph) FindNextEdge(from, to defs.MapID) (MapEdge, bool) {
+	if from == to {
+		return MapEdge{}, false
+	}
+	key := [2]defs.MapID{from, to}
+	if res, exists := wg.nextEdgeCache[key]; exists {
+		return res.edge, res.found
+	}
+
+	res := wg.findNextEdge(from, to)
+	if wg.nextEdgeCache == nil {
+		wg.nextEdgeCache = make(map[[2]defs.MapID]nextEdgeResult)
+	}
+	wg.nextEdgeCache[key] = res
+	return res.edge, res.found
+}
+
+func (wg *WorldGraph) findNextEdge(from, to defs.MapID) nextEdgeResult {
+	if wg.Nodes[from] == nil {
+		return nextEdgeResult{}
+	}
+
+	// for each map we reach, remember which of the starting map's edges we first left through to get there
+	firstEdge := map[defs.MapID]MapEdge{}
+	visited := map[defs.MapID]bool{from: true}
+	queue := []defs.MapID{}
+	for _, edge := range wg.Nodes[from].Edges {
+		if visited[edge.To] {
+			continue
+		}
+		visited[edge.To] = true
+		firstEdge[edge.To] = edge
+		queue = append(queue, edge.To)
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			return nextEdgeResult{edge: firstEdge[current], found: true}
+		}
+		node := wg.Nodes[current]
+		if node == nil {
+			continue
+		}
+		for _, edge := range node.Edges {
+			if visited[edge.To] {
+				continue
+			}
+			visited[edge.To] = true
+			firstEdge[edge.To] = firstEdge[current]
+			queue = append(queue, edge.To)
+		}
+	}
+	return nextEdgeResult{}
 }
 
 func (wg *WorldGraph) FindPath(from, to defs.MapID) (pathToGoal WorldPath, foundPath bool) {
@@ -0,0 +1,56 @@
+package worldgraph
+
+import (
+	"testing"
+
+	"github.com/webbben/2d-game-engine/data/defs"
+	"github.com/webbben/2d-game-engine/model"
+)
+
+func testGraph() *WorldGraph {
+	// town -> tavern -> cellar, and town -> forest
+	return &WorldGraph{Nodes: map[defs.MapID]*MapNode{
+		"town": {ID: "town", Edges: []MapEdge{
+			{To: "tavern", EdgeCoords: model.Coords{X: 1, Y: 1}},
+			{To: "forest", EdgeCoords: model.Coords{X: 9, Y: 9}},
+		}},
+		"tavern": {ID: "tavern", Edges: []MapEdge{
+			{To: "town", EdgeCoords: model.Coords{X: 2, Y: 2}},
+			{To: "cellar", EdgeCoords: model.Coords{X: 3, Y: 3}},
+		}},
+		"cellar": {ID: "cellar", Edges: []MapEdge{
+			{To: "tavern", EdgeCoords: model.Coords{X: 4, Y: 4}},
+		}},
+		"forest": {ID: "forest", Edges: []MapEdge{
+			{To: "town", EdgeCoords: model.Coords{X: 5, Y: 5}},
+		}},
+		"island": {ID: "island"},
+	}}
+}
+
+func TestFindNextEdge(t *testing.T) {
+	wg := testGraph()
+
+	tests := []struct {
+		from, to defs.MapID
+		found    bool
+		nextTo   defs.MapID
+	}{
+		{"town", "tavern", true, "tavern"},
+		{"town", "cellar", true, "tavern"},
+		{"cellar", "forest", true, "tavern"},
+		{"forest", "cellar", true, "town"},
+		{"town", "island", false, ""},
+		{"island", "town", false, ""},
+		{"town", "town", false, ""},
+	}
+	for _, tt := range tests {
+		// twice, so the cached result is checked too
+		for range 2 {
+			edge, found := wg.FindNextEdge(tt.from, tt.to)
+			if found != tt.found || edge.To != tt.nextTo {
+				t.Errorf("%s -> %s: expected (%v, %s), got (%v, %s)", tt.from, tt.to, tt.found, tt.nextTo, found, edge.To)
+			}
+		}
+	}
+}